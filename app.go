@@ -9,8 +9,15 @@ import (
 	"os"
 
 	"github.com/sirupsen/logrus"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// BatchProgressEvent is emitted with a model.BatchProgress after each book of a batch download
+const BatchProgressEvent = "batch-progress"
+
+// SubscriptionUpdateEvent is emitted with a model.SubscriptionUpdate when a scheduled check finds new chapters
+const SubscriptionUpdateEvent = "subscription-update"
+
 // App struct
 type App struct {
 	ctx          context.Context
@@ -21,6 +28,14 @@ type App struct {
 	getHint      *functions.GetHint
 	chatbot      *functions.FyChatbot
 	funnyToy     *functions.FunnyToy
+	dictHandler  *functions.DictHandler
+	library      *functions.LibraryHandler
+	subscriber   *functions.Subscriber
+	fullText     *functions.FullTextHandler
+	batch        *functions.BatchDownloader
+	reader       *functions.ReaderHandler
+	cache        *functions.CacheHandler
+	recorder     *functions.Recorder
 }
 
 // NewApp creates a new App application struct
@@ -46,7 +61,24 @@ func (a *App) startup(ctx context.Context) {
 	a.getHint = functions.NewGetHint(log)
 	a.chatbot = functions.NewFyChatbot(log)
 	a.funnyToy = functions.NewFunnyToy(log)
+	a.dictHandler = functions.NewDictHandler(log)
+	a.library = functions.NewLibraryHandler(log)
+	a.subscriber = functions.NewSubscriber(log)
+	a.fullText = functions.NewFullTextHandler(log)
+	a.batch = functions.NewBatchDownloader(log)
+	a.reader = functions.NewReaderHandler(log)
+	a.cache = functions.NewCacheHandler(log)
+	a.recorder = functions.NewRecorder(log)
 	a.log = log
+	go a.subscriber.Run(ctx, func(u model.SubscriptionUpdate) {
+		runtime.EventsEmit(ctx, SubscriptionUpdateEvent, u)
+	})
+	// Index books downloaded before full-text search existed, so the first search is fast
+	go func() {
+		if _, err := a.fullText.Sync(); err != nil {
+			log.Errorf("app full-text index sync error: %v", err)
+		}
+	}()
 }
 
 func (a *App) SerachNovel(name string) []*model.SearchResult {
@@ -58,6 +90,20 @@ func (a *App) SerachNovel(name string) []*model.SearchResult {
 	return res
 }
 
+// CheckCatalog 下载前检查目录, 列出缺失或重复的章节号
+func (a *App) CheckCatalog(sr *model.SearchResult) *model.CheckCatalogResult {
+	res := &model.CheckCatalogResult{}
+	report, err := a.downloader.CheckCatalog(sr)
+	if err != nil {
+		errMsg := fmt.Sprintf("app CheckCatalog error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Report = *report
+	return res
+}
+
 func (a *App) DownLoadNovel(sr *model.SearchResult) *model.CrawlResult {
 	res, err := a.downloader.DownLoad(sr)
 	if err != nil {
@@ -67,6 +113,20 @@ func (a *App) DownLoadNovel(sr *model.SearchResult) *model.CrawlResult {
 	return res
 }
 
+// RefetchChapters 重新获取下载结果中的可疑章节并重新生成文件, sourceID 为 0 时使用原书源
+func (a *App) RefetchChapters(bookURL string, seqs []int, sourceID int) *model.RefetchChaptersResult {
+	res := &model.RefetchChaptersResult{}
+	result, err := a.downloader.Refetch(bookURL, seqs, sourceID)
+	if err != nil {
+		errMsg := fmt.Sprintf("app RefetchChapters error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Result = *result
+	return res
+}
+
 func (a *App) GetUpdateInfo() *model.GetUpdateInfoResult {
 	return a.checkUpdater.CheckUpdate()
 }
@@ -201,3 +261,341 @@ func (a *App) GenerateAsciiImage(yukkuriParams model.YukkuriParams) *model.Gener
 	res.Response = ""
 	return res
 }
+
+func (a *App) ListDictEntries() *model.ListDictEntriesResult {
+	res := &model.ListDictEntriesResult{}
+	entries, err := a.dictHandler.List()
+	if err != nil {
+		errMsg := fmt.Sprintf("app ListDictEntries error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Entries = entries
+	return res
+}
+
+// SaveDictEntry adds an entry when its ID is empty, otherwise updates it
+func (a *App) SaveDictEntry(entry model.DictEntry) *model.SaveDictEntryResult {
+	res := &model.SaveDictEntryResult{}
+	saved, err := a.dictHandler.Save(entry)
+	if err != nil {
+		errMsg := fmt.Sprintf("app SaveDictEntry error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Entry = saved
+	return res
+}
+
+func (a *App) DeleteDictEntry(id string) string {
+	if err := a.dictHandler.Delete(id); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// DryRunDictEntry lists the journaled chapters an entry would change, without saving it
+func (a *App) DryRunDictEntry(entry model.DictEntry) *model.DryRunDictEntryResult {
+	res, err := a.dictHandler.DryRun(entry)
+	if err != nil {
+		errMsg := fmt.Sprintf("app DryRunDictEntry error: %v", err)
+		a.log.Error(errMsg)
+		return &model.DryRunDictEntryResult{ErrorMsg: errMsg}
+	}
+	return res
+}
+
+// ListLibrary returns every book in the local library, most recent first
+func (a *App) ListLibrary() *model.ListLibraryResult {
+	return a.SearchLibrary("")
+}
+
+// SearchLibrary matches the keyword against book name, author and category
+func (a *App) SearchLibrary(keyword string) *model.ListLibraryResult {
+	res := &model.ListLibraryResult{}
+	entries, err := a.library.Search(keyword)
+	if err != nil {
+		errMsg := fmt.Sprintf("app SearchLibrary error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Entries = entries
+	return res
+}
+
+func (a *App) OpenLibraryFolder(id string) string {
+	if err := a.library.OpenFolder(id); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// DeleteLibraryEntry removes a library entry with its subscription, and its exported files, journal and
+// full-text index when removeFiles is set
+func (a *App) DeleteLibraryEntry(id string, removeFiles bool) string {
+	if err := a.library.Delete(id, removeFiles); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// Subscribe follows a book for new chapters, autoDownload fetches them as soon as they are found
+func (a *App) Subscribe(sr *model.SearchResult, autoDownload bool) *model.SubscribeResult {
+	res := &model.SubscribeResult{}
+	sub, err := a.subscriber.Subscribe(sr, autoDownload)
+	if err != nil {
+		errMsg := fmt.Sprintf("app Subscribe error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Subscription = sub
+	return res
+}
+
+func (a *App) ListSubscriptions() *model.ListSubscriptionsResult {
+	res := &model.ListSubscriptionsResult{}
+	subs, err := a.subscriber.List()
+	if err != nil {
+		errMsg := fmt.Sprintf("app ListSubscriptions error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Subscriptions = subs
+	return res
+}
+
+func (a *App) Unsubscribe(id string) string {
+	if err := a.subscriber.Unsubscribe(id); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (a *App) SetSubscriptionAutoDownload(id string, autoDownload bool) string {
+	if err := a.subscriber.SetAutoDownload(id, autoDownload); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// CheckSubscriptions checks every subscription now, regardless of the configured interval
+func (a *App) CheckSubscriptions() *model.CheckSubscriptionsResult {
+	res := &model.CheckSubscriptionsResult{}
+	updates, err := a.subscriber.Check(0)
+	if err != nil {
+		errMsg := fmt.Sprintf("app CheckSubscriptions error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+	}
+	res.Updates = updates
+	return res
+}
+
+// SearchFullText finds chapters of downloaded books containing every space-separated keyword,
+// limit 0 uses the default of 50 hits
+func (a *App) SearchFullText(query string, limit int) *model.FullTextSearchResult {
+	res, err := a.fullText.Search(query, limit)
+	if err != nil {
+		errMsg := fmt.Sprintf("app SearchFullText error: %v", err)
+		a.log.Error(errMsg)
+		return &model.FullTextSearchResult{ErrorMsg: errMsg}
+	}
+	return res
+}
+
+// SelectBatchFile opens a file dialog for a reading list, returns an empty string when cancelled
+func (a *App) SelectBatchFile() string {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Reading list",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Reading list (*.txt;*.csv;*.json)", Pattern: "*.txt;*.csv;*.json"},
+		},
+	})
+	if err != nil {
+		a.log.Errorf("app SelectBatchFile error: %v", err)
+		return ""
+	}
+	return path
+}
+
+// BatchDownload downloads every book of a reading list and writes a report next to it,
+// progress is reported through BatchProgressEvent
+func (a *App) BatchDownload(listPath string, parallel int) *model.BatchDownloadResult {
+	res := &model.BatchDownloadResult{}
+	report, err := a.batch.Run(listPath, parallel, "", func(p model.BatchProgress) {
+		runtime.EventsEmit(a.ctx, BatchProgressEvent, p)
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("app BatchDownload error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Report = *report
+	return res
+}
+
+// ListReaderBooks returns the books that can be read in the app, including books
+// still being downloaded or never exported, most recently read first
+func (a *App) ListReaderBooks() *model.ListReaderBooksResult {
+	res := &model.ListReaderBooksResult{}
+	books, err := a.reader.Books()
+	if err != nil {
+		errMsg := fmt.Sprintf("app ListReaderBooks error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Books = books
+	return res
+}
+
+// ListReaderChapters returns the catalog of a book, chapters without fetched content are marked unavailable
+func (a *App) ListReaderChapters(id string) *model.ListReaderChaptersResult {
+	res := &model.ListReaderChaptersResult{}
+	chapters, err := a.reader.Chapters(id)
+	if err != nil {
+		errMsg := fmt.Sprintf("app ListReaderChapters error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Chapters = chapters
+	return res
+}
+
+// ReadChapter returns the sanitized HTML of a chapter by its catalog index
+func (a *App) ReadChapter(id string, seq int) *model.ReadChapterResult {
+	res := &model.ReadChapterResult{}
+	chapter, err := a.reader.Chapter(id, seq)
+	if err != nil {
+		errMsg := fmt.Sprintf("app ReadChapter error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Chapter = *chapter
+	return res
+}
+
+// GetReadingState returns the saved position and bookmarks of a book
+func (a *App) GetReadingState(id string) *model.ReadingStateResult {
+	res := &model.ReadingStateResult{}
+	state, err := a.reader.State(id)
+	if err != nil {
+		errMsg := fmt.Sprintf("app GetReadingState error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.State = state
+	return res
+}
+
+// SaveReadingPosition saves the chapter and the scroll offset (0 to 1) within it
+func (a *App) SaveReadingPosition(id string, seq int, offset float64) string {
+	if err := a.reader.SavePosition(id, seq, offset); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (a *App) AddBookmark(id string, bookmark model.Bookmark) *model.AddBookmarkResult {
+	res := &model.AddBookmarkResult{}
+	saved, err := a.reader.AddBookmark(id, bookmark)
+	if err != nil {
+		errMsg := fmt.Sprintf("app AddBookmark error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Bookmark = saved
+	return res
+}
+
+func (a *App) DeleteBookmark(id, bookmarkID string) string {
+	if err := a.reader.DeleteBookmark(id, bookmarkID); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// OpenOnlineBook fetches the book page and full catalog for reading without downloading,
+// chapters already cached are marked available
+func (a *App) OpenOnlineBook(sr *model.SearchResult) *model.OpenOnlineBookResult {
+	res := &model.OpenOnlineBookResult{}
+	book, err := a.reader.OpenOnline(sr)
+	if err != nil {
+		errMsg := fmt.Sprintf("app OpenOnlineBook error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Book = *book
+	return res
+}
+
+// ReadOnlineChapter fetches and cleans one chapter by its catalog index, the next
+// reader.prefetch chapters are fetched in the background
+func (a *App) ReadOnlineChapter(sr *model.SearchResult, seq int) *model.ReadChapterResult {
+	res := &model.ReadChapterResult{}
+	chapter, err := a.reader.ReadOnline(sr, seq)
+	if err != nil {
+		errMsg := fmt.Sprintf("app ReadOnlineChapter error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Chapter = *chapter
+	return res
+}
+
+func (a *App) ClearOnlineCache() string {
+	if err := a.reader.ClearOnlineCache(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// GetCacheStats reports the crawl page cache and the read-online chapter cache
+func (a *App) GetCacheStats() *model.CacheStatsResult {
+	return &model.CacheStatsResult{
+		HTTP:     a.cache.Stats(),
+		Chapters: a.reader.OnlineCacheStats(),
+	}
+}
+
+func (a *App) ClearHTTPCache() string {
+	if err := a.cache.Clear(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// StartRecording records every crawl request until StopRecording, for attaching to source bug reports
+func (a *App) StartRecording() string {
+	if err := a.recorder.Start(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// StopRecording saves the recorded requests as a HAR file in the recordings directory
+func (a *App) StopRecording() *model.StopRecordingResult {
+	res := &model.StopRecordingResult{}
+	path, entries, err := a.recorder.Stop()
+	if err != nil {
+		errMsg := fmt.Sprintf("app StopRecording error: %v", err)
+		a.log.Error(errMsg)
+		res.ErrorMsg = errMsg
+		return res
+	}
+	res.Path = path
+	res.Entries = entries
+	return res
+}
@@ -0,0 +1,14 @@
+// Command fy-novel is the headless command-line interface. It shares the download, config and
+// update functions with the desktop app (built from the repository root with wails) but needs
+// no webview, so it runs on servers without a display.
+package main
+
+import (
+	"os"
+
+	"fy-novel/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
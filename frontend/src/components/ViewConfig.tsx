@@ -138,7 +138,30 @@ const ViewConfig: React.FC = () => {
                         <Select
                             options={[
                                 { value: "txt", label: "txt" },
-                                { value: "epub", label: "epub" }
+                                { value: "epub", label: "epub" },
+                                { value: "html", label: "html" },
+                                { value: "md", label: "md" },
+                                { value: "fb2", label: "fb2" },
+                                { value: "pdf", label: "pdf" }
+                            ]}
+                        />
+                    </Form.Item>
+                    <Form.Item
+                        name={["base", "layout"]}
+                        label={
+                            <span>
+                                {t('viewConfig.exportLayout')}
+                                <Tooltip title={t('viewConfig.exportLayoutTooltip')}>
+                                    <QuestionCircleOutlined style={{ marginLeft: 4 }} />
+                                </Tooltip>
+                            </span>
+                        }
+                        style={formItemStyle}
+                    >
+                        <Select
+                            options={[
+                                { value: "single", label: t('viewConfig.layoutSingle') },
+                                { value: "multi", label: t('viewConfig.layoutMulti') }
                             ]}
                         />
                     </Form.Item>
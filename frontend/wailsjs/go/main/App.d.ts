@@ -2,12 +2,34 @@
 // This file is automatically generated. DO NOT EDIT
 import {model} from '../models';
 
+export function AddBookmark(arg1:string,arg2:model.Bookmark):Promise<model.AddBookmarkResult>;
+
+export function BatchDownload(arg1:string,arg2:number):Promise<model.BatchDownloadResult>;
+
+export function CheckCatalog(arg1:model.SearchResult):Promise<model.CheckCatalogResult>;
+
+export function CheckSubscriptions():Promise<model.CheckSubscriptionsResult>;
+
+export function ClearHTTPCache():Promise<string>;
+
+export function ClearOnlineCache():Promise<string>;
+
 export function DeepSeekChat(arg1:string,arg2:string):Promise<model.StartChatbotResult>;
 
+export function DeleteBookmark(arg1:string,arg2:string):Promise<string>;
+
+export function DeleteDictEntry(arg1:string):Promise<string>;
+
+export function DeleteLibraryEntry(arg1:string,arg2:boolean):Promise<string>;
+
 export function DownLoadNovel(arg1:model.SearchResult):Promise<model.CrawlResult>;
 
+export function DryRunDictEntry(arg1:model.DictEntry):Promise<model.DryRunDictEntryResult>;
+
 export function GenerateAsciiImage(arg1:model.YukkuriParams):Promise<model.GenerateAsciiImageResult>;
 
+export function GetCacheStats():Promise<model.CacheStatsResult>;
+
 export function GetConfig():Promise<model.GetConfigResult>;
 
 export function GetCurrentUseModel():Promise<model.GetCurrentUseModelResult>;
@@ -16,6 +38,8 @@ export function GetDownloadProgress(arg1:model.SearchResult):Promise<model.Progr
 
 export function GetInitOllamaProgress():Promise<model.InitOllamaProgressResult>;
 
+export function GetReadingState(arg1:string):Promise<model.ReadingStateResult>;
+
 export function GetSelectModelList():Promise<model.GetSelectModelListResult>;
 
 export function GetSetOllamaModelProgress():Promise<model.GetSetOllamaModelProgressResult>;
@@ -30,10 +54,50 @@ export function InitOllama():Promise<model.InitOllamaResult>;
 
 export function InitSetOllamaModelTask():Promise<model.InitSetOllamaModelResult>;
 
+export function ListDictEntries():Promise<model.ListDictEntriesResult>;
+
+export function ListLibrary():Promise<model.ListLibraryResult>;
+
+export function ListReaderBooks():Promise<model.ListReaderBooksResult>;
+
+export function ListReaderChapters(arg1:string):Promise<model.ListReaderChaptersResult>;
+
+export function ListSubscriptions():Promise<model.ListSubscriptionsResult>;
+
+export function OpenLibraryFolder(arg1:string):Promise<string>;
+
+export function OpenOnlineBook(arg1:model.SearchResult):Promise<model.OpenOnlineBookResult>;
+
+export function ReadChapter(arg1:string,arg2:number):Promise<model.ReadChapterResult>;
+
+export function ReadOnlineChapter(arg1:model.SearchResult,arg2:number):Promise<model.ReadChapterResult>;
+
+export function RefetchChapters(arg1:string,arg2:Array<number>,arg3:number):Promise<model.RefetchChaptersResult>;
+
+export function SaveDictEntry(arg1:model.DictEntry):Promise<model.SaveDictEntryResult>;
+
+export function SaveReadingPosition(arg1:string,arg2:number,arg3:number):Promise<string>;
+
+export function SearchFullText(arg1:string,arg2:number):Promise<model.FullTextSearchResult>;
+
+export function SearchLibrary(arg1:string):Promise<model.ListLibraryResult>;
+
+export function SelectBatchFile():Promise<string>;
+
 export function SerachNovel(arg1:string):Promise<Array<model.SearchResult>>;
 
 export function SetConfig(arg1:string):Promise<string>;
 
 export function SetOllamaModel(arg1:string):Promise<model.SetOllamaModelResult>;
 
+export function SetSubscriptionAutoDownload(arg1:string,arg2:boolean):Promise<string>;
+
 export function StartChatbot(arg1:string):Promise<model.StartChatbotResult>;
+
+export function StartRecording():Promise<string>;
+
+export function StopRecording():Promise<model.StopRecordingResult>;
+
+export function Subscribe(arg1:model.SearchResult,arg2:boolean):Promise<model.SubscribeResult>;
+
+export function Unsubscribe(arg1:string):Promise<string>;
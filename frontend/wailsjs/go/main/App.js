@@ -2,18 +2,62 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddBookmark(arg1, arg2) {
+  return window['go']['main']['App']['AddBookmark'](arg1, arg2);
+}
+
+export function BatchDownload(arg1, arg2) {
+  return window['go']['main']['App']['BatchDownload'](arg1, arg2);
+}
+
+export function CheckCatalog(arg1) {
+  return window['go']['main']['App']['CheckCatalog'](arg1);
+}
+
+export function CheckSubscriptions() {
+  return window['go']['main']['App']['CheckSubscriptions']();
+}
+
+export function ClearHTTPCache() {
+  return window['go']['main']['App']['ClearHTTPCache']();
+}
+
+export function ClearOnlineCache() {
+  return window['go']['main']['App']['ClearOnlineCache']();
+}
+
 export function DeepSeekChat(arg1, arg2) {
   return window['go']['main']['App']['DeepSeekChat'](arg1, arg2);
 }
 
+export function DeleteBookmark(arg1, arg2) {
+  return window['go']['main']['App']['DeleteBookmark'](arg1, arg2);
+}
+
+export function DeleteDictEntry(arg1) {
+  return window['go']['main']['App']['DeleteDictEntry'](arg1);
+}
+
+export function DeleteLibraryEntry(arg1, arg2) {
+  return window['go']['main']['App']['DeleteLibraryEntry'](arg1, arg2);
+}
+
 export function DownLoadNovel(arg1) {
   return window['go']['main']['App']['DownLoadNovel'](arg1);
 }
 
+export function DryRunDictEntry(arg1) {
+  return window['go']['main']['App']['DryRunDictEntry'](arg1);
+}
+
 export function GenerateAsciiImage(arg1) {
   return window['go']['main']['App']['GenerateAsciiImage'](arg1);
 }
 
+export function GetCacheStats() {
+  return window['go']['main']['App']['GetCacheStats']();
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
@@ -30,6 +74,10 @@ export function GetInitOllamaProgress() {
   return window['go']['main']['App']['GetInitOllamaProgress']();
 }
 
+export function GetReadingState(arg1) {
+  return window['go']['main']['App']['GetReadingState'](arg1);
+}
+
 export function GetSelectModelList() {
   return window['go']['main']['App']['GetSelectModelList']();
 }
@@ -58,6 +106,66 @@ export function InitSetOllamaModelTask() {
   return window['go']['main']['App']['InitSetOllamaModelTask']();
 }
 
+export function ListDictEntries() {
+  return window['go']['main']['App']['ListDictEntries']();
+}
+
+export function ListLibrary() {
+  return window['go']['main']['App']['ListLibrary']();
+}
+
+export function ListReaderBooks() {
+  return window['go']['main']['App']['ListReaderBooks']();
+}
+
+export function ListReaderChapters(arg1) {
+  return window['go']['main']['App']['ListReaderChapters'](arg1);
+}
+
+export function ListSubscriptions() {
+  return window['go']['main']['App']['ListSubscriptions']();
+}
+
+export function OpenLibraryFolder(arg1) {
+  return window['go']['main']['App']['OpenLibraryFolder'](arg1);
+}
+
+export function OpenOnlineBook(arg1) {
+  return window['go']['main']['App']['OpenOnlineBook'](arg1);
+}
+
+export function ReadChapter(arg1, arg2) {
+  return window['go']['main']['App']['ReadChapter'](arg1, arg2);
+}
+
+export function ReadOnlineChapter(arg1, arg2) {
+  return window['go']['main']['App']['ReadOnlineChapter'](arg1, arg2);
+}
+
+export function RefetchChapters(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RefetchChapters'](arg1, arg2, arg3);
+}
+
+export function SaveDictEntry(arg1) {
+  return window['go']['main']['App']['SaveDictEntry'](arg1);
+}
+
+export function SaveReadingPosition(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveReadingPosition'](arg1, arg2, arg3);
+}
+
+export function SearchFullText(arg1, arg2) {
+  return window['go']['main']['App']['SearchFullText'](arg1, arg2);
+}
+
+export function SearchLibrary(arg1) {
+  return window['go']['main']['App']['SearchLibrary'](arg1);
+}
+
+export function SelectBatchFile() {
+  return window['go']['main']['App']['SelectBatchFile']();
+}
+
 export function SerachNovel(arg1) {
   return window['go']['main']['App']['SerachNovel'](arg1);
 }
@@ -70,6 +178,26 @@ export function SetOllamaModel(arg1) {
   return window['go']['main']['App']['SetOllamaModel'](arg1);
 }
 
+export function SetSubscriptionAutoDownload(arg1, arg2) {
+  return window['go']['main']['App']['SetSubscriptionAutoDownload'](arg1, arg2);
+}
+
 export function StartChatbot(arg1) {
   return window['go']['main']['App']['StartChatbot'](arg1);
 }
+
+export function StartRecording() {
+  return window['go']['main']['App']['StartRecording']();
+}
+
+export function StopRecording() {
+  return window['go']['main']['App']['StopRecording']();
+}
+
+export function Subscribe(arg1, arg2) {
+  return window['go']['main']['App']['Subscribe'](arg1, arg2);
+}
+
+export function Unsubscribe(arg1) {
+  return window['go']['main']['App']['Unsubscribe'](arg1);
+}
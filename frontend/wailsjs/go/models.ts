@@ -1,7 +1,7 @@
 export namespace config {
 	
 	export class Info {
-	    // Go type: struct { SourceID int "mapstructure:\"source-id\" json:\"source-id\""; DownloadPath string "mapstructure:\"download-path\" json:\"download-path\""; Extname string "mapstructure:\"extname\" json:\"extname\""; LogLevel string "mapstructure:\"log-level\" json:\"log-level\"" }
+	    // Go type: struct { SourceID int "mapstructure:\"source-id\" json:\"source-id\""; DownloadPath string "mapstructure:\"download-path\" json:\"download-path\""; Extname string "mapstructure:\"extname\" json:\"extname\""; Layout string "mapstructure:\"layout\" json:\"layout\""; LogLevel string "mapstructure:\"log-level\" json:\"log-level\"" }
 	    base: any;
 	    // Go type: struct { Threads int "mapstructure:\"threads\" json:\"threads\"" }
 	    crawl: any;
@@ -9,6 +9,22 @@ export namespace config {
 	    retry: any;
 	    // Go type: struct { Model string "mapstructure:\"model\" json:\"model\"" }
 	    chatbot: any;
+	    // Go type: struct { CssPath string "mapstructure:\"css-path\" json:\"css-path\""; FontPath string "mapstructure:\"font-path\" json:\"font-path\""; Vertical bool "mapstructure:\"vertical\" json:\"vertical\"" }
+	    epub: any;
+	    // Go type: struct { PageSize string "mapstructure:\"page-size\" json:\"page-size\""; Margin float64 "mapstructure:\"margin\" json:\"margin\""; FontSize float64 "mapstructure:\"font-size\" json:\"font-size\""; FontPath string "mapstructure:\"font-path\" json:\"font-path\"" }
+	    pdf: any;
+	    // Go type: struct { Stages []string "mapstructure:\"stages\" json:\"stages\""; Replacements []config.Replacement "mapstructure:\"replacements\" json:\"replacements\""; KeepDuplicates bool "mapstructure:\"keep-duplicates\" json:\"keep-duplicates\"" }
+	    filter: any;
+	    // Go type: struct { Threshold int "mapstructure:\"threshold\" json:\"threshold\""; AutoRefetch bool "mapstructure:\"auto-refetch\" json:\"auto-refetch\""; FallbackSources []int "mapstructure:\"fallback-sources\" json:\"fallback-sources\"" }
+	    quality: any;
+	    // Go type: struct { StripPatterns []string "mapstructure:\"strip-patterns\" json:\"strip-patterns\""; Normalize bool "mapstructure:\"normalize\" json:\"normalize\""; Renumber bool "mapstructure:\"renumber\" json:\"renumber\"" }
+	    title: any;
+	    // Go type: struct { CheckInterval int "mapstructure:\"check-interval\" json:\"check-interval\"" }
+	    subscribe: any;
+	    // Go type: struct { Prefetch int "mapstructure:\"prefetch\" json:\"prefetch\""; CacheSize int "mapstructure:\"cache-size\" json:\"cache-size\"" }
+	    reader: any;
+	    // Go type: struct { Bypass bool "mapstructure:\"bypass\" json:\"bypass\""; Size int "mapstructure:\"size\" json:\"size\""; SearchTTL int "mapstructure:\"search-ttl\" json:\"search-ttl\""; CatalogTTL int "mapstructure:\"catalog-ttl\" json:\"catalog-ttl\""; ChapterTTL int "mapstructure:\"chapter-ttl\" json:\"chapter-ttl\"" }
+	    cache: any;
 	
 	    static createFrom(source: any = {}) {
 	        return new Info(source);
@@ -20,6 +36,14 @@ export namespace config {
 	        this.crawl = this.convertValues(source["crawl"], Object);
 	        this.retry = this.convertValues(source["retry"], Object);
 	        this.chatbot = this.convertValues(source["chatbot"], Object);
+	        this.epub = this.convertValues(source["epub"], Object);
+	        this.pdf = this.convertValues(source["pdf"], Object);
+	        this.filter = this.convertValues(source["filter"], Object);
+	        this.quality = this.convertValues(source["quality"], Object);
+	        this.title = this.convertValues(source["title"], Object);
+	        this.subscribe = this.convertValues(source["subscribe"], Object);
+	        this.reader = this.convertValues(source["reader"], Object);
+	        this.cache = this.convertValues(source["cache"], Object);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -45,44 +69,1293 @@ export namespace config {
 
 export namespace model {
 	
+	export class AddBookmarkResult {
+	    Bookmark: Bookmark;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AddBookmarkResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Bookmark = this.convertValues(source["Bookmark"], Bookmark);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BatchDownloadResult {
+	    Report: BatchReport;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchDownloadResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Report = this.convertValues(source["Report"], BatchReport);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BatchItem {
+	    line: number;
+	    name: string;
+	    author: string;
+	    sourceId: number;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.name = source["name"];
+	        this.author = source["author"];
+	        this.sourceId = source["sourceId"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class BatchItemResult {
+	    item: BatchItem;
+	    status: string;
+	    book?: SearchResult;
+	    candidates?: SearchResult[];
+	    outputPath?: string;
+	    suspects: number;
+	    error?: string;
+	    takeTime: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchItemResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.item = this.convertValues(source["item"], BatchItem);
+	        this.status = source["status"];
+	        this.book = this.convertValues(source["book"], SearchResult);
+	        this.candidates = this.convertValues(source["candidates"], SearchResult);
+	        this.outputPath = source["outputPath"];
+	        this.suspects = source["suspects"];
+	        this.error = source["error"];
+	        this.takeTime = source["takeTime"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BatchReport {
+	    items: BatchItemResult[];
+	    succeeded: number;
+	    ambiguous: number;
+	    notFound: number;
+	    failed: number;
+	    takeTime: number;
+	    reportPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], BatchItemResult);
+	        this.succeeded = source["succeeded"];
+	        this.ambiguous = source["ambiguous"];
+	        this.notFound = source["notFound"];
+	        this.failed = source["failed"];
+	        this.takeTime = source["takeTime"];
+	        this.reportPath = source["reportPath"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Book {
+	    url: string;
+	    bookName: string;
+	    author: string;
+	    intro: string;
+	    category: string;
+	    coverUrl: string;
+	    latestChapter: string;
+	    latestUpdate: string;
+	    isEnd: string;
+	    catalog: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Book(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.bookName = source["bookName"];
+	        this.author = source["author"];
+	        this.intro = source["intro"];
+	        this.category = source["category"];
+	        this.coverUrl = source["coverUrl"];
+	        this.latestChapter = source["latestChapter"];
+	        this.latestUpdate = source["latestUpdate"];
+	        this.isEnd = source["isEnd"];
+	        this.catalog = source["catalog"];
+	    }
+	}
+	export class Bookmark {
+	    id: string;
+	    seq: number;
+	    offset: number;
+	    title: string;
+	    note: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Bookmark(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.seq = source["seq"];
+	        this.offset = source["offset"];
+	        this.title = source["title"];
+	        this.note = source["note"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CacheStats {
+	    entries: number;
+	    size: number;
+	    maxSize: number;
+	    hits: number;
+	    misses: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CacheStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = source["entries"];
+	        this.size = source["size"];
+	        this.maxSize = source["maxSize"];
+	        this.hits = source["hits"];
+	        this.misses = source["misses"];
+	    }
+	}
+	export class CacheStatsResult {
+	    HTTP: CacheStats;
+	    Chapters: CacheStats;
+	
+	    static createFrom(source: any = {}) {
+	        return new CacheStatsResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.HTTP = this.convertValues(source["HTTP"], CacheStats);
+	        this.Chapters = this.convertValues(source["Chapters"], CacheStats);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CatalogDuplicate {
+	    Volume: string;
+	    Number: number;
+	    Titles: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogDuplicate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Volume = source["Volume"];
+	        this.Number = source["Number"];
+	        this.Titles = source["Titles"];
+	    }
+	}
+	export class CatalogGap {
+	    Volume: string;
+	    From: number;
+	    To: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogGap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Volume = source["Volume"];
+	        this.From = source["From"];
+	        this.To = source["To"];
+	    }
+	}
+	export class CatalogReport {
+	    Total: number;
+	    Numbered: number;
+	    Gaps: CatalogGap[];
+	    Duplicates: CatalogDuplicate[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Total = source["Total"];
+	        this.Numbered = source["Numbered"];
+	        this.Gaps = this.convertValues(source["Gaps"], CatalogGap);
+	        this.Duplicates = this.convertValues(source["Duplicates"], CatalogDuplicate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CheckCatalogResult {
+	    Report: CatalogReport;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckCatalogResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Report = this.convertValues(source["Report"], CatalogReport);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CheckSubscriptionsResult {
+	    Updates: SubscriptionUpdate[];
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckSubscriptionsResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Updates = this.convertValues(source["Updates"], SubscriptionUpdate);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class CrawlResult {
 	    OutputPath: string;
 	    TakeTime: number;
+	    Dedup: DedupReport[];
+	    Suspects: SuspectChapter[];
+	    Catalog: CatalogReport;
+	
+	    static createFrom(source: any = {}) {
+	        return new CrawlResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.OutputPath = source["OutputPath"];
+	        this.TakeTime = source["TakeTime"];
+	        this.Dedup = this.convertValues(source["Dedup"], DedupReport);
+	        this.Suspects = this.convertValues(source["Suspects"], SuspectChapter);
+	        this.Catalog = this.convertValues(source["Catalog"], CatalogReport);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DedupReport {
+	    ChapterNo: number;
+	    ChapterTitle: string;
+	    Kind: string;
+	    Paragraphs: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DedupReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ChapterNo = source["ChapterNo"];
+	        this.ChapterTitle = source["ChapterTitle"];
+	        this.Kind = source["Kind"];
+	        this.Paragraphs = source["Paragraphs"];
+	    }
+	}
+	export class DictDryRunChange {
+	    BookName: string;
+	    Author: string;
+	    ChapterIndex: number;
+	    ChapterTitle: string;
+	    Count: number;
+	    Before: string;
+	    After: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DictDryRunChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.BookName = source["BookName"];
+	        this.Author = source["Author"];
+	        this.ChapterIndex = source["ChapterIndex"];
+	        this.ChapterTitle = source["ChapterTitle"];
+	        this.Count = source["Count"];
+	        this.Before = source["Before"];
+	        this.After = source["After"];
+	    }
+	}
+	export class DictEntry {
+	    id: string;
+	    pattern: string;
+	    replace: string;
+	    regex: boolean;
+	    book: string;
+	    sourceId: number;
+	    disabled: boolean;
+	    comment: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DictEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.pattern = source["pattern"];
+	        this.replace = source["replace"];
+	        this.regex = source["regex"];
+	        this.book = source["book"];
+	        this.sourceId = source["sourceId"];
+	        this.disabled = source["disabled"];
+	        this.comment = source["comment"];
+	    }
+	}
+	export class DryRunDictEntryResult {
+	    Changes: DictDryRunChange[];
+	    ChapterCount: number;
+	    MatchCount: number;
+	    Truncated: boolean;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DryRunDictEntryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Changes = this.convertValues(source["Changes"], DictDryRunChange);
+	        this.ChapterCount = source["ChapterCount"];
+	        this.MatchCount = source["MatchCount"];
+	        this.Truncated = source["Truncated"];
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FullTextHit {
+	    bookId: string;
+	    bookName: string;
+	    author: string;
+	    bookUrl: string;
+	    seq: number;
+	    chapterNo: number;
+	    chapterTitle: string;
+	    snippet: string;
+	    highlights: TextRange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FullTextHit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bookId = source["bookId"];
+	        this.bookName = source["bookName"];
+	        this.author = source["author"];
+	        this.bookUrl = source["bookUrl"];
+	        this.seq = source["seq"];
+	        this.chapterNo = source["chapterNo"];
+	        this.chapterTitle = source["chapterTitle"];
+	        this.snippet = source["snippet"];
+	        this.highlights = this.convertValues(source["highlights"], TextRange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FullTextSearchResult {
+	    Hits: FullTextHit[];
+	    Truncated: boolean;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FullTextSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Hits = this.convertValues(source["Hits"], FullTextHit);
+	        this.Truncated = source["Truncated"];
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GenerateAsciiImageResult {
+	    Response: string;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GenerateAsciiImageResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Response = source["Response"];
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	}
+	export class GetConfigResult {
+	    Config: config.Info;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetConfigResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Config = this.convertValues(source["Config"], config.Info);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GetCurrentUseModelResult {
+	    Model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetCurrentUseModelResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Model = source["Model"];
+	    }
+	}
+	export class GetSelectModelListResult {
+	    Models: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GetSelectModelListResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Models = source["Models"];
+	    }
+	}
+	export class GetSetOllamaModelProgressResult {
+	    Exists: boolean;
+	    Completed: number;
+	    Total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetSetOllamaModelProgressResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Exists = source["Exists"];
+	        this.Completed = source["Completed"];
+	        this.Total = source["Total"];
+	    }
+	}
+	export class GetUpdateInfoResult {
+	    ErrorMsg: string;
+	    NeedUpdate: boolean;
+	    LatestVersion: string;
+	    CurrentVersion: string;
+	    LatestUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetUpdateInfoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ErrorMsg = source["ErrorMsg"];
+	        this.NeedUpdate = source["NeedUpdate"];
+	        this.LatestVersion = source["LatestVersion"];
+	        this.CurrentVersion = source["CurrentVersion"];
+	        this.LatestUrl = source["LatestUrl"];
+	    }
+	}
+	export class GetUsageInfoResult {
+	    VersionInfo: string;
+	    Address: string;
+	    CurrentBookSource: string;
+	    ExportFormat: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetUsageInfoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.VersionInfo = source["VersionInfo"];
+	        this.Address = source["Address"];
+	        this.CurrentBookSource = source["CurrentBookSource"];
+	        this.ExportFormat = source["ExportFormat"];
+	    }
+	}
+	export class HasInitOllamaResult {
+	    Has: boolean;
+	    IsInit: boolean;
+	    IsSetModel: boolean;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HasInitOllamaResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Has = source["Has"];
+	        this.IsInit = source["IsInit"];
+	        this.IsSetModel = source["IsSetModel"];
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	}
+	export class InitOllamaProgressResult {
+	    Exists: boolean;
+	    Completed: number;
+	    Total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new InitOllamaProgressResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Exists = source["Exists"];
+	        this.Completed = source["Completed"];
+	        this.Total = source["Total"];
+	    }
+	}
+	export class InitOllamaResult {
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InitOllamaResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	}
+	export class InitSetOllamaModelResult {
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InitSetOllamaModelResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	}
+	export class LibraryEntry {
+	    id: string;
+	    book: Book;
+	    sourceId: number;
+	    outputs: LibraryOutput[];
+	    chapterCount: number;
+	    catalogCount: number;
+	    // Go type: time
+	    downloadedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new LibraryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.book = this.convertValues(source["book"], Book);
+	        this.sourceId = source["sourceId"];
+	        this.outputs = this.convertValues(source["outputs"], LibraryOutput);
+	        this.chapterCount = source["chapterCount"];
+	        this.catalogCount = source["catalogCount"];
+	        this.downloadedAt = this.convertValues(source["downloadedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LibraryOutput {
+	    format: string;
+	    path: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new LibraryOutput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.path = source["path"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ListDictEntriesResult {
+	    Entries: DictEntry[];
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListDictEntriesResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Entries = this.convertValues(source["Entries"], DictEntry);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ListLibraryResult {
+	    Entries: LibraryEntry[];
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListLibraryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Entries = this.convertValues(source["Entries"], LibraryEntry);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ListReaderBooksResult {
+	    Books: ReaderBook[];
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListReaderBooksResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Books = this.convertValues(source["Books"], ReaderBook);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ListReaderChaptersResult {
+	    Chapters: ReaderChapter[];
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListReaderChaptersResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Chapters = this.convertValues(source["Chapters"], ReaderChapter);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ListSubscriptionsResult {
+	    Subscriptions: Subscription[];
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListSubscriptionsResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Subscriptions = this.convertValues(source["Subscriptions"], Subscription);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OnlineBook {
+	    id: string;
+	    book: Book;
+	    sourceId: number;
+	    chapters: ReaderChapter[];
+	    reading?: ReadingState;
+	
+	    static createFrom(source: any = {}) {
+	        return new OnlineBook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.book = this.convertValues(source["book"], Book);
+	        this.sourceId = source["sourceId"];
+	        this.chapters = this.convertValues(source["chapters"], ReaderChapter);
+	        this.reading = this.convertValues(source["reading"], ReadingState);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OpenOnlineBookResult {
+	    Book: OnlineBook;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OpenOnlineBookResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Book = this.convertValues(source["Book"], OnlineBook);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProgressResult {
+	    Exists: boolean;
+	    Completed: number;
+	    Total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProgressResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Exists = source["Exists"];
+	        this.Completed = source["Completed"];
+	        this.Total = source["Total"];
+	    }
+	}
+	export class ReadChapterResult {
+	    Chapter: ReaderChapterContent;
+	    ErrorMsg: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReadChapterResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Chapter = this.convertValues(source["Chapter"], ReaderChapterContent);
+	        this.ErrorMsg = source["ErrorMsg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReaderBook {
+	    id: string;
+	    book: Book;
+	    sourceId: number;
+	    inLibrary: boolean;
+	    // Go type: time
+	    fetchedAt: any;
+	    reading?: ReadingState;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReaderBook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.book = this.convertValues(source["book"], Book);
+	        this.sourceId = source["sourceId"];
+	        this.inLibrary = source["inLibrary"];
+	        this.fetchedAt = this.convertValues(source["fetchedAt"], null);
+	        this.reading = this.convertValues(source["reading"], ReadingState);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReaderChapter {
+	    seq: number;
+	    chapterNo: number;
+	    title: string;
+	    available: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new CrawlResult(source);
+	        return new ReaderChapter(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.OutputPath = source["OutputPath"];
-	        this.TakeTime = source["TakeTime"];
+	        this.seq = source["seq"];
+	        this.chapterNo = source["chapterNo"];
+	        this.title = source["title"];
+	        this.available = source["available"];
 	    }
 	}
-	export class GenerateAsciiImageResult {
-	    Response: string;
-	    ErrorMsg: string;
+	export class ReaderChapterContent {
+	    bookId: string;
+	    seq: number;
+	    title: string;
+	    content: string;
+	    prev: number;
+	    next: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new GenerateAsciiImageResult(source);
+	        return new ReaderChapterContent(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Response = source["Response"];
-	        this.ErrorMsg = source["ErrorMsg"];
+	        this.bookId = source["bookId"];
+	        this.seq = source["seq"];
+	        this.title = source["title"];
+	        this.content = source["content"];
+	        this.prev = source["prev"];
+	        this.next = source["next"];
 	    }
 	}
-	export class GetConfigResult {
-	    Config: config.Info;
+	export class ReadingState {
+	    bookId: string;
+	    seq: number;
+	    offset: number;
+	    // Go type: time
+	    updatedAt: any;
+	    bookmarks: Bookmark[];
 	
 	    static createFrom(source: any = {}) {
-	        return new GetConfigResult(source);
+	        return new ReadingState(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Config = this.convertValues(source["Config"], config.Info);
+	        this.bookId = source["bookId"];
+	        this.seq = source["seq"];
+	        this.offset = source["offset"];
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.bookmarks = this.convertValues(source["bookmarks"], Bookmark);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -103,204 +1376,320 @@ export namespace model {
 		    return a;
 		}
 	}
-	export class GetCurrentUseModelResult {
-	    Model: string;
+	export class ReadingStateResult {
+	    State: ReadingState;
+	    ErrorMsg: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GetCurrentUseModelResult(source);
+	        return new ReadingStateResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Model = source["Model"];
-	    }
-	}
-	export class GetSelectModelListResult {
-	    Models: string[];
-	
-	    static createFrom(source: any = {}) {
-	        return new GetSelectModelListResult(source);
+	        this.State = this.convertValues(source["State"], ReadingState);
+	        this.ErrorMsg = source["ErrorMsg"];
 	    }
 	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Models = source["Models"];
-	    }
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class GetSetOllamaModelProgressResult {
-	    Exists: boolean;
-	    Completed: number;
-	    Total: number;
+	export class RefetchChaptersResult {
+	    Result: CrawlResult;
+	    ErrorMsg: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GetSetOllamaModelProgressResult(source);
+	        return new RefetchChaptersResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Exists = source["Exists"];
-	        this.Completed = source["Completed"];
-	        this.Total = source["Total"];
+	        this.Result = this.convertValues(source["Result"], CrawlResult);
+	        this.ErrorMsg = source["ErrorMsg"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class GetUpdateInfoResult {
+	export class SaveDictEntryResult {
+	    Entry: DictEntry;
 	    ErrorMsg: string;
-	    NeedUpdate: boolean;
-	    LatestVersion: string;
-	    CurrentVersion: string;
-	    LatestUrl: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GetUpdateInfoResult(source);
+	        return new SaveDictEntryResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Entry = this.convertValues(source["Entry"], DictEntry);
 	        this.ErrorMsg = source["ErrorMsg"];
-	        this.NeedUpdate = source["NeedUpdate"];
-	        this.LatestVersion = source["LatestVersion"];
-	        this.CurrentVersion = source["CurrentVersion"];
-	        this.LatestUrl = source["LatestUrl"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class GetUsageInfoResult {
-	    VersionInfo: string;
-	    Address: string;
-	    CurrentBookSource: string;
-	    ExportFormat: string;
+	export class SearchResult {
+	    url: string;
+	    bookName: string;
+	    author: string;
+	    intro: string;
+	    latestChapter: string;
+	    latestUpdate: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GetUsageInfoResult(source);
+	        return new SearchResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.VersionInfo = source["VersionInfo"];
-	        this.Address = source["Address"];
-	        this.CurrentBookSource = source["CurrentBookSource"];
-	        this.ExportFormat = source["ExportFormat"];
+	        this.url = source["url"];
+	        this.bookName = source["bookName"];
+	        this.author = source["author"];
+	        this.intro = source["intro"];
+	        this.latestChapter = source["latestChapter"];
+	        this.latestUpdate = source["latestUpdate"];
 	    }
 	}
-	export class HasInitOllamaResult {
-	    Has: boolean;
-	    IsInit: boolean;
-	    IsSetModel: boolean;
+	export class SetOllamaModelResult {
 	    ErrorMsg: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new HasInitOllamaResult(source);
+	        return new SetOllamaModelResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Has = source["Has"];
-	        this.IsInit = source["IsInit"];
-	        this.IsSetModel = source["IsSetModel"];
 	        this.ErrorMsg = source["ErrorMsg"];
 	    }
 	}
-	export class InitOllamaProgressResult {
-	    Exists: boolean;
-	    Completed: number;
-	    Total: number;
+	export class StartChatbotResult {
+	    Response: string;
+	    ErrorMsg: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new InitOllamaProgressResult(source);
+	        return new StartChatbotResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Exists = source["Exists"];
-	        this.Completed = source["Completed"];
-	        this.Total = source["Total"];
+	        this.Response = source["Response"];
+	        this.ErrorMsg = source["ErrorMsg"];
 	    }
 	}
-	export class InitOllamaResult {
+	export class StopRecordingResult {
+	    Path: string;
+	    Entries: number;
 	    ErrorMsg: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new InitOllamaResult(source);
+	        return new StopRecordingResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Path = source["Path"];
+	        this.Entries = source["Entries"];
 	        this.ErrorMsg = source["ErrorMsg"];
 	    }
 	}
-	export class InitSetOllamaModelResult {
+	export class SubscribeResult {
+	    Subscription: Subscription;
 	    ErrorMsg: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new InitSetOllamaModelResult(source);
+	        return new SubscribeResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Subscription = this.convertValues(source["Subscription"], Subscription);
 	        this.ErrorMsg = source["ErrorMsg"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ProgressResult {
-	    Exists: boolean;
-	    Completed: number;
-	    Total: number;
+	export class Subscription {
+	    id: string;
+	    book: Book;
+	    sourceId: number;
+	    autoDownload: boolean;
+	    chapterCount: number;
+	    latestChapter: string;
+	    baseline: number;
+	    newChapters: number;
+	    // Go type: time
+	    checkedAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	    lastError: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ProgressResult(source);
+	        return new Subscription(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Exists = source["Exists"];
-	        this.Completed = source["Completed"];
-	        this.Total = source["Total"];
+	        this.id = source["id"];
+	        this.book = this.convertValues(source["book"], Book);
+	        this.sourceId = source["sourceId"];
+	        this.autoDownload = source["autoDownload"];
+	        this.chapterCount = source["chapterCount"];
+	        this.latestChapter = source["latestChapter"];
+	        this.baseline = source["baseline"];
+	        this.newChapters = source["newChapters"];
+	        this.checkedAt = this.convertValues(source["checkedAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.lastError = source["lastError"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class SearchResult {
-	    url: string;
-	    bookName: string;
-	    author: string;
-	    intro: string;
-	    latestChapter: string;
-	    latestUpdate: string;
+	export class SubscriptionUpdate {
+	    subscription: Subscription;
+	    newTitles: string[];
+	    outputPath: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SearchResult(source);
+	        return new SubscriptionUpdate(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.url = source["url"];
-	        this.bookName = source["bookName"];
-	        this.author = source["author"];
-	        this.intro = source["intro"];
-	        this.latestChapter = source["latestChapter"];
-	        this.latestUpdate = source["latestUpdate"];
+	        this.subscription = this.convertValues(source["subscription"], Subscription);
+	        this.newTitles = source["newTitles"];
+	        this.outputPath = source["outputPath"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class SetOllamaModelResult {
-	    ErrorMsg: string;
+	export class SuspectChapter {
+	    Seq: number;
+	    ChapterNo: number;
+	    Title: string;
+	    URL: string;
+	    Length: number;
+	    Score: number;
+	    Reasons: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new SetOllamaModelResult(source);
+	        return new SuspectChapter(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.ErrorMsg = source["ErrorMsg"];
+	        this.Seq = source["Seq"];
+	        this.ChapterNo = source["ChapterNo"];
+	        this.Title = source["Title"];
+	        this.URL = source["URL"];
+	        this.Length = source["Length"];
+	        this.Score = source["Score"];
+	        this.Reasons = source["Reasons"];
 	    }
 	}
-	export class StartChatbotResult {
-	    Response: string;
-	    ErrorMsg: string;
+	export class TextRange {
+	    start: number;
+	    end: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new StartChatbotResult(source);
+	        return new TextRange(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Response = source["Response"];
-	        this.ErrorMsg = source["ErrorMsg"];
+	        this.start = source["start"];
+	        this.end = source["end"];
 	    }
 	}
 	export class YukkuriParams {
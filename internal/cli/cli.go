@@ -0,0 +1,253 @@
+// Package cli 命令行界面, 与图形界面共用 functions 中的下载、配置与检查更新, 不依赖 webview,
+// 可在无图形界面的 Linux 服务器上运行. 加 -json 时结果以 JSON 输出到标准输出, 日志与进度输出到标准错误.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fy-novel/internal/config"
+	harTool "fy-novel/internal/tools/har"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 退出码
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// usageError 参数错误, 退出码为 2 并打印命令用法
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, a ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	// flags 注册命令自己的参数, 可以为 nil
+	flags func(c *cli, fs *flag.FlagSet)
+	run   func(c *cli, fs *flag.FlagSet) error
+}
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	log    *logrus.Logger
+	json   bool
+	quiet  bool
+	// batch 参数
+	parallel int
+	report   string
+	// serve 参数
+	addr   string
+	token  string
+	webDir string
+	// 命令行参数对配置的临时覆盖
+	override overrideFlags
+}
+
+var commands []*command
+
+func register(cmd *command) {
+	commands = append(commands, cmd)
+}
+
+// Run 执行命令行, args 不含程序名, 返回退出码
+func Run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr, log: newLogger(stderr)}
+
+	global := flag.NewFlagSet("fy-novel", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.BoolVar(&c.json, "json", false, "以 JSON 格式输出结果")
+	global.Usage = func() { c.usage() }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if global.NArg() == 0 {
+		c.usage()
+		return exitUsage
+	}
+
+	name := global.Arg(0)
+	if name == "help" {
+		c.usage()
+		return exitOK
+	}
+	var cmd *command
+	for _, cc := range commands {
+		if cc.name == name {
+			cmd = cc
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		c.usage()
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("fy-novel "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&c.json, "json", c.json, "以 JSON 格式输出结果")
+	if cmd.flags != nil {
+		cmd.flags(c, fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: fy-novel %s [参数] %s\n\n%s\n\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(global.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if err := cmd.run(c, fs); err != nil {
+		var ue *usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintf(stderr, "%v\n\n", err)
+			fs.Usage()
+			return exitUsage
+		}
+		if c.json {
+			c.output(map[string]string{"error": err.Error()}, nil)
+		}
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "用法: fy-novel [-json] <命令> [参数]")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "使用 fy-novel <命令> -h 查看命令的参数")
+}
+
+// output 输出结果: -json 时输出 v, 否则调用 text 输出可读文本
+func (c *cli) output(v any, text func(w io.Writer)) {
+	if c.json || text == nil {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+		return
+	}
+	text(c.stdout)
+}
+
+// newLogger 日志输出到标准错误, 级别与图形界面一样取配置中的 log-level, 默认只输出错误
+func newLogger(w io.Writer) *logrus.Logger {
+	log := logrus.New()
+	log.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	log.SetOutput(w)
+	log.SetLevel(logrus.ErrorLevel)
+	if level, err := logrus.ParseLevel(config.GetConf().Base.LogLevel); err == nil {
+		log.SetLevel(level)
+	}
+	return log
+}
+
+// overrideFlags 注册覆盖配置的参数 (只在本次运行中生效, 不保存)
+type overrideFlags struct {
+	sourceID int
+	extname  string
+	path     string
+	noCache  bool
+	// 录制或回放抓取请求的 HAR 文件
+	record string
+	replay string
+}
+
+func (o *overrideFlags) register(fs *flag.FlagSet, download bool) {
+	fs.IntVar(&o.sourceID, "source", 0, "书源 ID, 默认使用配置中的书源")
+	fs.BoolVar(&o.noCache, "no-cache", false, "不读取抓取缓存, 总是从书源获取")
+	fs.StringVar(&o.record, "record", "", "将全部抓取请求与响应录制到 HAR 文件, 用于反馈书源问题")
+	fs.StringVar(&o.replay, "replay", "", "以录制的 HAR 文件回答抓取请求, 不访问网络")
+	if download {
+		fs.StringVar(&o.extname, "format", "", "导出格式: txt, epub, html, md, fb2, pdf")
+		fs.StringVar(&o.path, "path", "", "下载目录")
+	}
+}
+
+// apply 覆盖配置, 并按 -record 或 -replay 开始录制或回放
+func (o *overrideFlags) apply() error {
+	conf := config.GetConf()
+	if o.sourceID != 0 {
+		conf.Base.SourceID = o.sourceID
+	}
+	if o.extname != "" {
+		conf.Base.Extname = strings.ToLower(o.extname)
+	}
+	if o.path != "" {
+		conf.Base.DownloadPath = o.path
+	}
+	if o.noCache {
+		conf.Cache.Bypass = true
+	}
+	config.Override(conf)
+
+	switch {
+	case o.record != "" && o.replay != "":
+		return usagef("-record and -replay cannot be used together")
+	case o.record != "":
+		return harTool.Record(harTool.NewRecorder())
+	case o.replay != "":
+		archive, err := harTool.Load(o.replay)
+		if err != nil {
+			return err
+		}
+		return harTool.Replay(harTool.NewReplayer(archive))
+	}
+	return nil
+}
+
+// finish 结束录制或回放, 录制的文件在命令出错时也保存, 便于反馈问题
+func (o *overrideFlags) finish(w io.Writer, err *error) {
+	r := harTool.Stop()
+	if r == nil || o.record == "" {
+		return
+	}
+	if serr := r.Save(o.record); serr != nil {
+		if *err == nil {
+			*err = serr
+		}
+		return
+	}
+	fmt.Fprintf(w, "已录制 %d 个请求: %s\n", r.Len(), o.record)
+}
+
+// isTerminal 标准错误是终端时才刷新进度
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
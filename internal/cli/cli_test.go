@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/config"
+)
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestUsage(t *testing.T) {
+	if code, _, stderr := run(t); code != exitUsage || !strings.Contains(stderr, "download") {
+		t.Errorf("no command: %d %q", code, stderr)
+	}
+	if code, _, _ := run(t, "nope"); code != exitUsage {
+		t.Errorf("unknown command: %d", code)
+	}
+	if code, _, stderr := run(t, "download"); code != exitUsage || !strings.Contains(stderr, "-format") {
+		t.Errorf("missing argument: %d %q", code, stderr)
+	}
+	if code, _, _ := run(t, "search", "-h"); code != exitOK {
+		t.Errorf("help: %d", code)
+	}
+}
+
+func TestSources(t *testing.T) {
+	code, stdout, _ := run(t, "-json", "sources")
+	if code != exitOK {
+		t.Fatalf("exit %d", code)
+	}
+	var sources []sourceInfo
+	if err := json.Unmarshal([]byte(stdout), &sources); err != nil {
+		t.Fatal(err)
+	}
+	current := 0
+	for _, s := range sources {
+		if s.URL == "" {
+			t.Errorf("source %d has no url", s.ID)
+		}
+		if s.Current {
+			current++
+		}
+	}
+	if len(sources) == 0 || current != 1 {
+		t.Errorf("sources = %+v", sources)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saved := config.GetConf()
+	defer config.Override(saved)
+
+	if code, stdout, _ := run(t, "config", "set", "quality.threshold", "70"); code != exitOK || stdout != "70\n" {
+		t.Errorf("set number: %d %q", code, stdout)
+	}
+	if code, stdout, _ := run(t, "config", "set", "base.extname", "txt"); code != exitOK || stdout != "txt\n" {
+		t.Errorf("set string: %d %q", code, stdout)
+	}
+	if code, stdout, _ := run(t, "config", "set", "quality.fallback-sources", "[2,4]"); code != exitOK || !strings.Contains(stdout, "4") {
+		t.Errorf("set array: %d %q", code, stdout)
+	}
+	if code, stdout, _ := run(t, "config", "get", "base.extname"); code != exitOK || stdout != "txt\n" {
+		t.Errorf("get: %d %q", code, stdout)
+	}
+
+	code, stdout, _ := run(t, "-json", "config", "get")
+	var all map[string]map[string]any
+	if code != exitOK || json.Unmarshal([]byte(stdout), &all) != nil || all["quality"]["threshold"] != 70.0 {
+		t.Errorf("get all: %d %q", code, stdout)
+	}
+
+	code, stdout, _ = run(t, "-json", "config", "set", "base.nope", "1")
+	if code != exitError || !strings.Contains(stdout, `"error"`) {
+		t.Errorf("unknown key: %d %q", code, stdout)
+	}
+}
+
+func TestOverrideNotSaved(t *testing.T) {
+	saved := config.GetConf()
+	defer config.Override(saved)
+
+	o := overrideFlags{sourceID: 3, extname: "EPUB", path: "out", noCache: true}
+	if err := o.apply(); err != nil {
+		t.Fatal(err)
+	}
+	conf := config.GetConf()
+	if conf.Base.SourceID != 3 || conf.Base.Extname != "epub" || conf.Base.DownloadPath != "out" {
+		t.Errorf("override = %+v", conf.Base)
+	}
+	if !conf.Cache.Bypass {
+		t.Error("-no-cache should bypass the crawl cache")
+	}
+}
+
+func TestServeBadAddr(t *testing.T) {
+	if code, _, stderr := run(t, "serve", "-addr", "256.0.0.1:x"); code != exitError || stderr == "" {
+		t.Errorf("bad address: %d %q", code, stderr)
+	}
+	if code, _, _ := run(t, "serve", "extra"); code != exitUsage {
+		t.Errorf("extra argument: %d", code)
+	}
+}
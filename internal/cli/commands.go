@@ -0,0 +1,390 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/functions"
+	"fy-novel/internal/model"
+	"fy-novel/internal/source"
+	progressTool "fy-novel/internal/tools/progress"
+	"fy-novel/internal/version"
+)
+
+func init() {
+	register(&command{
+		name:    "search",
+		args:    "<书名或作者>",
+		summary: "在书源中搜索小说",
+		flags:   func(c *cli, fs *flag.FlagSet) { c.override.register(fs, false) },
+		run:     runSearch,
+	})
+	register(&command{
+		name:    "download",
+		args:    "<书籍地址>",
+		summary: "下载小说并导出为配置的格式",
+		flags: func(c *cli, fs *flag.FlagSet) {
+			c.override.register(fs, true)
+			fs.BoolVar(&c.quiet, "quiet", false, "不显示下载进度")
+		},
+		run: runDownload,
+	})
+	register(&command{
+		name:    "batch",
+		args:    "<书单文件>",
+		summary: "按书单批量下载 (纯文本每行一个书名, 或含 name, author, source, range 的 CSV/JSON)",
+		flags: func(c *cli, fs *flag.FlagSet) {
+			fs.IntVar(&c.parallel, "parallel", 1, "同时下载的书籍数, 共用配置的线程数")
+			fs.StringVar(&c.report, "report", "", "报告文件路径, 默认写在书单旁边")
+		},
+		run: runBatch,
+	})
+	register(&command{
+		name:    "update",
+		summary: "检查订阅的书籍是否有新章节, 开启自动下载的书籍会下载新章节",
+		run:     runUpdate,
+	})
+	register(&command{
+		name:    "serve",
+		summary: "启动本地 HTTP 服务, 以 JSON REST API 提供搜索、下载任务、书库与配置 (接口说明见 /api/openapi.json), 并提供网页界面与 /opds 书库目录",
+		flags: func(c *cli, fs *flag.FlagSet) {
+			fs.StringVar(&c.addr, "addr", "127.0.0.1:8080", "监听地址")
+			fs.StringVar(&c.token, "token", os.Getenv("FY_NOVEL_TOKEN"), "访问令牌, 请求需带 Authorization: Bearer <令牌>, 默认取环境变量 FY_NOVEL_TOKEN")
+			fs.StringVar(&c.webDir, "web", "frontend/dist", "网页界面目录 (前端构建产物), 为空时不提供网页界面")
+		},
+		run: runServe,
+	})
+	register(&command{
+		name:    "config",
+		args:    "get [分组.键] | set <分组.键> <值>",
+		summary: "查看或修改配置, 例如 config set base.extname txt",
+		run:     runConfig,
+	})
+	register(&command{
+		name:    "cache",
+		args:    "[stats | clear]",
+		summary: "查看或清空抓取页面缓存",
+		run:     runCache,
+	})
+	register(&command{
+		name:    "sources",
+		summary: "列出内置书源",
+		run:     runSources,
+	})
+	register(&command{
+		name:    "check-update",
+		summary: "检查是否有新版本",
+		run:     runCheckUpdate,
+	})
+	register(&command{
+		name:    "version",
+		summary: "显示版本信息",
+		run:     runVersion,
+	})
+}
+
+func runSearch(c *cli, fs *flag.FlagSet) (err error) {
+	if fs.NArg() == 0 {
+		return usagef("missing keyword")
+	}
+	if err := c.override.apply(); err != nil {
+		return err
+	}
+	defer c.override.finish(c.stderr, &err)
+	res, err := functions.NewDownload(c.log).Serach(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		res = []*model.SearchResult{}
+	}
+	c.output(res, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "#\t书名\t作者\t最新章节\t地址")
+		for i, r := range res {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", i+1, r.BookName, r.Author, r.LatestChapter, r.Url)
+		}
+		tw.Flush()
+	})
+	return nil
+}
+
+func runDownload(c *cli, fs *flag.FlagSet) (err error) {
+	if fs.NArg() != 1 {
+		return usagef("expected one book url")
+	}
+	if err := c.override.apply(); err != nil {
+		return err
+	}
+	defer c.override.finish(c.stderr, &err)
+	sr := &model.SearchResult{Url: fs.Arg(0)}
+
+	done := make(chan struct{})
+	if !c.quiet && isTerminal(c.stderr) {
+		go c.showProgress(sr.Url, done)
+	}
+	res, err := functions.NewDownload(c.log).DownLoad(sr)
+	close(done)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return fmt.Errorf("empty catalog for %s", sr.Url)
+	}
+	c.output(res, func(w io.Writer) {
+		fmt.Fprintf(w, "已保存: %s (耗时 %d 秒)\n", res.OutputPath, res.TakeTime)
+		if len(res.Dedup) > 0 {
+			fmt.Fprintf(w, "去除重复段落: %d 章\n", len(res.Dedup))
+		}
+		for _, gap := range res.Catalog.Gaps {
+			fmt.Fprintf(w, "目录缺失: %s 第%d-%d章\n", gap.Volume, gap.From, gap.To)
+		}
+		for _, s := range res.Suspects {
+			fmt.Fprintf(w, "可疑章节: %s (%s)\n", s.Title, strings.Join(s.Reasons, ", "))
+		}
+	})
+	return nil
+}
+
+// showProgress 在标准错误刷新下载进度, 直到 done 关闭
+func (c *cli) showProgress(url string, done <-chan struct{}) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			fmt.Fprint(c.stderr, "\r\033[K")
+			return
+		case <-ticker.C:
+			if completed, total, ok := progressTool.GetProgress(url); ok {
+				fmt.Fprintf(c.stderr, "\r下载中 %d/%d", completed, total)
+			}
+		}
+	}
+}
+
+func runBatch(c *cli, fs *flag.FlagSet) error {
+	if fs.NArg() != 1 {
+		return usagef("expected one list file")
+	}
+	report, err := functions.NewBatchDownloader(c.log).Run(fs.Arg(0), c.parallel, c.report, func(p model.BatchProgress) {
+		fmt.Fprintf(c.stderr, "[%d/%d] %s: %s\n", p.Done, p.Total, p.Result.Item.Name, p.Result.Status)
+	})
+	if err != nil {
+		return err
+	}
+	c.output(report, func(w io.Writer) {
+		fmt.Fprintf(w, "成功 %d, 歧义 %d, 未找到 %d, 失败 %d, 耗时 %d 秒\n",
+			report.Succeeded, report.Ambiguous, report.NotFound, report.Failed, report.TakeTime)
+		for _, r := range report.Items {
+			switch r.Status {
+			case model.BatchOK:
+				continue
+			case model.BatchAmbiguous:
+				fmt.Fprintf(w, "第 %d 行 %s: 有多本同名书, 请填写作者\n", r.Item.Line, r.Item.Name)
+				for _, b := range r.Candidates {
+					fmt.Fprintf(w, "  %s %s\n", b.Author, b.Url)
+				}
+			case model.BatchNotFound:
+				fmt.Fprintf(w, "第 %d 行 %s: 未找到\n", r.Item.Line, r.Item.Name)
+			default:
+				fmt.Fprintf(w, "第 %d 行 %s: %s\n", r.Item.Line, r.Item.Name, r.Error)
+			}
+		}
+		if report.ReportPath != "" {
+			fmt.Fprintf(w, "报告: %s\n", report.ReportPath)
+		}
+	})
+	return nil
+}
+
+func runUpdate(c *cli, fs *flag.FlagSet) error {
+	updates, err := functions.NewSubscriber(c.log).Check(0)
+	if err != nil {
+		return err
+	}
+	if updates == nil {
+		updates = []model.SubscriptionUpdate{}
+	}
+	c.output(updates, func(w io.Writer) {
+		if len(updates) == 0 {
+			fmt.Fprintln(w, "没有新章节")
+			return
+		}
+		for _, u := range updates {
+			fmt.Fprintf(w, "%s: %d 个新章节, 最新 %s\n", u.Subscription.Book.BookName, len(u.NewTitles), u.Subscription.LatestChapter)
+			if u.OutputPath != "" {
+				fmt.Fprintf(w, "  已下载: %s\n", u.OutputPath)
+			}
+		}
+	})
+	return nil
+}
+
+func runConfig(c *cli, fs *flag.FlagSet) error {
+	handler := functions.NewGetConf(c.log)
+	switch fs.Arg(0) {
+	case "get":
+		if fs.NArg() > 2 {
+			return usagef("config get takes at most one key")
+		}
+		value, err := configValue(handler.GetConfig(), fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		c.printValue(value)
+		return nil
+	case "set":
+		if fs.NArg() != 3 {
+			return usagef("config set needs a key and a value")
+		}
+		key, raw := fs.Arg(1), fs.Arg(2)
+		if _, err := configValue(handler.GetConfig(), key); err != nil {
+			return err
+		}
+		section, name, _ := strings.Cut(key, ".")
+		// 数字、布尔值与数组按 JSON 解析, 其余按字符串处理
+		value := json.RawMessage(raw)
+		if !json.Valid(value) {
+			value, _ = json.Marshal(raw)
+		}
+		patch, err := json.Marshal(map[string]map[string]json.RawMessage{section: {name: value}})
+		if err != nil {
+			return err
+		}
+		if err := handler.SetConfig(string(patch)); err != nil {
+			return err
+		}
+		saved, err := configValue(handler.GetConfig(), key)
+		if err != nil {
+			return err
+		}
+		c.printValue(saved)
+		return nil
+	}
+	return usagef("expected get or set")
+}
+
+// configValue 按 "分组.键" 取配置值, key 为空时返回全部配置
+func configValue(conf config.Info, key string) (any, error) {
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return all, nil
+	}
+	section, name, ok := strings.Cut(key, ".")
+	group, found := all[section].(map[string]any)
+	if !ok || !found {
+		return nil, fmt.Errorf("unknown config key %q, use section.key such as base.extname", key)
+	}
+	value, found := group[name]
+	if !found {
+		return nil, fmt.Errorf("unknown config key %q", key)
+	}
+	return value, nil
+}
+
+// printValue 字符串与数字直接输出, 其余以 JSON 输出
+func (c *cli) printValue(v any) {
+	switch v.(type) {
+	case string, float64, bool:
+		c.output(v, func(w io.Writer) { fmt.Fprintln(w, v) })
+	default:
+		c.output(v, nil)
+	}
+}
+
+func runCache(c *cli, fs *flag.FlagSet) error {
+	handler := functions.NewCacheHandler(c.log)
+	switch fs.Arg(0) {
+	case "", "stats":
+		stats := handler.Stats()
+		c.output(stats, func(w io.Writer) {
+			fmt.Fprintf(w, "%d 个页面, %.1f MB / %.0f MB\n", stats.Entries, float64(stats.Size)/(1<<20), float64(stats.MaxSize)/(1<<20))
+		})
+		return nil
+	case "clear":
+		if err := handler.Clear(); err != nil {
+			return err
+		}
+		c.output(map[string]bool{"cleared": true}, func(w io.Writer) { fmt.Fprintln(w, "已清空抓取缓存") })
+		return nil
+	}
+	return usagef("expected stats or clear")
+}
+
+type sourceInfo struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Comment string `json:"comment"`
+	Current bool   `json:"current"`
+}
+
+func runSources(c *cli, fs *flag.FlagSet) error {
+	current := config.GetConf().Base.SourceID
+	var sources []sourceInfo
+	for _, id := range source.IDs() {
+		rule := source.GetRuleBySourceID(id)
+		sources = append(sources, sourceInfo{
+			ID:      id,
+			Name:    rule.Name,
+			URL:     rule.URL,
+			Comment: rule.Comment,
+			Current: id == current,
+		})
+	}
+	c.output(sources, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\t名称\t地址\t说明")
+		for _, s := range sources {
+			mark := ""
+			if s.Current {
+				mark = " *"
+			}
+			fmt.Fprintf(tw, "%d%s\t%s\t%s\t%s\n", s.ID, mark, s.Name, s.URL, s.Comment)
+		}
+		tw.Flush()
+	})
+	return nil
+}
+
+func runCheckUpdate(c *cli, fs *flag.FlagSet) error {
+	res := functions.NewCheckUpdate(c.log, 5000).CheckUpdate()
+	if res.ErrorMsg != "" {
+		return fmt.Errorf("%s", res.ErrorMsg)
+	}
+	c.output(res, func(w io.Writer) {
+		if res.NeedUpdate {
+			fmt.Fprintf(w, "有新版本 %s (当前 %s): %s\n", res.LatestVersion, res.CurrentVersion, res.LatestUrl)
+		} else {
+			fmt.Fprintf(w, "已是最新版本 %s\n", res.CurrentVersion)
+		}
+	})
+	return nil
+}
+
+func runVersion(c *cli, fs *flag.FlagSet) error {
+	info := map[string]string{
+		"version": version.Version,
+		"commit":  version.Commit,
+		"date":    version.Date,
+	}
+	c.output(info, func(w io.Writer) {
+		fmt.Fprintf(w, "fy-novel %s (commit %s, built at %s)\n", version.Version, version.Commit, version.Date)
+	})
+	return nil
+}
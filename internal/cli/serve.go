@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"fy-novel/internal/server"
+)
+
+// 退出时等待请求结束的时间
+const shutdownTimeout = 5 * time.Second
+
+func runServe(c *cli, fs *flag.FlagSet) error {
+	if fs.NArg() != 0 {
+		return usagef("serve takes no arguments")
+	}
+	listener, err := net.Listen("tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	if c.token == "" && !isLoopback(listener.Addr()) {
+		c.log.Warnf("serving on %s without a token, anyone on the network can use the API", listener.Addr())
+	}
+
+	webDir := c.webDir
+	if webDir != "" {
+		if _, err := os.Stat(filepath.Join(webDir, "index.html")); err != nil {
+			fmt.Fprintf(c.stderr, "web UI disabled: %s/index.html not found, build the frontend or set -web\n", webDir)
+			webDir = ""
+		}
+	}
+	s := server.New(c.log, server.Options{Token: c.token, WebDir: webDir, Addr: serveAddr(c.addr, listener.Addr())})
+	defer s.Close()
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(listener) }()
+	fmt.Fprintf(c.stderr, "fy-novel API listening on http://%s (Ctrl+C to stop)\n", listener.Addr())
+	if webDir != "" {
+		fmt.Fprintf(c.stderr, "web UI: http://%s/\n", listener.Addr())
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	// 先取消下载任务, 再等待正在处理的请求
+	s.Close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return nil
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// serveAddr 保留 -addr 中的主机名 (可能是域名), 端口取实际监听的端口 (-addr 可为 :0)
+func serveAddr(addr string, listening net.Addr) string {
+	host, _, _ := net.SplitHostPort(addr)
+	_, port, _ := net.SplitHostPort(listening.String())
+	return net.JoinHostPort(host, port)
+}
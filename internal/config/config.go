@@ -7,6 +7,7 @@ import (
 	concurrencyTool "fy-novel/internal/tools/concurrency"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,9 +24,12 @@ var (
 )
 
 // User-defined configuration paths
-const customConfigPath = "$HOME/.fynovel/config.json"
+const (
+	dataDir          = "$HOME/.fynovel"
+	customConfigPath = dataDir + "/config.json"
+)
 
-// { "base": { "source-id": 3, "download-path": "downloads", "extname": "epub", "log-level": "error" }, "crawl": { "threads": -1 }, "retry": { "max-attempts": 3 } }
+// { "base": { "source-id": 3, "download-path": "downloads", "extname": "epub", "layout": "single", "log-level": "error" }, "crawl": { "threads": -1 }, "retry": { "max-attempts": 3 } }
 
 // Config stores all configuration of the application.
 type Info struct {
@@ -33,6 +37,7 @@ type Info struct {
 		SourceID     int    `mapstructure:"source-id" json:"source-id"`
 		DownloadPath string `mapstructure:"download-path" json:"download-path"`
 		Extname      string `mapstructure:"extname" json:"extname"`
+		Layout       string `mapstructure:"layout" json:"layout"`
 		LogLevel     string `mapstructure:"log-level" json:"log-level"`
 	} `mapstructure:"base"    json:"base"`
 	Crawl struct {
@@ -44,6 +49,59 @@ type Info struct {
 	Chatbot struct {
 		Model string `mapstructure:"model" json:"model"`
 	} `mapstructure:"chatbot" json:"chatbot"`
+	Epub struct {
+		CssPath  string `mapstructure:"css-path" json:"css-path"`
+		FontPath string `mapstructure:"font-path" json:"font-path"`
+		Vertical bool   `mapstructure:"vertical" json:"vertical"`
+	} `mapstructure:"epub"    json:"epub"`
+	Pdf struct {
+		PageSize string  `mapstructure:"page-size" json:"page-size"`
+		Margin   float64 `mapstructure:"margin" json:"margin"`
+		FontSize float64 `mapstructure:"font-size" json:"font-size"`
+		FontPath string  `mapstructure:"font-path" json:"font-path"`
+	} `mapstructure:"pdf"     json:"pdf"`
+	Filter struct {
+		Stages       []string      `mapstructure:"stages" json:"stages"`
+		Replacements []Replacement `mapstructure:"replacements" json:"replacements"`
+		// 保留重复段落, 默认去除章内重复及与上一章重叠的段落
+		KeepDuplicates bool `mapstructure:"keep-duplicates" json:"keep-duplicates"`
+	} `mapstructure:"filter"  json:"filter"`
+	Quality struct {
+		Threshold       int   `mapstructure:"threshold" json:"threshold"`
+		AutoRefetch     bool  `mapstructure:"auto-refetch" json:"auto-refetch"`
+		FallbackSources []int `mapstructure:"fallback-sources" json:"fallback-sources"`
+	} `mapstructure:"quality" json:"quality"`
+	Title struct {
+		StripPatterns []string `mapstructure:"strip-patterns" json:"strip-patterns"`
+		Normalize     bool     `mapstructure:"normalize" json:"normalize"`
+		Renumber      bool     `mapstructure:"renumber" json:"renumber"`
+	} `mapstructure:"title"   json:"title"`
+	Subscribe struct {
+		// 检查间隔 (分钟), 0 使用默认值, 负数关闭定时检查
+		CheckInterval int `mapstructure:"check-interval" json:"check-interval"`
+	} `mapstructure:"subscribe" json:"subscribe"`
+	Reader struct {
+		// 在线阅读时预先获取的后续章节数
+		Prefetch int `mapstructure:"prefetch" json:"prefetch"`
+		// 在线阅读缓存上限 (MB), 0 使用默认值
+		CacheSize int `mapstructure:"cache-size" json:"cache-size"`
+	} `mapstructure:"reader" json:"reader"`
+	Cache struct {
+		// 不读取缓存, 总是从书源获取 (获取到的页面仍写入缓存)
+		Bypass bool `mapstructure:"bypass" json:"bypass"`
+		// 缓存上限 (MB), 0 使用默认值
+		Size int `mapstructure:"size" json:"size"`
+		// 各类页面的缓存时间 (分钟), 0 不缓存, 负数永不过期
+		SearchTTL  int `mapstructure:"search-ttl" json:"search-ttl"`
+		CatalogTTL int `mapstructure:"catalog-ttl" json:"catalog-ttl"`
+		ChapterTTL int `mapstructure:"chapter-ttl" json:"chapter-ttl"`
+	} `mapstructure:"cache" json:"cache"`
+}
+
+// Replacement 用户自定义的正则替换, Replace 支持 $1 等分组引用
+type Replacement struct {
+	Pattern string `mapstructure:"pattern" json:"pattern"`
+	Replace string `mapstructure:"replace" json:"replace"`
 }
 
 func init() {
@@ -51,7 +109,7 @@ func init() {
 		confValue.Store(Info{})
 		if err := loadConfig(); err != nil {
 			// In the event of an initialization failure, we should log the error or take appropriate action
-			fmt.Printf("Failed to load config: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 		}
 	})
 }
@@ -65,20 +123,36 @@ func (i Info) ToJSON() (string, error) {
 	return string(jsonBytes), nil
 }
 
-func (i Info) GetRandomDelay() time.Duration {
+// GetConcurrencyNum returns the configured thread count, source.ConcurrencyNum also applies the rule limit
+func (i Info) GetConcurrencyNum() int {
+	return concurrencyTool.GetConcurrencyNumBySourceID(i.Crawl.Threads)
+}
+
+// GetCheckInterval returns how often subscribed books are checked for new chapters, 0 disables the scheduler
+func (i Info) GetCheckInterval() time.Duration {
+	switch {
+	case i.Subscribe.CheckInterval < 0:
+		return 0
+	case i.Subscribe.CheckInterval == 0:
+		return time.Hour
+	}
+	return time.Duration(i.Subscribe.CheckInterval) * time.Minute
+}
 
-	if isSlowSource(i.Base.SourceID) {
-		return 2 * time.Second
+// GetReaderCacheBytes returns the size limit of the read-online chapter cache
+func (i Info) GetReaderCacheBytes() int64 {
+	if i.Reader.CacheSize <= 0 {
+		return 100 << 20
 	}
-	return 250 * time.Millisecond
+	return int64(i.Reader.CacheSize) << 20
 }
 
-func (i Info) GetConcurrencyNum() int {
-	threads := concurrencyTool.GetConcurrencyNumBySourceID(i.Crawl.Threads)
-	if isSlowSource(i.Base.SourceID) {
-		return 1
+// GetHTTPCacheBytes returns the size limit of the crawl response cache
+func (i Info) GetHTTPCacheBytes() int64 {
+	if i.Cache.Size <= 0 {
+		return 200 << 20
 	}
-	return threads
+	return int64(i.Cache.Size) << 20
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -121,10 +195,20 @@ func loadConfig() error {
 	return nil
 }
 
+// DataDir returns the directory holding user data (config, dictionaries, journals)
+func DataDir() string {
+	return os.ExpandEnv(dataDir)
+}
+
 func GetConf() Info {
 	return confValue.Load().(Info)
 }
 
+// Override replaces the in-memory configuration without saving it, e.g. for command-line flags
+func Override(conf Info) {
+	confValue.Store(conf)
+}
+
 func SetConf(conf string) error {
 	var newConf Info
 	currentConf := GetConf()
@@ -133,6 +217,9 @@ func SetConf(conf string) error {
 	if err := json.Unmarshal([]byte(conf), &newConf); err != nil {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	// Boolean fields cannot be told apart from their zero value, so check presence
+	var present map[string]map[string]json.RawMessage
+	_ = json.Unmarshal([]byte(conf), &present)
 
 	// Compare and update configuration
 	updated := false
@@ -151,6 +238,10 @@ func SetConf(conf string) error {
 		currentConf.Base.Extname = newConf.Base.Extname
 		updated = true
 	}
+	if newConf.Base.Layout != "" && newConf.Base.Layout != currentConf.Base.Layout {
+		currentConf.Base.Layout = newConf.Base.Layout
+		updated = true
+	}
 	if newConf.Base.LogLevel != "" && newConf.Base.LogLevel != currentConf.Base.LogLevel {
 		currentConf.Base.LogLevel = newConf.Base.LogLevel
 		updated = true
@@ -175,6 +266,129 @@ func SetConf(conf string) error {
 		updated = true
 	}
 
+	// Update Epub fields
+	if newConf.Epub.CssPath != "" && newConf.Epub.CssPath != currentConf.Epub.CssPath {
+		currentConf.Epub.CssPath = newConf.Epub.CssPath
+		updated = true
+	}
+	if newConf.Epub.FontPath != "" && newConf.Epub.FontPath != currentConf.Epub.FontPath {
+		currentConf.Epub.FontPath = newConf.Epub.FontPath
+		updated = true
+	}
+	if _, ok := present["epub"]["vertical"]; ok &&
+		newConf.Epub.Vertical != currentConf.Epub.Vertical {
+		currentConf.Epub.Vertical = newConf.Epub.Vertical
+		updated = true
+	}
+
+	// Update Pdf fields
+	if newConf.Pdf.PageSize != "" && newConf.Pdf.PageSize != currentConf.Pdf.PageSize {
+		currentConf.Pdf.PageSize = newConf.Pdf.PageSize
+		updated = true
+	}
+	if newConf.Pdf.Margin != 0 && newConf.Pdf.Margin != currentConf.Pdf.Margin {
+		currentConf.Pdf.Margin = newConf.Pdf.Margin
+		updated = true
+	}
+	if newConf.Pdf.FontSize != 0 && newConf.Pdf.FontSize != currentConf.Pdf.FontSize {
+		currentConf.Pdf.FontSize = newConf.Pdf.FontSize
+		updated = true
+	}
+	if newConf.Pdf.FontPath != "" && newConf.Pdf.FontPath != currentConf.Pdf.FontPath {
+		currentConf.Pdf.FontPath = newConf.Pdf.FontPath
+		updated = true
+	}
+
+	// Update Filter fields, an explicit empty list restores the defaults
+	if _, ok := present["filter"]["stages"]; ok &&
+		!slices.Equal(newConf.Filter.Stages, currentConf.Filter.Stages) {
+		currentConf.Filter.Stages = newConf.Filter.Stages
+		updated = true
+	}
+	if _, ok := present["filter"]["replacements"]; ok &&
+		!slices.Equal(newConf.Filter.Replacements, currentConf.Filter.Replacements) {
+		currentConf.Filter.Replacements = newConf.Filter.Replacements
+		updated = true
+	}
+	if _, ok := present["filter"]["keep-duplicates"]; ok &&
+		newConf.Filter.KeepDuplicates != currentConf.Filter.KeepDuplicates {
+		currentConf.Filter.KeepDuplicates = newConf.Filter.KeepDuplicates
+		updated = true
+	}
+
+	// Update Quality fields
+	if newConf.Quality.Threshold != 0 && newConf.Quality.Threshold != currentConf.Quality.Threshold {
+		currentConf.Quality.Threshold = newConf.Quality.Threshold
+		updated = true
+	}
+	if _, ok := present["quality"]["auto-refetch"]; ok &&
+		newConf.Quality.AutoRefetch != currentConf.Quality.AutoRefetch {
+		currentConf.Quality.AutoRefetch = newConf.Quality.AutoRefetch
+		updated = true
+	}
+	if _, ok := present["quality"]["fallback-sources"]; ok &&
+		!slices.Equal(newConf.Quality.FallbackSources, currentConf.Quality.FallbackSources) {
+		currentConf.Quality.FallbackSources = newConf.Quality.FallbackSources
+		updated = true
+	}
+
+	// Update Title fields
+	if _, ok := present["title"]["strip-patterns"]; ok &&
+		!slices.Equal(newConf.Title.StripPatterns, currentConf.Title.StripPatterns) {
+		currentConf.Title.StripPatterns = newConf.Title.StripPatterns
+		updated = true
+	}
+	if _, ok := present["title"]["normalize"]; ok &&
+		newConf.Title.Normalize != currentConf.Title.Normalize {
+		currentConf.Title.Normalize = newConf.Title.Normalize
+		updated = true
+	}
+	if _, ok := present["title"]["renumber"]; ok &&
+		newConf.Title.Renumber != currentConf.Title.Renumber {
+		currentConf.Title.Renumber = newConf.Title.Renumber
+		updated = true
+	}
+
+	// Update Subscribe fields
+	if _, ok := present["subscribe"]["check-interval"]; ok &&
+		newConf.Subscribe.CheckInterval != currentConf.Subscribe.CheckInterval {
+		currentConf.Subscribe.CheckInterval = newConf.Subscribe.CheckInterval
+		updated = true
+	}
+
+	// Update Reader fields
+	if _, ok := present["reader"]["prefetch"]; ok &&
+		newConf.Reader.Prefetch != currentConf.Reader.Prefetch {
+		currentConf.Reader.Prefetch = max(newConf.Reader.Prefetch, 0)
+		updated = true
+	}
+	if newConf.Reader.CacheSize != 0 && newConf.Reader.CacheSize != currentConf.Reader.CacheSize {
+		currentConf.Reader.CacheSize = newConf.Reader.CacheSize
+		updated = true
+	}
+
+	// Update Cache fields, a TTL of 0 turns caching off so presence is checked
+	if _, ok := present["cache"]["bypass"]; ok && newConf.Cache.Bypass != currentConf.Cache.Bypass {
+		currentConf.Cache.Bypass = newConf.Cache.Bypass
+		updated = true
+	}
+	if newConf.Cache.Size != 0 && newConf.Cache.Size != currentConf.Cache.Size {
+		currentConf.Cache.Size = newConf.Cache.Size
+		updated = true
+	}
+	if _, ok := present["cache"]["search-ttl"]; ok && newConf.Cache.SearchTTL != currentConf.Cache.SearchTTL {
+		currentConf.Cache.SearchTTL = newConf.Cache.SearchTTL
+		updated = true
+	}
+	if _, ok := present["cache"]["catalog-ttl"]; ok && newConf.Cache.CatalogTTL != currentConf.Cache.CatalogTTL {
+		currentConf.Cache.CatalogTTL = newConf.Cache.CatalogTTL
+		updated = true
+	}
+	if _, ok := present["cache"]["chapter-ttl"]; ok && newConf.Cache.ChapterTTL != currentConf.Cache.ChapterTTL {
+		currentConf.Cache.ChapterTTL = newConf.Cache.ChapterTTL
+		updated = true
+	}
+
 	// If no updates, return early
 	if !updated {
 		return nil
@@ -205,11 +419,3 @@ func SetConf(conf string) error {
 
 	return nil
 }
-
-func isSlowSource(sourceId int) bool {
-	var slowSourceMap = map[int]bool{
-		// Slow source IDs
-		3: true,
-	}
-	return slowSourceMap[sourceId]
-}
@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"fy-novel/internal/parse"
+	batchTool "fy-novel/internal/tools/batch"
+)
+
+// Batch downloads the books of a reading list. parallel books are downloaded at a time and share
+// the configured thread budget, so a batch is no harder on the sources than a single download.
+// progress is called after each book, one call at a time.
+func (nc *novelCrawler) Batch(items []model.BatchItem, parallel int, progress func(model.BatchProgress)) *model.BatchReport {
+	startTime := time.Now()
+	base := config.GetConf()
+	parallel = max(1, min(parallel, len(items)))
+	threads := max(1, base.GetConcurrencyNum()/parallel)
+
+	results := make([]model.BatchItemResult, len(items))
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	semaphore := make(chan struct{}, parallel)
+	for i, item := range items {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(i int, item model.BatchItem) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			conf := base
+			if item.SourceID != 0 {
+				conf.Base.SourceID = item.SourceID
+			}
+			conf.Crawl.Threads = threads
+			results[i] = nc.batchItem(item, conf)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if progress != nil {
+				progress(model.BatchProgress{Done: done, Total: len(items), Result: results[i]})
+			}
+		}(i, item)
+	}
+	wg.Wait()
+
+	report := &model.BatchReport{Items: results}
+	for _, r := range results {
+		switch r.Status {
+		case model.BatchOK:
+			report.Succeeded++
+		case model.BatchAmbiguous:
+			report.Ambiguous++
+		case model.BatchNotFound:
+			report.NotFound++
+		default:
+			report.Failed++
+		}
+	}
+	report.TakeTime = int64(time.Since(startTime).Seconds())
+	return report
+}
+
+// batchItem searches the book by name, picks the result matching name and author, and downloads it
+func (nc *novelCrawler) batchItem(item model.BatchItem, conf config.Info) model.BatchItemResult {
+	startTime := time.Now()
+	result := model.BatchItemResult{Item: item}
+	defer func() {
+		result.TakeTime = int64(time.Since(startTime).Seconds())
+	}()
+
+	found, err := parse.NewSearchResultParser(conf).Parse(item.Name)
+	if err != nil {
+		result.Status = model.BatchFailed
+		result.Error = fmt.Sprintf("search error: %v", err)
+		return result
+	}
+	result.Book, result.Candidates, result.Status = batchTool.Resolve(item, found)
+	if result.Book == nil {
+		return result
+	}
+
+	start, end := item.Start, item.End
+	if start == 0 {
+		start = 1
+	}
+	if end == 0 {
+		end = math.MaxInt
+	}
+	res, err := nc.crawl(context.Background(), result.Book, conf, start, end)
+	switch {
+	case err != nil:
+		result.Status = model.BatchFailed
+		result.Error = err.Error()
+	case res == nil:
+		result.Status = model.BatchFailed
+		result.Error = "no chapters in range"
+	default:
+		result.OutputPath = res.OutputPath
+		result.Suspects = len(res.Suspects)
+	}
+	if err != nil {
+		nc.log.Errorf("batch %s error: %v", item.Name, err)
+	}
+	return result
+}
@@ -1,17 +1,25 @@
 package crawler
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 	"fy-novel/internal/parse"
-	chapterTool "fy-novel/internal/tools/chapter"
+	"fy-novel/internal/source"
+	dictTool "fy-novel/internal/tools/dict"
+	fulltextTool "fy-novel/internal/tools/fulltext"
+	journalTool "fy-novel/internal/tools/journal"
+	libraryTool "fy-novel/internal/tools/library"
 	mergeTool "fy-novel/internal/tools/merge"
 	progressTool "fy-novel/internal/tools/progress"
+	qualityTool "fy-novel/internal/tools/quality"
+	titleTool "fy-novel/internal/tools/title"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,6 +28,12 @@ import (
 type Crawler interface {
 	Search(key string) ([]*model.SearchResult, error)
 	Crawl(res *model.SearchResult, start, end int) (*model.CrawlResult, error)
+	CrawlContext(ctx context.Context, res *model.SearchResult, conf config.Info, start, end int) (*model.CrawlResult, error)
+	Details(res *model.SearchResult) (*model.BookDetails, error)
+	Refetch(bookURL string, seqs []int, sourceID int) (*model.CrawlResult, error)
+	CheckCatalog(res *model.SearchResult) (*model.CatalogReport, error)
+	CheckUpdate(sub model.Subscription) (*model.SubscriptionUpdate, error)
+	Batch(items []model.BatchItem, parallel int, progress func(model.BatchProgress)) *model.BatchReport
 }
 
 type novelCrawler struct {
@@ -42,21 +56,23 @@ func (nc *novelCrawler) Search(key string) ([]*model.SearchResult, error) {
 }
 
 func (nc *novelCrawler) Crawl(res *model.SearchResult, start, end int) (*model.CrawlResult, error) {
-	conf := config.GetConf()
+	return nc.crawl(context.Background(), res, config.GetConf(), start, end)
+}
+
+// CrawlContext downloads the book with the given config and stops fetching chapters once ctx is done,
+// a canceled download returns ctx.Err() and leaves no output file behind
+func (nc *novelCrawler) CrawlContext(ctx context.Context, res *model.SearchResult, conf config.Info, start, end int) (*model.CrawlResult, error) {
+	return nc.crawl(ctx, res, conf, start, end)
+}
+
+// crawl downloads the book with the given config, subscriptions use it to crawl from their own source
+func (nc *novelCrawler) crawl(ctx context.Context, res *model.SearchResult, conf config.Info, start, end int) (*model.CrawlResult, error) {
 	// Fetch and parse the novel details page
-	book, err := parse.NewBookParser(config.GetConf()).Parse(res.Url)
+	book, err := parse.NewBookParser(conf).Parse(res.Url)
 	if err != nil {
 		return nil, err
 	}
 
-	// Format the directory name as "BookName (Author)"
-	bookDir := fmt.Sprintf("%s (%s)", book.BookName, book.Author)
-	dirPath := filepath.Join(conf.Base.DownloadPath, bookDir)
-	// Create the directory
-	err = os.MkdirAll(dirPath, os.ModePerm)
-	if err != nil {
-		return nil, err
-	}
 	// Get the novel's table of contents
 	catalogsParser := parse.NewCatalogsParser(conf)
 	catalogs, err := catalogsParser.Parse(res.Url, start, end)
@@ -66,52 +82,209 @@ func (nc *novelCrawler) Crawl(res *model.SearchResult, start, end int) (*model.C
 	if len(catalogs) == 0 {
 		return nil, nil
 	}
+	titles := chapterTitles(catalogs)
+	catalogReport := nc.checkTitles(titles)
+	normalizer, err := titleTool.NewNormalizer(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail early on a broken replacement dictionary
+	scope := dictTool.Scope{Book: book.BookName, SourceID: conf.Base.SourceID}
+	if _, err := dictTool.ForScope(scope); err != nil {
+		return nil, err
+	}
+
+	// Chapters are written in catalog order as they arrive
+	writer, err := mergeTool.NewWriter(book, conf.Base.DownloadPath, conf)
+	if err != nil {
+		return nil, err
+	}
+	concurrencyNum := source.ConcurrencyNum(conf)
+	ordered := mergeTool.NewOrderedWriter(writer, concurrencyNum*4)
+	processor := newChapterProcessor(nc.log, conf, scope)
+	// ChapterNo is the 1-based position in the whole catalog
+	processor.offset = catalogs[0].ChapterNo - 1
+	processor.catalogs = catalogs
+	if normalizer.Active() {
+		processor.titles = normalizer.Catalog(titles)
+	}
+	// Keep the cleaned chapters so they can be re-processed without fetching again
+	journal, err := journalTool.Create(book, conf.Base.SourceID)
+	if err != nil {
+		nc.log.Errorf("crawl journal disabled: %v", err)
+	} else {
+		processor.journal = journal
+	}
+	// Chapters that depend on their neighbours are processed in catalog order
+	ordered.SetHook(processor.process)
 
 	startTime := time.Now()
 	// Parse and download content
 	// Limit concurrent processing
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, conf.GetConcurrencyNum())
-	var nowCatalogsCount = int64(0)
+	semaphore := make(chan struct{}, concurrencyNum)
 	// Total completed tasks = number of chapters fetched + 1 (merging task)
 	progressTool.InitTask(res.Url, int64(len(catalogs)+1))
-	for _, chapter := range catalogs {
+	for i, chapter := range catalogs {
+		// Chapters already fetching are finished, the rest are dropped
+		if ctx.Err() != nil {
+			break
+		}
+		// Wait until the chapter fits in the reorder window
+		ordered.Reserve(i)
+		semaphore <- struct{}{}
 		wg.Add(1)
-		go func(chapter *model.Chapter, bookDir string) {
+		go func(seq int, chapter *model.Chapter) {
 			defer wg.Done()
-			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
-			defer func() {
-				atomic.AddInt64(&nowCatalogsCount, 1)
-				progressTool.UpdateProgress(res.Url, nowCatalogsCount)
-			}()
+			// UpdateProgress adds to the completed count
+			defer progressTool.UpdateProgress(res.Url, 1)
 			// Download logic
-			err := parse.NewChapterParser(conf).Parse(chapter, res, book, bookDir)
+			err := parse.NewChapterParser(conf).Parse(chapter, res, book)
 			if err != nil {
-				fmt.Printf("parse.NewChapterParser(conf).Parse error: %v", err)
+				nc.log.Errorf("parse chapter %s error: %v", chapter.Title, err)
+				// Skip the failed chapter so later chapters are not held back
+				ordered.Put(seq, nil)
 				return
 			}
-			chapterTool.CreateFileForChapter(chapter, bookDir)
-		}(
-			chapter,
-			bookDir,
-		)
+			// Hand the content over to the writer, the catalog only keeps metadata
+			content := *chapter
+			chapter.Content = ""
+			if err := ordered.Put(seq, &content); err != nil {
+				nc.log.Errorf("write chapter %s error: %v", chapter.Title, err)
+			}
+		}(i, chapter)
 	}
 	wg.Wait()
 
-	// Merge and generate the novel file format
-	outputPath, err := mergeTool.MergeSaveHandler(book, dirPath)
+	// Finish the novel file
+	outputPath, err := ordered.Close()
+	if journal != nil {
+		if err := journal.Close(); err != nil {
+			nc.log.Errorf("crawl journal close error: %v", err)
+		} else if err := journalTool.Compact(book.URL, book); err != nil {
+			// Reused journals keep growing with every crawl, drop the superseded entries
+			nc.log.Errorf("crawl journal compact error: %v", err)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	// The journal keeps the fetched chapters, only the partial export is removed
+	if ctx.Err() != nil {
+		// Multi-file html/md exports point at their index page
+		partial := outputPath
+		if base := filepath.Base(partial); base == "index.html" || base == "index.md" {
+			partial = filepath.Dir(partial)
+		}
+		if err := os.RemoveAll(partial); err != nil {
+			nc.log.Errorf("remove canceled output error: %v", err)
+		}
+		return nil, ctx.Err()
+	}
 	// Task completed
-	defer func() {
-		atomic.AddInt64(&nowCatalogsCount, 1)
-		progressTool.UpdateProgress(res.Url, int64(nowCatalogsCount))
-	}()
+	defer progressTool.UpdateProgress(res.Url, 1)
 
-	return &model.CrawlResult{
+	result := &model.CrawlResult{
 		OutputPath: outputPath,
-		TakeTime:   int64(time.Since(startTime).Seconds()),
-	}, nil
+		Dedup:      processor.reports,
+		Suspects:   processor.quality.Suspects(conf.Quality.Threshold),
+	}
+	// Chapters that failed to download stay out of the snapshot, subscriptions fetch them again
+	nc.record(book, conf, withoutURLs(catalogs, fetchFailedURLs(result.Suspects)), outputPath, processor.written)
+	// Suspect chapters are fetched again from the journal, so it must exist
+	if conf.Quality.AutoRefetch && journal != nil && len(result.Suspects) > 0 {
+		span := seqSpan{first: processor.offset, last: processor.offset + len(catalogs) - 1}
+		result = nc.autoRefetch(book.URL, span, result, conf)
+	}
+	result.TakeTime = int64(time.Since(startTime).Seconds())
+	result.Catalog = catalogReport
+	return result, nil
+}
+
+// CheckCatalog parses the catalog and reports missing or duplicated chapter numbers before downloading
+func (nc *novelCrawler) CheckCatalog(res *model.SearchResult) (*model.CatalogReport, error) {
+	catalogs, err := parse.NewCatalogsParser(config.GetConf()).Parse(res.Url, 1, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	report := nc.checkTitles(chapterTitles(catalogs))
+	return &report, nil
+}
+
+// Details parses the book page and its catalog without downloading any chapter
+func (nc *novelCrawler) Details(res *model.SearchResult) (*model.BookDetails, error) {
+	conf := config.GetConf()
+	book, err := parse.NewBookParser(conf).Parse(res.Url)
+	if err != nil {
+		return nil, err
+	}
+	catalogs, err := parse.NewCatalogsParser(conf).Parse(res.Url, 1, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	details := &model.BookDetails{
+		Book:     *book,
+		SourceID: conf.Base.SourceID,
+		Chapters: make([]model.CatalogItem, len(catalogs)),
+		Catalog:  nc.checkTitles(chapterTitles(catalogs)),
+	}
+	for i, c := range catalogs {
+		details.Chapters[i] = model.CatalogItem{ChapterNo: c.ChapterNo, Title: c.Title, URL: c.URL}
+	}
+	return details, nil
+}
+
+func (nc *novelCrawler) checkTitles(titles []string) model.CatalogReport {
+	report := titleTool.Check(titles)
+	for _, gap := range report.Gaps {
+		nc.log.Warnf("catalog gap: %s missing chapters %d-%d", gap.Volume, gap.From, gap.To)
+	}
+	for _, dup := range report.Duplicates {
+		nc.log.Warnf("catalog duplicate: %s chapter %d %v", dup.Volume, dup.Number, dup.Titles)
+	}
+	return report
+}
+
+// record adds the exported file to the local library and re-indexes the journaled text for
+// full-text search, failures only affect the library and the index
+func (nc *novelCrawler) record(book *model.Book, conf config.Info, catalogs []*model.Chapter, outputPath string, written int) {
+	output := model.LibraryOutput{Format: conf.Base.Extname, Path: outputPath}
+	if _, err := libraryTool.Record(book, conf.Base.SourceID, catalogs, output, written); err != nil {
+		nc.log.Errorf("library record error: %v", err)
+	}
+	if err := fulltextTool.IndexBook(book.URL); err != nil && !errors.Is(err, os.ErrNotExist) {
+		nc.log.Errorf("fulltext index error: %v", err)
+	}
+}
+
+// fetchFailedURLs returns the urls of the suspect chapters that could not be downloaded
+func fetchFailedURLs(suspects []model.SuspectChapter) map[string]bool {
+	urls := make(map[string]bool)
+	for _, s := range suspects {
+		if slices.Contains(s.Reasons, qualityTool.ReasonFetchFailed) {
+			urls[s.URL] = true
+		}
+	}
+	return urls
+}
+
+// withoutURLs returns the catalog chapters whose url is not in urls, never nil
+func withoutURLs(catalogs []*model.Chapter, urls map[string]bool) []*model.Chapter {
+	res := make([]*model.Chapter, 0, len(catalogs))
+	for _, c := range catalogs {
+		if !urls[c.URL] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func chapterTitles(catalogs []*model.Chapter) []string {
+	titles := make([]string, len(catalogs))
+	for i, c := range catalogs {
+		titles[i] = c.Title
+	}
+	return titles
 }
@@ -0,0 +1,268 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"fy-novel/internal/parse"
+	dictTool "fy-novel/internal/tools/dict"
+	diskcacheTool "fy-novel/internal/tools/diskcache"
+	libraryTool "fy-novel/internal/tools/library"
+	readerTool "fy-novel/internal/tools/reader"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Open books whose catalogs are kept in memory
+const maxOnlineBooks = 8
+
+// OnlineReader reads chapters straight from the source without downloading the book.
+// Cleaned chapters are kept in an LRU disk cache and the next chapters are prefetched
+// in the background, so turning the page does not wait for the network.
+type OnlineReader struct {
+	log   *logrus.Logger
+	cache *diskcacheTool.Cache
+	// fetch downloads and cleans a chapter, replaced in tests
+	fetch func(conf config.Info, chapter *model.Chapter, res *model.SearchResult, book *model.Book) error
+
+	mu    sync.Mutex
+	books map[string]*onlineBook
+	// Chapters being fetched, by cache key, closed when done
+	inflight map[string]chan struct{}
+	// Running prefetches, tests wait for them
+	prefetching sync.WaitGroup
+}
+
+type onlineBook struct {
+	res     *model.SearchResult
+	book    *model.Book
+	conf    config.Info
+	catalog []*model.Chapter
+	opened  time.Time
+	// Bumped by every read, an older prefetch stops when the reader has moved on
+	generation atomic.Int64
+}
+
+// cachedChapter is the cleaned chapter before the replacement dictionary, so dictionary
+// edits apply to cached chapters too
+type cachedChapter struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// OnlineCacheDir holds the read-online chapter cache
+func OnlineCacheDir() string {
+	return filepath.Join(config.DataDir(), "cache", "chapters")
+}
+
+func NewOnlineReader(l *logrus.Logger) *OnlineReader {
+	return &OnlineReader{
+		log:   l,
+		cache: diskcacheTool.New(OnlineCacheDir(), config.GetConf().GetReaderCacheBytes()),
+		fetch: func(conf config.Info, chapter *model.Chapter, res *model.SearchResult, book *model.Book) error {
+			return parse.NewChapterParser(conf).Parse(chapter, res, book)
+		},
+		books:    make(map[string]*onlineBook),
+		inflight: make(map[string]chan struct{}),
+	}
+}
+
+// Open parses the book page and catalog with the current source, chapters already in the
+// cache are marked available
+func (r *OnlineReader) Open(res *model.SearchResult) (*model.OnlineBook, error) {
+	conf := config.GetConf()
+	r.cache.SetMaxBytes(conf.GetReaderCacheBytes())
+	book, err := parse.NewBookParser(conf).Parse(res.Url)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := parse.NewCatalogsParser(conf).Parse(res.Url, 1, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalog) == 0 {
+		return nil, fmt.Errorf("no chapters found for %s", book.BookName)
+	}
+	ob := &onlineBook{res: res, book: book, conf: conf, catalog: catalog}
+	id := r.add(ob)
+	return r.describe(id, ob)
+}
+
+func (r *OnlineReader) add(ob *onlineBook) string {
+	id := libraryTool.ID(ob.res.Url)
+	ob.opened = time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.books[id] = ob
+	// Forget the book opened longest ago
+	if len(r.books) > maxOnlineBooks {
+		var oldest string
+		for k, b := range r.books {
+			if oldest == "" || b.opened.Before(r.books[oldest].opened) {
+				oldest = k
+			}
+		}
+		delete(r.books, oldest)
+	}
+	return id
+}
+
+func (r *OnlineReader) describe(id string, ob *onlineBook) (*model.OnlineBook, error) {
+	res := &model.OnlineBook{
+		ID:       id,
+		Book:     *ob.book,
+		SourceID: ob.conf.Base.SourceID,
+		Chapters: make([]model.ReaderChapter, len(ob.catalog)),
+	}
+	for seq, c := range ob.catalog {
+		res.Chapters[seq] = model.ReaderChapter{
+			Seq:       seq,
+			ChapterNo: c.ChapterNo,
+			Title:     c.Title,
+			Available: r.cache.Has(cacheKey(ob.conf, c)),
+		}
+	}
+	state, err := libraryTool.ReadingState(id)
+	if err != nil {
+		return nil, err
+	}
+	if !state.UpdatedAt.IsZero() || len(state.Bookmarks) > 0 {
+		res.Reading = &state
+	}
+	return res, nil
+}
+
+// Chapter returns a chapter by its catalog index and starts prefetching the following ones.
+// A book not opened in this session is opened first
+func (r *OnlineReader) Chapter(res *model.SearchResult, seq int) (*model.ReaderChapterContent, error) {
+	id := libraryTool.ID(res.Url)
+	r.mu.Lock()
+	ob := r.books[id]
+	r.mu.Unlock()
+	if ob == nil {
+		if _, err := r.Open(res); err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		ob = r.books[id]
+		r.mu.Unlock()
+	}
+	if seq < 0 || seq >= len(ob.catalog) {
+		return nil, fmt.Errorf("chapter %d out of range, the book has %d chapters", seq, len(ob.catalog))
+	}
+	chapter, err := r.load(ob, seq)
+	if err != nil {
+		return nil, err
+	}
+	r.prefetching.Add(1)
+	go r.prefetch(ob, seq, ob.generation.Add(1))
+
+	replacer, err := dictTool.ForScope(dictTool.Scope{Book: ob.book.BookName, SourceID: ob.conf.Base.SourceID})
+	if err != nil {
+		return nil, err
+	}
+	content, _ := replacer.Apply(chapter.Content)
+	result := &model.ReaderChapterContent{
+		BookID:  id,
+		Seq:     seq,
+		Title:   chapter.Title,
+		Content: readerTool.Sanitize(content),
+		Prev:    seq - 1,
+		Next:    seq + 1,
+	}
+	if result.Next >= len(ob.catalog) {
+		result.Next = -1
+	}
+	return result, nil
+}
+
+// prefetch loads the next chapters one at a time, so reading ahead does not hit the source harder than a download
+func (r *OnlineReader) prefetch(ob *onlineBook, seq int, generation int64) {
+	defer r.prefetching.Done()
+	end := min(seq+1+ob.conf.Reader.Prefetch, len(ob.catalog))
+	for i := seq + 1; i < end && ob.generation.Load() == generation; i++ {
+		if _, err := r.load(ob, i); err != nil {
+			r.log.Warnf("online prefetch %s error: %v", ob.catalog[i].Title, err)
+			return
+		}
+	}
+}
+
+// load returns a chapter from the cache or fetches it, concurrent loads of the same chapter fetch it once
+func (r *OnlineReader) load(ob *onlineBook, seq int) (*cachedChapter, error) {
+	key := cacheKey(ob.conf, ob.catalog[seq])
+	for {
+		if c, ok := r.cached(key); ok {
+			return c, nil
+		}
+		r.mu.Lock()
+		wait, busy := r.inflight[key]
+		if !busy {
+			r.inflight[key] = make(chan struct{})
+		}
+		r.mu.Unlock()
+		if !busy {
+			break
+		}
+		// The other fetch may have failed, then the cache is checked and fetched again
+		<-wait
+	}
+	defer func() {
+		r.mu.Lock()
+		close(r.inflight[key])
+		delete(r.inflight, key)
+		r.mu.Unlock()
+	}()
+	// Another fetch may have finished between the cache check and taking over
+	if c, ok := r.cached(key); ok {
+		return c, nil
+	}
+
+	// Fetch a copy, the catalog only keeps metadata
+	chapter := *ob.catalog[seq]
+	if err := r.fetch(ob.conf, &chapter, ob.res, ob.book); err != nil {
+		return nil, err
+	}
+	c := &cachedChapter{Title: chapter.Title, Content: chapter.Content}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.cache.Put(key, data); err != nil {
+		r.log.Errorf("online cache error: %v", err)
+	}
+	return c, nil
+}
+
+func (r *OnlineReader) cached(key string) (*cachedChapter, bool) {
+	data, ok := r.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	var c cachedChapter
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, false
+	}
+	return &c, true
+}
+
+func cacheKey(conf config.Info, chapter *model.Chapter) string {
+	return fmt.Sprintf("%d|%s", conf.Base.SourceID, chapter.URL)
+}
+
+// CacheStats reports the size of the read-online cache
+func (r *OnlineReader) CacheStats() model.CacheStats {
+	return r.cache.Stats()
+}
+
+// ClearCache removes every cached chapter
+func (r *OnlineReader) ClearCache() error {
+	return r.cache.Clear()
+}
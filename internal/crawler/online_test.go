@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	diskcacheTool "fy-novel/internal/tools/diskcache"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestOnlineReader(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var fetched sync.Map
+	var fetches atomic.Int32
+	r := NewOnlineReader(logrus.New())
+	r.cache = diskcacheTool.New(filepath.Join(t.TempDir(), "chapters"), 0)
+	r.fetch = func(conf config.Info, chapter *model.Chapter, res *model.SearchResult, book *model.Book) error {
+		fetches.Add(1)
+		if _, dup := fetched.LoadOrStore(chapter.URL, true); dup {
+			t.Errorf("%s fetched twice", chapter.URL)
+		}
+		if strings.HasSuffix(chapter.URL, "/bad") {
+			return fmt.Errorf("fetch failed")
+		}
+		chapter.Content = `<p>` + chapter.Title + `</p><script>x()</script>`
+		return nil
+	}
+
+	var conf config.Info
+	conf.Base.SourceID = 1
+	conf.Reader.Prefetch = 2
+	res := &model.SearchResult{Url: "https://example.com/online"}
+	ob := &onlineBook{res: res, book: &model.Book{BookName: "在线"}, conf: conf, catalog: catalog("a", "b", "c", "d", "/bad")}
+	id := r.add(ob)
+
+	first, err := r.Chapter(res, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.BookID != id || first.Content != "<p>a</p>" || first.Prev != -1 || first.Next != 1 {
+		t.Errorf("first chapter = %+v", first)
+	}
+	// 同时读取正在预取的章节只获取一次
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c, err := r.Chapter(res, 1); err != nil || c.Content != "<p>b</p>" {
+				t.Errorf("second chapter = %+v, %v", c, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 读第 4 章时预取 (第 5 章) 失败不影响当前章节
+	if c, err := r.Chapter(res, 3); err != nil || c.Next != 4 {
+		t.Errorf("fourth chapter = %+v, %v", c, err)
+	}
+	if _, err := r.Chapter(res, 5); err == nil {
+		t.Error("out of range chapter should fail")
+	}
+
+	r.prefetching.Wait()
+	if _, err := r.load(ob, 2); err != nil {
+		t.Fatal(err)
+	}
+	described, err := r.describe(id, ob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq, c := range described.Chapters {
+		if want := seq < 4; c.Available != want {
+			t.Errorf("chapter %d available = %v, want %v", seq, c.Available, want)
+		}
+	}
+	if n := fetches.Load(); n > 5 {
+		t.Errorf("%d fetches for 5 chapters", n)
+	}
+}
@@ -0,0 +1,99 @@
+package crawler
+
+import (
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	chapterTool "fy-novel/internal/tools/chapter"
+	dedupTool "fy-novel/internal/tools/dedup"
+	dictTool "fy-novel/internal/tools/dict"
+	journalTool "fy-novel/internal/tools/journal"
+	qualityTool "fy-novel/internal/tools/quality"
+
+	"github.com/sirupsen/logrus"
+)
+
+// chapterProcessor 按目录顺序处理清洗后的章节: 去重, 写入抓取日志, 再转换为导出格式.
+// 作为 OrderedWriter 的 Hook 调用, 同一时间只有一个章节在处理.
+type chapterProcessor struct {
+	log     *logrus.Logger
+	extName string
+	scope   dictTool.Scope
+	journal *journalTool.Writer
+	// 为 nil 时保留重复段落
+	dedup   *dedupTool.Tracker
+	reports []model.DedupReport
+	quality *qualityTool.Analyzer
+	// 整理后的目录标题, 按 seq 索引; 为 nil 时保留原标题
+	titles []string
+	// 目录, 按 seq 索引; 为 nil 时缺失的章节不记录为抓取失败
+	catalogs []*model.Chapter
+	// 部分下载时第一章在整个目录中的下标, 日志与质量检查按整个目录的下标记录
+	offset int
+	// 交给 Writer 的章节数
+	written int
+}
+
+func newChapterProcessor(log *logrus.Logger, conf config.Info, scope dictTool.Scope) *chapterProcessor {
+	p := &chapterProcessor{
+		log:     log,
+		extName: conf.Base.Extname,
+		scope:   scope,
+		quality: qualityTool.NewAnalyzer(),
+	}
+	if !conf.Filter.KeepDuplicates {
+		p.dedup = dedupTool.NewTracker()
+	}
+	return p
+}
+
+func (p *chapterProcessor) process(seq int, chapter *model.Chapter) (*model.Chapter, error) {
+	if chapter == nil {
+		// 缺失的章节无法判断重叠
+		if p.dedup != nil {
+			p.dedup.Reset()
+		}
+		if seq < len(p.catalogs) {
+			p.failed(seq, p.catalogs[seq])
+		}
+		return nil, nil
+	}
+	// 清洗时已用原标题去除正文开头的重复标题, 这里再换成整理后的标题
+	if seq < len(p.titles) {
+		chapter.Title = p.titles[seq]
+	}
+	if p.dedup != nil {
+		var removals []dedupTool.Removal
+		chapter.Content, removals = p.dedup.Apply(chapter.Content)
+		for _, r := range removals {
+			p.log.Infof("dedup %s: removed %d %s paragraph(s)", chapter.Title, len(r.Paragraphs), r.Kind)
+			p.reports = append(p.reports, model.DedupReport{
+				ChapterNo:    chapter.ChapterNo,
+				ChapterTitle: chapter.Title,
+				Kind:         r.Kind,
+				Paragraphs:   r.Paragraphs,
+			})
+		}
+	}
+	p.quality.Add(p.offset+seq, chapter)
+	if p.journal != nil {
+		if err := p.journal.Append(p.offset+seq, chapter); err != nil {
+			p.log.Errorf("crawl journal append error: %v", err)
+		}
+	}
+	if err := chapterTool.ConvertChapter(chapter, p.extName, p.scope); err != nil {
+		p.log.Errorf("chapterTool.ConvertChapter error: %v", err)
+		return nil, nil
+	}
+	p.written++
+	return chapter, nil
+}
+
+// failed 抓取失败的章节记为可疑章节, 日志中保留目录地址以便重新获取
+func (p *chapterProcessor) failed(seq int, chapter *model.Chapter) {
+	p.quality.AddFailed(p.offset+seq, chapter)
+	if p.journal != nil {
+		if err := p.journal.AppendFailed(p.offset+seq, chapter); err != nil {
+			p.log.Errorf("crawl journal append error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	dictTool "fy-novel/internal/tools/dict"
+	journalTool "fy-novel/internal/tools/journal"
+	qualityTool "fy-novel/internal/tools/quality"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestProcessFailedChapter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/book/1", BookName: "书"}
+	journal, err := journalTool.Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := config.Info{}
+	conf.Base.Extname = "txt"
+	conf.Filter.KeepDuplicates = true
+	p := newChapterProcessor(logrus.New(), conf, dictTool.Scope{Book: book.BookName})
+	p.journal = journal
+	// 从第 11 章开始的部分下载
+	catalogs := catalog("k", "l", "m")
+	for i, c := range catalogs {
+		c.ChapterNo = 11 + i
+	}
+	p.catalogs = catalogs
+	p.offset = 10
+
+	content := strings.Repeat("<p>他抬起头，看着远处的山峰，心中暗暗下定了决心。</p>", 40)
+	for seq, c := range catalogs {
+		var chapter *model.Chapter
+		if seq != 1 {
+			chapter = &model.Chapter{ChapterNo: c.ChapterNo, Title: c.Title, URL: c.URL, Content: content}
+		}
+		if _, err := p.process(seq, chapter); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	suspects := p.quality.Suspects(0)
+	if len(suspects) != 1 || suspects[0].Seq != 11 || suspects[0].URL != "l" ||
+		!reflect.DeepEqual(suspects[0].Reasons, []string{qualityTool.ReasonFetchFailed}) {
+		t.Errorf("suspects = %+v", suspects)
+	}
+	j, err := journalTool.Read(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Chapters) != 2 || j.Chapters[0].Seq != 10 || j.Chapters[1].Seq != 12 {
+		t.Errorf("journal chapters = %+v", j.Chapters)
+	}
+	// 日志保留失败章节的目录地址, 重新获取时使用
+	if len(j.Failed) != 1 || j.Failed[0].Seq != 11 || j.Failed[0].URL != "l" {
+		t.Errorf("journal failed = %+v", j.Failed)
+	}
+}
@@ -0,0 +1,266 @@
+package crawler
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"fy-novel/internal/parse"
+	dictTool "fy-novel/internal/tools/dict"
+	journalTool "fy-novel/internal/tools/journal"
+	mergeTool "fy-novel/internal/tools/merge"
+	qualityTool "fy-novel/internal/tools/quality"
+	titleTool "fy-novel/internal/tools/title"
+)
+
+// Refetch re-downloads the given chapters (catalog indexes) of a crawled book and regenerates the
+// output from the journal. sourceID 0 uses the original source, otherwise the book is looked up
+// by name and author in that source and chapters are matched by title.
+// New content only replaces the old one when it scores better.
+func (nc *novelCrawler) Refetch(bookURL string, seqs []int, sourceID int) (*model.CrawlResult, error) {
+	conf := config.GetConf()
+	startTime := time.Now()
+	j, err := journalTool.Read(bookURL)
+	if err != nil {
+		return nil, fmt.Errorf("refetch error reading journal: %v", err)
+	}
+	if err := nc.refetch(j, seqs, sourceID, conf); err != nil {
+		return nil, err
+	}
+	result, err := nc.rebuild(bookURL, wholeBook, conf, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.TakeTime = int64(time.Since(startTime).Seconds())
+	return result, nil
+}
+
+// seqSpan is an inclusive range of catalog indexes
+type seqSpan struct {
+	first, last int
+}
+
+var wholeBook = seqSpan{first: 0, last: math.MaxInt}
+
+func (s seqSpan) contains(seq int) bool {
+	return seq >= s.first && seq <= s.last
+}
+
+// autoRefetch retries suspect chapters from the original source, then from the fallback sources
+// in order, and regenerates the output of the downloaded span once. A throttling source is
+// slowed down by the per-domain rate limiter, so no extra delay is needed.
+func (nc *novelCrawler) autoRefetch(bookURL string, span seqSpan, result *model.CrawlResult, conf config.Info) *model.CrawlResult {
+	suspects := result.Suspects
+	for _, sourceID := range append([]int{0}, conf.Quality.FallbackSources...) {
+		if len(suspects) == 0 {
+			break
+		}
+		j, err := journalTool.Read(bookURL)
+		if err != nil {
+			nc.log.Errorf("auto refetch error reading journal: %v", err)
+			return result
+		}
+		seqs := make([]int, 0, len(suspects))
+		for _, s := range suspects {
+			seqs = append(seqs, s.Seq)
+		}
+		if err := nc.refetch(j, seqs, sourceID, conf); err != nil {
+			nc.log.Errorf("auto refetch from source %d error: %v", sourceID, err)
+			continue
+		}
+		if j, err = journalTool.Read(bookURL); err != nil {
+			nc.log.Errorf("auto refetch error reading journal: %v", err)
+			return result
+		}
+		suspects = slices.DeleteFunc(journalAnalyzer(j).Suspects(conf.Quality.Threshold), func(s model.SuspectChapter) bool {
+			return !span.contains(s.Seq)
+		})
+	}
+
+	rebuilt, err := nc.rebuild(bookURL, span, conf, nil)
+	if err != nil {
+		nc.log.Errorf("auto refetch error rebuilding output: %v", err)
+		return result
+	}
+	return rebuilt
+}
+
+// journalAnalyzer scores the journaled chapters, failed fetches included
+func journalAnalyzer(j *journalTool.Journal) *qualityTool.Analyzer {
+	analyzer := qualityTool.NewAnalyzer()
+	for _, e := range j.Chapters {
+		analyzer.Add(e.Seq, entryChapter(e))
+	}
+	for _, e := range j.Failed {
+		analyzer.AddFailed(e.Seq, entryChapter(e))
+	}
+	return analyzer
+}
+
+// refetch downloads the chapters again and appends the better ones to the journal.
+// Chapters that failed to download are filled in from their catalog url.
+func (nc *novelCrawler) refetch(j *journalTool.Journal, seqs []int, sourceID int, conf config.Info) error {
+	entries := make(map[int]journalTool.Entry)
+	for _, e := range slices.Concat(j.Chapters, j.Failed) {
+		if slices.Contains(seqs, e.Seq) {
+			entries[e.Seq] = e
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	// The whole book decides what a normal chapter length is
+	median := journalAnalyzer(j).Median()
+
+	fetchConf := conf
+	fetchConf.Base.SourceID = j.SourceID
+	// The cached page is the suspect content, fetch it again
+	fetchConf.Cache.Bypass = true
+	urls := make(map[int]string, len(entries))
+	if sourceID != 0 && sourceID != j.SourceID {
+		fetchConf.Base.SourceID = sourceID
+		var err error
+		if urls, err = matchChapters(&j.Book, entries, fetchConf); err != nil {
+			return err
+		}
+	} else {
+		for seq, e := range entries {
+			urls[seq] = e.URL
+		}
+	}
+
+	writer, err := journalTool.Open(j.Book.URL)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	parser := parse.NewChapterParser(fetchConf)
+	res := &model.SearchResult{Url: j.Book.URL}
+	for _, seq := range slices.Sorted(maps.Keys(urls)) {
+		old := entries[seq]
+		chapter := &model.Chapter{URL: urls[seq], ChapterNo: old.ChapterNo, Title: old.Title}
+		if err := parser.Parse(chapter, res, &j.Book); err != nil {
+			nc.log.Errorf("refetch %s error: %v", old.Title, err)
+			continue
+		}
+		oldScore, _ := qualityTool.Score(qualityTool.Measure(seq, entryChapter(old)), median)
+		newScore, _ := qualityTool.Score(qualityTool.Measure(seq, chapter), median)
+		if newScore >= oldScore {
+			nc.log.Infof("refetch %s: no better content from source %d", old.Title, fetchConf.Base.SourceID)
+			continue
+		}
+		if err := writer.Append(seq, chapter); err != nil {
+			return fmt.Errorf("refetch error writing journal: %v", err)
+		}
+	}
+	return nil
+}
+
+// matchChapters finds the same book in another source and maps chapters to its urls by title
+func matchChapters(book *model.Book, entries map[int]journalTool.Entry, conf config.Info) (map[int]string, error) {
+	results, err := parse.NewSearchResultParser(conf).Parse(book.BookName)
+	if err != nil {
+		return nil, err
+	}
+	var found *model.SearchResult
+	for _, r := range results {
+		if strings.TrimSpace(r.BookName) == book.BookName &&
+			(book.Author == "" || strings.TrimSpace(r.Author) == book.Author) {
+			found = r
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("book %s not found in source %d", book.BookName, conf.Base.SourceID)
+	}
+	catalogs, err := parse.NewCatalogsParser(conf).Parse(found.Url, 1, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	byTitle := make(map[string]string, len(catalogs))
+	for _, c := range catalogs {
+		byTitle[titleKey(c.Title)] = c.URL
+	}
+	urls := make(map[int]string, len(entries))
+	for seq, e := range entries {
+		if url, ok := byTitle[titleKey(e.Title)]; ok {
+			urls[seq] = url
+		}
+	}
+	return urls, nil
+}
+
+// titleKey ignores numeral style, spacing and punctuation differences between sources
+func titleKey(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return -1
+	}, titleTool.Parse(title).String())
+}
+
+// rebuild regenerates the output file from the journaled chapters in span. A non-nil catalog replaces
+// the library snapshot, e.g. after new chapters of a subscribed book were appended.
+func (nc *novelCrawler) rebuild(bookURL string, span seqSpan, conf config.Info, catalogs []*model.Chapter) (*model.CrawlResult, error) {
+	// Refetches and subscription updates append to the journal, drop the superseded entries first
+	if err := journalTool.Compact(bookURL, nil); err != nil {
+		nc.log.Errorf("rebuild journal compact error: %v", err)
+	}
+	j, err := journalTool.Read(bookURL)
+	if err != nil {
+		return nil, fmt.Errorf("rebuild error reading journal: %v", err)
+	}
+	writer, err := mergeTool.NewWriter(&j.Book, conf.Base.DownloadPath, conf)
+	if err != nil {
+		return nil, err
+	}
+	processor := newChapterProcessor(nc.log, conf, dictTool.Scope{Book: j.Book.BookName, SourceID: j.SourceID})
+	// Chapters that still failed stay suspects so they can be fetched again
+	for _, e := range j.Failed {
+		if span.contains(e.Seq) {
+			processor.quality.AddFailed(e.Seq, entryChapter(e))
+		}
+	}
+	next := span.first
+	for _, e := range j.Chapters {
+		if !span.contains(e.Seq) {
+			continue
+		}
+		// Missing chapters break the overlap check between neighbours
+		if e.Seq != next {
+			processor.process(next, nil)
+		}
+		next = e.Seq + 1
+		chapter, err := processor.process(e.Seq, entryChapter(e))
+		if err == nil && chapter != nil {
+			err = writer.WriteChapter(chapter)
+		}
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+	}
+	outputPath, err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	recordConf := conf
+	recordConf.Base.SourceID = j.SourceID
+	nc.record(&j.Book, recordConf, catalogs, outputPath, processor.written)
+	return &model.CrawlResult{
+		OutputPath: outputPath,
+		Dedup:      processor.reports,
+		Suspects:   processor.quality.Suspects(conf.Quality.Threshold),
+	}, nil
+}
+
+func entryChapter(e journalTool.Entry) *model.Chapter {
+	return &model.Chapter{URL: e.URL, ChapterNo: e.ChapterNo, Title: e.Title, Content: e.Content}
+}
@@ -0,0 +1,172 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"fy-novel/internal/parse"
+	journalTool "fy-novel/internal/tools/journal"
+	libraryTool "fy-novel/internal/tools/library"
+)
+
+// CheckUpdate fetches the book page and catalog of a subscribed book from its own source and
+// counts the chapters that have not been downloaded yet. With AutoDownload they are fetched and
+// the output is regenerated. The returned subscription is not saved.
+func (nc *novelCrawler) CheckUpdate(sub model.Subscription) (*model.SubscriptionUpdate, error) {
+	conf := config.GetConf()
+	conf.Base.SourceID = sub.SourceID
+	// New chapters only show up in a freshly fetched catalog
+	fresh := conf
+	fresh.Cache.Bypass = true
+	book, err := parse.NewBookParser(fresh).Parse(sub.Book.URL)
+	if err != nil {
+		return nil, err
+	}
+	catalogs, err := parse.NewCatalogsParser(fresh).Parse(sub.Book.URL, 1, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalogs) == 0 {
+		return nil, fmt.Errorf("empty catalog for %s", sub.Book.URL)
+	}
+
+	// Chapters in the last downloaded catalog are known, without a download the
+	// catalog seen when subscribing is the starting point
+	known, err := libraryTool.Catalog(sub.ID)
+	if err != nil && !errors.Is(err, libraryTool.ErrNotFound) {
+		return nil, err
+	}
+	if sub.CheckedAt.IsZero() && known == nil {
+		sub.Baseline = len(catalogs)
+	}
+	pending := pendingChapters(catalogs, known, sub.Baseline)
+
+	now := time.Now()
+	update := &model.SubscriptionUpdate{}
+	for _, c := range pending {
+		if c.ChapterNo > sub.ChapterCount {
+			update.NewTitles = append(update.NewTitles, c.Title)
+		}
+	}
+	if book.BookName != "" {
+		sub.Book = *book
+	}
+	sub.ChapterCount = len(catalogs)
+	sub.LatestChapter = catalogs[len(catalogs)-1].Title
+	sub.NewChapters = len(pending)
+	sub.CheckedAt = now
+	sub.LastError = ""
+	if len(update.NewTitles) > 0 {
+		sub.UpdatedAt = now
+	}
+
+	if sub.AutoDownload && len(pending) > 0 {
+		outputPath, failed, err := nc.downloadUpdate(&sub.Book, catalogs, pending, conf)
+		if err != nil {
+			nc.log.Errorf("subscription %s download error: %v", sub.Book.BookName, err)
+			sub.LastError = err.Error()
+		} else {
+			update.OutputPath = outputPath
+			// Failed chapters stay pending and are fetched again on the next check
+			sub.NewChapters = failed
+			if failed > 0 {
+				sub.LastError = fmt.Sprintf("%d chapter(s) failed to download", failed)
+			} else {
+				sub.Baseline = len(catalogs)
+			}
+		}
+	}
+	update.Subscription = sub
+	return update, nil
+}
+
+// downloadUpdate appends the pending chapters to the journal and regenerates the output from it,
+// returning how many chapters failed to download. Failed chapters are left out of the library
+// snapshot so the next check finds them pending again.
+// When the journal is missing or no longer lines up with the catalog the whole book is downloaded again.
+func (nc *novelCrawler) downloadUpdate(
+	book *model.Book,
+	catalogs []*model.Chapter,
+	pending []*model.Chapter,
+	conf config.Info,
+) (string, int, error) {
+	j, err := journalTool.Read(book.URL)
+	if err != nil || j.SourceID != conf.Base.SourceID || !journalMatches(j, catalogs) {
+		res := &model.SearchResult{Url: book.URL, BookName: book.BookName, Author: book.Author}
+		result, err := nc.crawl(context.Background(), res, conf, 1, math.MaxInt)
+		if err != nil {
+			return "", 0, err
+		}
+		if result == nil {
+			return "", 0, fmt.Errorf("empty catalog for %s", book.URL)
+		}
+		return result.OutputPath, len(fetchFailedURLs(result.Suspects)), nil
+	}
+
+	writer, err := journalTool.Open(book.URL)
+	if err != nil {
+		return "", 0, err
+	}
+	parser := parse.NewChapterParser(conf)
+	res := &model.SearchResult{Url: book.URL}
+	failed := make(map[string]bool)
+	for _, chapter := range pending {
+		// ChapterNo is the 1-based catalog position
+		if err := parser.Parse(chapter, res, book); err != nil {
+			nc.log.Errorf("subscription fetch %s error: %v", chapter.Title, err)
+			failed[chapter.URL] = true
+			err = writer.AppendFailed(chapter.ChapterNo-1, chapter)
+		} else {
+			err = writer.Append(chapter.ChapterNo-1, chapter)
+		}
+		if err != nil {
+			writer.Close()
+			return "", 0, fmt.Errorf("subscription error writing journal: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", 0, err
+	}
+	result, err := nc.rebuild(book.URL, wholeBook, conf, withoutURLs(catalogs, failed))
+	if err != nil {
+		return "", 0, err
+	}
+	return result.OutputPath, len(failed), nil
+}
+
+// pendingChapters returns the catalog chapters missing from the known snapshot,
+// or those after the baseline when there is no snapshot
+func pendingChapters(catalogs []*model.Chapter, known []model.CatalogItem, baseline int) []*model.Chapter {
+	if known == nil {
+		if baseline >= len(catalogs) {
+			return nil
+		}
+		return catalogs[baseline:]
+	}
+	urls := make(map[string]bool, len(known))
+	for _, item := range known {
+		urls[item.URL] = true
+	}
+	var res []*model.Chapter
+	for _, c := range catalogs {
+		if !urls[c.URL] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// journalMatches reports whether every journaled chapter is still at the same catalog position
+func journalMatches(j *journalTool.Journal, catalogs []*model.Chapter) bool {
+	for _, e := range j.Chapters {
+		if e.Seq >= len(catalogs) || catalogs[e.Seq].URL != e.URL {
+			return false
+		}
+	}
+	return true
+}
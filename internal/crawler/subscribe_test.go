@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"slices"
+	"testing"
+
+	"fy-novel/internal/model"
+	journalTool "fy-novel/internal/tools/journal"
+	qualityTool "fy-novel/internal/tools/quality"
+)
+
+func catalog(urls ...string) []*model.Chapter {
+	res := make([]*model.Chapter, len(urls))
+	for i, url := range urls {
+		res[i] = &model.Chapter{ChapterNo: i + 1, Title: url, URL: url}
+	}
+	return res
+}
+
+func TestPendingChapters(t *testing.T) {
+	catalogs := catalog("a", "b", "c", "d")
+	tests := []struct {
+		name     string
+		known    []model.CatalogItem
+		baseline int
+		want     []string
+	}{
+		{"baseline", nil, 2, []string{"c", "d"}},
+		{"baseline up to date", nil, 4, nil},
+		{"baseline after catalog shrank", nil, 6, nil},
+		// 快照中缺少的章节 (包括中间插入的) 都需要下载
+		{"snapshot", []model.CatalogItem{{URL: "a"}, {URL: "c"}}, 0, []string{"b", "d"}},
+		{"snapshot up to date", []model.CatalogItem{{URL: "a"}, {URL: "b"}, {URL: "c"}, {URL: "d"}}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range pendingChapters(catalogs, tt.known, tt.baseline) {
+				got = append(got, c.URL)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestJournalMatches(t *testing.T) {
+	j := &journalTool.Journal{Chapters: []journalTool.Entry{{Seq: 0, URL: "a"}, {Seq: 1, URL: "b"}}}
+	if !journalMatches(j, catalog("a", "b", "c")) {
+		t.Error("appended chapters should match")
+	}
+	if journalMatches(j, catalog("a", "x", "b")) {
+		t.Error("inserted chapter shifts the positions")
+	}
+	if journalMatches(j, catalog("a")) {
+		t.Error("shorter catalog should not match")
+	}
+}
+
+func TestSnapshotSkipsFailedChapters(t *testing.T) {
+	suspects := []model.SuspectChapter{
+		{URL: "b", Reasons: []string{qualityTool.ReasonFetchFailed}},
+		{URL: "c", Reasons: []string{qualityTool.ReasonShort}},
+	}
+	var got []string
+	for _, c := range withoutURLs(catalog("a", "b", "c"), fetchFailedURLs(suspects)) {
+		got = append(got, c.URL)
+	}
+	// 只有抓取失败的章节不算已下载, 内容可疑的章节已在日志中
+	if !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("snapshot = %v", got)
+	}
+	// 全部失败时仍然记录空的快照, 而不是保留旧快照
+	if snapshot := withoutURLs(catalog("b"), fetchFailedURLs(suspects)); snapshot == nil || len(snapshot) != 0 {
+		t.Errorf("all failed snapshot = %v", snapshot)
+	}
+
+	// 之后的检查中失败的章节仍是待下载的章节
+	known := make([]model.CatalogItem, 0, 2)
+	for _, c := range withoutURLs(catalog("a", "b", "c"), fetchFailedURLs(suspects)) {
+		known = append(known, model.CatalogItem{URL: c.URL})
+	}
+	pending := pendingChapters(catalog("a", "b", "c", "d"), known, 0)
+	if len(pending) != 2 || pending[0].URL != "b" || pending[1].URL != "d" {
+		t.Errorf("pending = %+v", pending)
+	}
+}
@@ -4,14 +4,10 @@ const (
 	NovelExtname_TXT  = "txt"
 	NovelExtname_EPUB = "epub"
 	NovelExtname_HTML = "html"
+	NovelExtname_MD   = "md"
+	NovelExtname_FB2  = "fb2"
+	NovelExtname_PDF  = "pdf"
 
-	NovelCapability_DOWNLOAD     = "1"
-	NovelCapability_CHECK_UPDATE = "2"
-	NovelCapability_PRINT_CONF   = "3"
-	NovelCapability_PRINT_HINT   = "4"
-	NovelCapability_EXIT         = "5"
-
-	ActionDownload_REOPEN   = 0
-	ActionDownload_START    = 1
-	ActionDownload_RESELECT = 2
+	NovelLayout_SINGLE = "single"
+	NovelLayout_MULTI  = "multi"
 )
@@ -1,111 +1,176 @@
 package definition
 
 const (
-	NovelTemp_EPUB = `    <h2>{{ .Title }}</h2>
-	{{ .Content }}`
+	NovelTemp_EPUB = `<section epub:type="chapter" role="doc-chapter">
+  <h2 class="chapter-title">{{ .Title }}</h2>
+  {{ .Content }}
+</section>`
 
-	NovelTemp_EPUB_OLD = `<?xml version="1.0" encoding="UTF-8" ?>
-<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">
-<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="zh-CN">
+	NovelTemp_HTML = `<h1>{{ .Title }}</h1>
+<div class="content">
+  {{ .Content }}
+</div>`
+
+	// NovelStyle_HTML 网页导出的内置样式, 不依赖任何 CDN
+	NovelStyle_HTML = `body {
+  max-width: 800px;
+  margin: 60px auto;
+  padding: 0 20px;
+  background: #111;
+  color: #939392;
+  font-family: -apple-system, "PingFang SC", "Microsoft YaHei", "Noto Sans CJK SC", sans-serif;
+}
+
+a {
+  color: #6c9bd2;
+  text-decoration: none;
+}
+
+h1 {
+  color: #939392;
+}
+
+.book-info p {
+  text-indent: 0;
+  margin: 8px 0;
+  font-size: 18px;
+}
+
+.toc {
+  list-style: none;
+  padding: 0;
+  columns: 2;
+}
+
+.toc li {
+  margin: 8px 0;
+}
+
+.content p {
+  text-indent: 2em;
+  letter-spacing: 0.1em;
+  font-size: 25px;
+  margin: 40px 0;
+}
+
+.nav-bar {
+  display: flex;
+  justify-content: space-between;
+  margin: 60px 0;
+  font-size: 20px;
+}
+
+.nav-bar .disabled {
+  visibility: hidden;
+}
+
+section.chapter {
+  margin-bottom: 100px;
+}
+`
+
+	// NovelTemp_HTML_Index 多页网站的目录页
+	NovelTemp_HTML_Index = `<!DOCTYPE html>
+<html lang="zh-CN">
 <head>
-  <meta http-equiv="Content-Type" content="application/xhtml+xml; charset=utf-8" />
-  <title>{{ .Title }}</title>
-  <style type="text/css">
-    p {
-      text-indent: 2em;
-      letter-spacing: 1px;
-    }
-  </style>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Book.BookName }}</title>
+  <link href="style.css" rel="stylesheet">
 </head>
+
 <body>
-  <h2>{{ .Title }}</h2>
-  <div>
-    {{ .Content }}
+  <h1>{{ .Book.BookName }}</h1>
+  <div class="book-info">
+    <p>作者：{{ .Book.Author }}</p>
+    {{- if .Book.Category }}
+    <p>分类：{{ .Book.Category }}</p>
+    {{- end }}
+    <p>简介：{{ .Book.Intro }}</p>
   </div>
+  <ol class="toc">
+    {{- range .Chapters }}
+    <li><a href="{{ .Href }}">{{ .Title }}</a></li>
+    {{- end }}
+  </ol>
 </body>
+
 </html>
 `
-	NovelTemp_HTML = `<html xmlns="http://www.w3.org/1999/xhtml">
+
+	// NovelTemp_HTML_Page 多页网站的章节页, 带上一章/目录/下一章导航
+	NovelTemp_HTML_Page = `<!DOCTYPE html>
+<html lang="zh-CN">
 <head>
-  <title>{{ .Title }}</title>
   <meta charset="UTF-8">
-  <link href="https://cdn.staticfile.net/bootstrap/5.3.2/css/bootstrap.min.css" rel="stylesheet">
-  <style type="text/css"/>
-    body {
-      max-width: 800px;
-      margin: 100px auto;
-      background: #111;
-    }
-
-    h1 {
-      color: #939392;
-    }
-
-    p {
-      text-indent: 2em;
-      letter-spacing: 0.1em;
-      color: #939392;
-      font-size: 25px;
-      margin: 40px 0;
-    }
-
-    .bottom-bar {
-      margin-top: 100px;
-    }
-  </style>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }} - {{ .BookName }}</title>
+  <link href="style.css" rel="stylesheet">
 </head>
 
 <body>
-  <h1>{{ .Title }}</h1>
-  <div class="content">
-    {{ .Content }}
-  </div>
-  <div class="bottom-bar d-flex justify-content-between">
-    <button id="btn-pre" type="button" class="btn btn-primary btn-lg w-25" onclick="turnPage('pre')">上一页</button>
-    <button id="btn-next" type="button" class="btn btn-primary btn-lg w-25" onclick="turnPage('next')">下一页</button>
+  {{ .Content }}
+  <div class="nav-bar">
+    <a id="btn-pre" {{ if .Prev }}href="{{ .Prev }}"{{ else }}class="disabled"{{ end }}>上一章</a>
+    <a id="btn-index" href="index.html">目录</a>
+    <a id="btn-next" {{ if .Next }}href="{{ .Next }}"{{ else }}class="disabled"{{ end }}>下一章</a>
   </div>
 </body>
 
-<script src="https://cdn.staticfile.net/bootstrap/5.3.2/js/bootstrap.min.js"></script>
 <script type="text/javascript">
-  // 当前页面 URL
-  const url = decodeURI(location.href)
-  const prefixUrl = url.substring(0, url.lastIndexOf('/') + 1)
-
-  // 获取当前文件名
-  let index = url.match(/(\d+)\_.html/)[1]
-  console.log('当前页索引', index)
-
-  if (index <= 1) {
-    document.getElementById('btn-pre').disabled = true
-  }
-
-  function turnPage(action) {
-    action === 'next' ? ++index : --index
-    if (index < 1) {
-      index = 1
-      return
-    }
-    location.href = prefixUrl + index + '_.html'
-  }
-
-  // 监听键盘按下事件
+  // 左右方向键翻页
   document.addEventListener('keyup', function (e) {
-    // 检查按下的是左箭头键还是右箭头键
-    switch (e.key) {
-      case 'ArrowRight':
-        turnPage('next')
-        break
-      case 'ArrowLeft':
-        turnPage('pre')
-        break
-      default:
-        break
+    var id = { ArrowLeft: 'btn-pre', ArrowRight: 'btn-next' }[e.key]
+    var link = id && document.getElementById(id)
+    if (link && link.href) {
+      location.href = link.href
     }
   })
 </script>
 
 </html>
+`
 
+	// NovelTemp_HTML_SingleHead 单文件导出的页头与目录, 所有章节与样式内联在一个网页中
+	NovelTemp_HTML_SingleHead = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Book.BookName }}</title>
+  <style type="text/css">
+{{ .Style }}
+  </style>
+</head>
+
+<body>
+  <h1 id="index">{{ .Book.BookName }}</h1>
+  <div class="book-info">
+    <p>作者：{{ .Book.Author }}</p>
+    {{- if .Book.Category }}
+    <p>分类：{{ .Book.Category }}</p>
+    {{- end }}
+    <p>简介：{{ .Book.Intro }}</p>
+  </div>
+  <ol class="toc">
+    {{- range .Chapters }}
+    <li><a href="#{{ .ID }}">{{ .Title }}</a></li>
+    {{- end }}
+  </ol>`
+
+	// NovelTemp_HTML_SingleSection 单文件导出中的一章
+	NovelTemp_HTML_SingleSection = `
+  <section class="chapter" id="{{ .ID }}">
+    {{ .Content }}
+    <div class="nav-bar">
+      <a href="#index">目录</a>
+    </div>
+  </section>`
+
+	// NovelTemp_HTML_SingleFoot 单文件导出的页尾
+	NovelTemp_HTML_SingleFoot = `
+</body>
+
+</html>
 `
 )
@@ -0,0 +1,42 @@
+package functions
+
+import (
+	"fy-novel/internal/crawler"
+	"fy-novel/internal/model"
+	batchTool "fy-novel/internal/tools/batch"
+
+	"github.com/sirupsen/logrus"
+)
+
+type BatchDownloader struct {
+	log     *logrus.Logger
+	crawler crawler.Crawler
+}
+
+func NewBatchDownloader(l *logrus.Logger) *BatchDownloader {
+	return &BatchDownloader{log: l, crawler: crawler.NewNovelCrawler(l)}
+}
+
+// Run 读取书单并依次下载, parallel 为同时下载的书籍数, 共用配置的线程数.
+// 报告写入 reportPath, 为空时写在书单旁边
+func (b *BatchDownloader) Run(
+	listPath string,
+	parallel int,
+	reportPath string,
+	progress func(model.BatchProgress),
+) (*model.BatchReport, error) {
+	items, err := batchTool.Load(listPath)
+	if err != nil {
+		return nil, err
+	}
+	report := b.crawler.Batch(items, parallel, progress)
+	if reportPath == "" {
+		reportPath = batchTool.ReportPath(listPath)
+	}
+	if err := batchTool.WriteReport(report, reportPath); err != nil {
+		b.log.Errorf("batch report error: %v", err)
+	} else {
+		report.ReportPath = reportPath
+	}
+	return report, nil
+}
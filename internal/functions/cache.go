@@ -0,0 +1,27 @@
+package functions
+
+import (
+	"fy-novel/internal/model"
+	httpcacheTool "fy-novel/internal/tools/httpcache"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CacheHandler 抓取页面缓存
+type CacheHandler struct {
+	log *logrus.Logger
+}
+
+func NewCacheHandler(l *logrus.Logger) *CacheHandler {
+	return &CacheHandler{log: l}
+}
+
+// Stats 抓取缓存统计, 命中次数从进程启动时开始计算
+func (c *CacheHandler) Stats() model.CacheStats {
+	return httpcacheTool.Stats()
+}
+
+// Clear 删除全部缓存的页面
+func (c *CacheHandler) Clear() error {
+	return httpcacheTool.Clear()
+}
@@ -0,0 +1,86 @@
+package functions
+
+import (
+	"fy-novel/internal/model"
+	dictTool "fy-novel/internal/tools/dict"
+	journalTool "fy-novel/internal/tools/journal"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 试运行最多返回的章节数
+const dryRunMaxChanges = 200
+
+type DictHandler struct {
+	log *logrus.Logger
+}
+
+func NewDictHandler(l *logrus.Logger) *DictHandler {
+	return &DictHandler{log: l}
+}
+
+func (d *DictHandler) List() ([]model.DictEntry, error) {
+	return dictTool.Load()
+}
+
+func (d *DictHandler) Save(entry model.DictEntry) (model.DictEntry, error) {
+	return dictTool.Put(entry)
+}
+
+func (d *DictHandler) Delete(id string) error {
+	return dictTool.Delete(id)
+}
+
+// DryRun 在抓取日志中的章节上试运行条目, 列出会被修改的章节 (不修改任何文件)
+func (d *DictHandler) DryRun(entry model.DictEntry) (*model.DryRunDictEntryResult, error) {
+	// 试运行时忽略条目的停用状态
+	entry.Disabled = false
+	replacer, err := dictTool.NewReplacer([]model.DictEntry{entry})
+	if err != nil {
+		return nil, err
+	}
+	journals, err := journalTool.List()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &model.DryRunDictEntryResult{}
+	for _, j := range journals {
+		scope := dictTool.Scope{Book: j.Book.BookName, SourceID: j.SourceID}
+		if !dictTool.Matches(entry, scope) {
+			continue
+		}
+		book, err := journalTool.ReadFile(j.Path)
+		if err != nil {
+			d.log.Errorf("dict dry run: read journal %s error: %v", j.Path, err)
+			continue
+		}
+		for _, chapter := range book.Chapters {
+			_, changes := replacer.Apply(chapter.Content)
+			if len(changes) == 0 {
+				continue
+			}
+			count := 0
+			for _, c := range changes {
+				count += c.Count
+			}
+			res.ChapterCount++
+			res.MatchCount += count
+			if len(res.Changes) >= dryRunMaxChanges {
+				res.Truncated = true
+				continue
+			}
+			before, after := dictTool.Snippet(changes[0].Before, changes[0].After, 20)
+			res.Changes = append(res.Changes, model.DictDryRunChange{
+				BookName:     book.Book.BookName,
+				Author:       book.Book.Author,
+				ChapterIndex: chapter.Seq,
+				ChapterTitle: chapter.Title,
+				Count:        count,
+				Before:       before,
+				After:        after,
+			})
+		}
+	}
+	return res, nil
+}
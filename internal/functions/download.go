@@ -26,3 +26,18 @@ func (d *Downloader) DownLoad(sr *model.SearchResult) (*model.CrawlResult, error
 	start, end := 1, math.MaxInt // Max int
 	return d.crawler.Crawl(sr, start, end)
 }
+
+// Refetch 重新获取可疑章节, sourceID 为 0 时使用原书源
+func (d *Downloader) Refetch(bookURL string, seqs []int, sourceID int) (*model.CrawlResult, error) {
+	return d.crawler.Refetch(bookURL, seqs, sourceID)
+}
+
+// CheckCatalog 下载前检查目录中缺失或重复的章节号
+func (d *Downloader) CheckCatalog(sr *model.SearchResult) (*model.CatalogReport, error) {
+	return d.crawler.CheckCatalog(sr)
+}
+
+// Details 获取书籍详情与目录, 不下载章节
+func (d *Downloader) Details(sr *model.SearchResult) (*model.BookDetails, error) {
+	return d.crawler.Details(sr)
+}
@@ -0,0 +1,34 @@
+package functions
+
+import (
+	"fy-novel/internal/model"
+	fulltextTool "fy-novel/internal/tools/fulltext"
+
+	"github.com/sirupsen/logrus"
+)
+
+type FullTextHandler struct {
+	log *logrus.Logger
+}
+
+func NewFullTextHandler(l *logrus.Logger) *FullTextHandler {
+	return &FullTextHandler{log: l}
+}
+
+// Search 搜索前先为新增或修改过的抓取日志补建索引
+func (f *FullTextHandler) Search(query string, limit int) (*model.FullTextSearchResult, error) {
+	if n, err := f.Sync(); err != nil {
+		return nil, err
+	} else if n > 0 {
+		f.log.Infof("fulltext: indexed %d books", n)
+	}
+	hits, truncated, err := fulltextTool.Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &model.FullTextSearchResult{Hits: hits, Truncated: truncated}, nil
+}
+
+func (f *FullTextHandler) Sync() (int, error) {
+	return fulltextTool.Sync()
+}
@@ -0,0 +1,269 @@
+package functions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/crawler"
+	"fy-novel/internal/definition"
+	"fy-novel/internal/model"
+	"fy-novel/internal/source"
+	progressTool "fy-novel/internal/tools/progress"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// 同时运行的下载任务数, 每个任务使用配置的线程数, 其余任务排队
+	jobSlots = 1
+	// 保留的已结束任务数, 超出时丢弃最早的
+	maxFinishedJobs = 100
+)
+
+var (
+	ErrJobNotFound = errors.New("download job not found")
+	ErrJobFinished = errors.New("download job already finished")
+	// 同一本书同时只能有一个未结束的任务, 下载进度按书籍地址记录
+	ErrJobConflict = errors.New("book is already being downloaded")
+)
+
+var jobFormats = []string{
+	definition.NovelExtname_TXT,
+	definition.NovelExtname_EPUB,
+	definition.NovelExtname_HTML,
+	definition.NovelExtname_MD,
+	definition.NovelExtname_FB2,
+	definition.NovelExtname_PDF,
+}
+
+type downloadJob struct {
+	model.DownloadJob
+	cancel context.CancelFunc
+}
+
+// DownloadJobs 后台下载任务, 只保存在内存中
+type DownloadJobs struct {
+	log     *logrus.Logger
+	crawler crawler.Crawler
+	mu      sync.Mutex
+	jobs    map[string]*downloadJob
+	// 创建顺序
+	order []string
+	slots chan struct{}
+}
+
+func NewDownloadJobs(l *logrus.Logger) *DownloadJobs {
+	return &DownloadJobs{
+		log:     l,
+		crawler: crawler.NewNovelCrawler(l),
+		jobs:    make(map[string]*downloadJob),
+		slots:   make(chan struct{}, jobSlots),
+	}
+}
+
+// Create 校验参数并创建任务, 任务在后台排队下载
+func (j *DownloadJobs) Create(req model.DownloadJobRequest) (model.DownloadJob, error) {
+	req.URL = strings.TrimSpace(req.URL)
+	req.Format = strings.ToLower(strings.TrimSpace(req.Format))
+	if err := validateJob(req); err != nil {
+		return model.DownloadJob{}, err
+	}
+	id, err := newJobID()
+	if err != nil {
+		return model.DownloadJob{}, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.jobs {
+		if job.Request.URL == req.URL && !jobFinished(job.Status) {
+			return model.DownloadJob{}, ErrJobConflict
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &downloadJob{
+		DownloadJob: model.DownloadJob{ID: id, Request: req, Status: model.JobQueued, CreatedAt: time.Now()},
+		cancel:      cancel,
+	}
+	j.jobs[id] = job
+	j.order = append(j.order, id)
+	go j.run(ctx, job)
+	return job.DownloadJob, nil
+}
+
+// Get 返回任务状态, 运行中的任务带有当前进度
+func (j *DownloadJobs) Get(id string) (model.DownloadJob, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok {
+		return model.DownloadJob{}, ErrJobNotFound
+	}
+	return j.snapshot(job), nil
+}
+
+// List 返回全部任务, 最新的在前
+func (j *DownloadJobs) List() []model.DownloadJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jobs := make([]model.DownloadJob, 0, len(j.order))
+	for i := len(j.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, j.snapshot(j.jobs[j.order[i]]))
+	}
+	return jobs
+}
+
+// Cancel 取消排队或运行中的任务, 已获取的章节会停止写入, 不生成导出文件
+func (j *DownloadJobs) Cancel(id string) (model.DownloadJob, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok {
+		return model.DownloadJob{}, ErrJobNotFound
+	}
+	if jobFinished(job.Status) {
+		return job.DownloadJob, ErrJobFinished
+	}
+	job.cancel()
+	return j.snapshot(job), nil
+}
+
+// CancelAll 取消所有未结束的任务, 退出前调用
+func (j *DownloadJobs) CancelAll() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.jobs {
+		job.cancel()
+	}
+}
+
+func (j *DownloadJobs) run(ctx context.Context, job *downloadJob) {
+	defer job.cancel()
+	select {
+	case j.slots <- struct{}{}:
+		defer func() { <-j.slots }()
+	case <-ctx.Done():
+		j.finish(job, nil, ctx.Err())
+		return
+	}
+	j.mu.Lock()
+	job.Status = model.JobRunning
+	req := job.Request
+	j.mu.Unlock()
+
+	conf := config.GetConf()
+	if req.SourceID != 0 {
+		conf.Base.SourceID = req.SourceID
+	}
+	if req.Format != "" {
+		conf.Base.Extname = req.Format
+	}
+	start, end := req.Start, req.End
+	if start == 0 {
+		start = 1
+	}
+	if end == 0 {
+		end = math.MaxInt
+	}
+	result, err := j.crawler.CrawlContext(ctx, &model.SearchResult{Url: req.URL}, conf, start, end)
+	if err == nil && result == nil {
+		err = errors.New("no chapters in range")
+	}
+	// 取消时正在获取书籍详情或目录的任务也算作取消
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		j.log.Errorf("download job %s error: %v", job.ID, err)
+	}
+	j.finish(job, result, err)
+}
+
+func (j *DownloadJobs) finish(job *downloadJob, result *model.CrawlResult, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// 保留结束时的进度, 之后同一本书的任务会重置进度
+	job.DownloadJob = j.snapshot(job)
+	now := time.Now()
+	job.FinishedAt = &now
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.Status = model.JobCanceled
+	case err != nil:
+		job.Status = model.JobFailed
+		job.Error = err.Error()
+	default:
+		job.Status = model.JobDone
+		job.Result = result
+	}
+	j.prune()
+}
+
+// prune 丢弃超出数量的最早的已结束任务
+func (j *DownloadJobs) prune() {
+	finished := 0
+	for _, id := range j.order {
+		if jobFinished(j.jobs[id].Status) {
+			finished++
+		}
+	}
+	order := j.order[:0]
+	for _, id := range j.order {
+		if finished > maxFinishedJobs && jobFinished(j.jobs[id].Status) {
+			delete(j.jobs, id)
+			finished--
+			continue
+		}
+		order = append(order, id)
+	}
+	j.order = order
+}
+
+func (j *DownloadJobs) snapshot(job *downloadJob) model.DownloadJob {
+	snap := job.DownloadJob
+	if snap.Status == model.JobRunning {
+		if completed, total, ok := progressTool.GetProgress(snap.Request.URL); ok {
+			snap.Completed, snap.Total = completed, total
+		}
+	}
+	return snap
+}
+
+func validateJob(req model.DownloadJobRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid book url %q", req.URL)
+	}
+	if req.SourceID != 0 && !slices.Contains(source.IDs(), req.SourceID) {
+		return fmt.Errorf("unknown source id %d", req.SourceID)
+	}
+	if req.Format != "" && !slices.Contains(jobFormats, req.Format) {
+		return fmt.Errorf("unsupported format %q", req.Format)
+	}
+	if req.Start < 0 || req.End < 0 || (req.End > 0 && req.Start > req.End) {
+		return fmt.Errorf("invalid chapter range %d-%d", req.Start, req.End)
+	}
+	return nil
+}
+
+func jobFinished(status string) bool {
+	return status == model.JobDone || status == model.JobFailed || status == model.JobCanceled
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package functions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	libraryTool "fy-novel/internal/tools/library"
+	opdsTool "fy-novel/internal/tools/opds"
+	"fy-novel/pkg/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+type LibraryHandler struct {
+	log *logrus.Logger
+}
+
+func NewLibraryHandler(l *logrus.Logger) *LibraryHandler {
+	return &LibraryHandler{log: l}
+}
+
+// Search 关键字为空时返回全部书籍
+func (l *LibraryHandler) Search(keyword string) ([]model.LibraryEntry, error) {
+	return libraryTool.Search(keyword)
+}
+
+// OpenFolder 打开最近一次导出文件所在的目录
+func (l *LibraryHandler) OpenFolder(id string) error {
+	entry, err := libraryTool.Get(id)
+	if err != nil {
+		return err
+	}
+	if len(entry.Outputs) == 0 {
+		return fmt.Errorf("no exported file for %s", entry.Book.BookName)
+	}
+	dir := filepath.Dir(entry.Outputs[0].Path)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("output folder not found: %v", err)
+	}
+	return utils.OpenPath(dir)
+}
+
+func (l *LibraryHandler) Delete(id string, removeFiles bool) error {
+	return libraryTool.Delete(id, removeFiles)
+}
+
+// Shelf 书库与下载目录中可供阅读器下载的 epub/txt 书籍, 最近更新的在前
+func (l *LibraryHandler) Shelf() ([]opdsTool.Book, error) {
+	return opdsTool.Load(config.GetConf().Base.DownloadPath)
+}
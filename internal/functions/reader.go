@@ -0,0 +1,69 @@
+package functions
+
+import (
+	"fy-novel/internal/crawler"
+	"fy-novel/internal/model"
+	libraryTool "fy-novel/internal/tools/library"
+	readerTool "fy-novel/internal/tools/reader"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ReaderHandler struct {
+	log    *logrus.Logger
+	online *crawler.OnlineReader
+}
+
+func NewReaderHandler(l *logrus.Logger) *ReaderHandler {
+	return &ReaderHandler{log: l, online: crawler.NewOnlineReader(l)}
+}
+
+// Books 有抓取日志、可以阅读的书
+func (r *ReaderHandler) Books() ([]model.ReaderBook, error) {
+	return readerTool.Books()
+}
+
+func (r *ReaderHandler) Chapters(id string) ([]model.ReaderChapter, error) {
+	return readerTool.Chapters(id)
+}
+
+func (r *ReaderHandler) Chapter(id string, seq int) (*model.ReaderChapterContent, error) {
+	return readerTool.Chapter(id, seq)
+}
+
+func (r *ReaderHandler) State(id string) (model.ReadingState, error) {
+	return libraryTool.ReadingState(id)
+}
+
+func (r *ReaderHandler) SavePosition(id string, seq int, offset float64) error {
+	_, err := libraryTool.SavePosition(id, seq, offset)
+	return err
+}
+
+func (r *ReaderHandler) AddBookmark(id string, bookmark model.Bookmark) (model.Bookmark, error) {
+	return libraryTool.AddBookmark(id, bookmark)
+}
+
+func (r *ReaderHandler) DeleteBookmark(id, bookmarkID string) error {
+	return libraryTool.DeleteBookmark(id, bookmarkID)
+}
+
+// OpenOnline 在线阅读: 获取书籍信息与目录, 不下载章节
+func (r *ReaderHandler) OpenOnline(sr *model.SearchResult) (*model.OnlineBook, error) {
+	return r.online.Open(sr)
+}
+
+// ReadOnline 获取一章并在后台预取后续章节
+func (r *ReaderHandler) ReadOnline(sr *model.SearchResult, seq int) (*model.ReaderChapterContent, error) {
+	return r.online.Chapter(sr, seq)
+}
+
+// ClearOnlineCache 删除在线阅读缓存的全部章节
+func (r *ReaderHandler) ClearOnlineCache() error {
+	return r.online.ClearCache()
+}
+
+// OnlineCacheStats 在线阅读缓存统计
+func (r *ReaderHandler) OnlineCacheStats() model.CacheStats {
+	return r.online.CacheStats()
+}
@@ -0,0 +1,45 @@
+package functions
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"fy-novel/internal/config"
+	harTool "fy-novel/internal/tools/har"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Recorder 录制抓取请求, 用于反馈书源问题
+type Recorder struct {
+	log *logrus.Logger
+}
+
+func NewRecorder(l *logrus.Logger) *Recorder {
+	return &Recorder{log: l}
+}
+
+// RecordingDir 录制文件的保存目录
+func RecordingDir() string {
+	return filepath.Join(config.DataDir(), "recordings")
+}
+
+// Start 开始录制, 之后的搜索与下载请求都会记录
+func (r *Recorder) Start() error {
+	return harTool.Record(harTool.NewRecorder())
+}
+
+// Stop 结束录制并保存, 返回文件路径与请求数
+func (r *Recorder) Stop() (string, int, error) {
+	rec := harTool.Stop()
+	if rec == nil {
+		return "", 0, fmt.Errorf("not recording")
+	}
+	path := filepath.Join(RecordingDir(), "fy-novel-"+time.Now().Format("20060102-150405")+".har")
+	if err := rec.Save(path); err != nil {
+		return "", 0, err
+	}
+	r.log.Infof("saved %d requests to %s", rec.Len(), path)
+	return path, rec.Len(), nil
+}
@@ -0,0 +1,134 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/crawler"
+	"fy-novel/internal/model"
+	libraryTool "fy-novel/internal/tools/library"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 定时检查时扫描到期订阅的间隔
+const subscriptionTick = time.Minute
+
+type Subscriber struct {
+	log     *logrus.Logger
+	crawler crawler.Crawler
+	// 定时检查与手动检查不同时进行, 避免重复下载
+	mu sync.Mutex
+}
+
+func NewSubscriber(l *logrus.Logger) *Subscriber {
+	return &Subscriber{log: l, crawler: crawler.NewNovelCrawler(l)}
+}
+
+// Subscribe 订阅书籍并立即检查一次, 已订阅时只更新自动下载设置
+func (s *Subscriber) Subscribe(sr *model.SearchResult, autoDownload bool) (model.Subscription, error) {
+	id := libraryTool.ID(sr.Url)
+	if sub, err := libraryTool.GetSubscription(id); err == nil {
+		sub.AutoDownload = autoDownload
+		return libraryTool.SaveSubscription(sub)
+	} else if !errors.Is(err, libraryTool.ErrNotSubscribed) {
+		return sub, err
+	}
+
+	sub := model.Subscription{
+		ID:       id,
+		Book:     model.Book{URL: sr.Url, BookName: sr.BookName, Author: sr.Author},
+		SourceID: config.GetConf().Base.SourceID,
+	}
+	s.mu.Lock()
+	// 订阅时不自动下载, 只记录当前目录
+	update, err := s.crawler.CheckUpdate(sub)
+	s.mu.Unlock()
+	if err != nil {
+		return sub, err
+	}
+	sub = update.Subscription
+	sub.AutoDownload = autoDownload
+	return libraryTool.SaveSubscription(sub)
+}
+
+func (s *Subscriber) List() ([]model.Subscription, error) {
+	return libraryTool.Subscriptions()
+}
+
+func (s *Subscriber) Unsubscribe(id string) error {
+	return libraryTool.Unsubscribe(id)
+}
+
+func (s *Subscriber) SetAutoDownload(id string, autoDownload bool) error {
+	sub, err := libraryTool.GetSubscription(id)
+	if err != nil {
+		return err
+	}
+	sub.AutoDownload = autoDownload
+	_, err = libraryTool.SaveSubscription(sub)
+	return err
+}
+
+// Check 检查订阅, interval 大于 0 时只检查距上次检查超过该间隔的订阅.
+// 返回发现新章节或完成自动下载的结果
+func (s *Subscriber) Check(interval time.Duration) ([]model.SubscriptionUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs, err := libraryTool.Subscriptions()
+	if err != nil {
+		return nil, err
+	}
+	var updates []model.SubscriptionUpdate
+	now := time.Now()
+	for _, sub := range subs {
+		if interval > 0 && now.Sub(sub.CheckedAt) < interval {
+			continue
+		}
+		update, err := s.crawler.CheckUpdate(sub)
+		if err != nil {
+			s.log.Errorf("subscription %s check error: %v", sub.Book.BookName, err)
+			sub.CheckedAt = now
+			sub.LastError = err.Error()
+			update = &model.SubscriptionUpdate{Subscription: sub}
+		}
+		// 检查期间可能已取消订阅
+		if _, err := libraryTool.GetSubscription(sub.ID); err != nil {
+			continue
+		}
+		if _, err := libraryTool.SaveSubscription(update.Subscription); err != nil {
+			return updates, err
+		}
+		if len(update.NewTitles) > 0 || update.OutputPath != "" {
+			updates = append(updates, *update)
+		}
+	}
+	return updates, nil
+}
+
+// Run 按配置的间隔定时检查订阅, 直到 ctx 结束. 配置修改后下一轮即生效
+func (s *Subscriber) Run(ctx context.Context, notify func(model.SubscriptionUpdate)) {
+	ticker := time.NewTicker(subscriptionTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		interval := config.GetConf().GetCheckInterval()
+		if interval == 0 {
+			continue
+		}
+		updates, err := s.Check(interval)
+		if err != nil {
+			s.log.Errorf("subscription check error: %v", err)
+		}
+		for _, u := range updates {
+			notify(u)
+		}
+	}
+}
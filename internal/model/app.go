@@ -87,3 +87,107 @@ type GenerateAsciiImageResult struct {
 	Response string
 	ErrorMsg string
 }
+
+type ListDictEntriesResult struct {
+	Entries  []DictEntry
+	ErrorMsg string
+}
+
+type SaveDictEntryResult struct {
+	Entry    DictEntry
+	ErrorMsg string
+}
+
+type DryRunDictEntryResult struct {
+	Changes      []DictDryRunChange
+	ChapterCount int
+	MatchCount   int
+	Truncated    bool
+	ErrorMsg     string
+}
+
+type CheckCatalogResult struct {
+	Report   CatalogReport
+	ErrorMsg string
+}
+
+type RefetchChaptersResult struct {
+	Result   CrawlResult
+	ErrorMsg string
+}
+
+type ListLibraryResult struct {
+	Entries  []LibraryEntry
+	ErrorMsg string
+}
+
+type SubscribeResult struct {
+	Subscription Subscription
+	ErrorMsg     string
+}
+
+type ListSubscriptionsResult struct {
+	Subscriptions []Subscription
+	ErrorMsg      string
+}
+
+type CheckSubscriptionsResult struct {
+	Updates  []SubscriptionUpdate
+	ErrorMsg string
+}
+
+type FullTextSearchResult struct {
+	Hits []FullTextHit
+	// 结果超过上限, 只返回了前面的部分
+	Truncated bool
+	ErrorMsg  string
+}
+
+type BatchDownloadResult struct {
+	Report   BatchReport
+	ErrorMsg string
+}
+
+type ListReaderBooksResult struct {
+	Books    []ReaderBook
+	ErrorMsg string
+}
+
+type ListReaderChaptersResult struct {
+	Chapters []ReaderChapter
+	ErrorMsg string
+}
+
+type OpenOnlineBookResult struct {
+	Book     OnlineBook
+	ErrorMsg string
+}
+
+type ReadChapterResult struct {
+	Chapter  ReaderChapterContent
+	ErrorMsg string
+}
+
+type ReadingStateResult struct {
+	State    ReadingState
+	ErrorMsg string
+}
+
+type AddBookmarkResult struct {
+	Bookmark Bookmark
+	ErrorMsg string
+}
+
+type CacheStatsResult struct {
+	// 抓取页面缓存
+	HTTP CacheStats
+	// 在线阅读章节缓存
+	Chapters CacheStats
+}
+
+type StopRecordingResult struct {
+	// 录制的 HAR 文件
+	Path     string
+	Entries  int
+	ErrorMsg string
+}
@@ -0,0 +1,56 @@
+package model
+
+// BatchItem 书单中的一本书
+type BatchItem struct {
+	// 在书单文件中的行号 (JSON 为序号), 从 1 开始
+	Line   int    `json:"line"`
+	Name   string `json:"name"`
+	Author string `json:"author"`
+	// 为 0 时使用配置中的书源
+	SourceID int `json:"sourceId"`
+	// 下载的章节范围, 从 1 开始且包含两端, 为 0 时不限制
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// 批量下载中一本书的结果
+const (
+	BatchOK        = "ok"
+	BatchAmbiguous = "ambiguous"
+	BatchNotFound  = "not-found"
+	BatchFailed    = "failed"
+)
+
+// BatchItemResult 书单中一本书的处理结果
+type BatchItemResult struct {
+	Item   BatchItem `json:"item"`
+	Status string    `json:"status"`
+	// 匹配到的搜索结果
+	Book *SearchResult `json:"book,omitempty"`
+	// 无法确定是哪一本时的候选结果
+	Candidates []*SearchResult `json:"candidates,omitempty"`
+	OutputPath string          `json:"outputPath,omitempty"`
+	// 可疑章节数, 可在书库中重新获取
+	Suspects int    `json:"suspects"`
+	Error    string `json:"error,omitempty"`
+	TakeTime int64  `json:"takeTime"`
+}
+
+// BatchReport 批量下载的汇总报告
+type BatchReport struct {
+	Items     []BatchItemResult `json:"items"`
+	Succeeded int               `json:"succeeded"`
+	Ambiguous int               `json:"ambiguous"`
+	NotFound  int               `json:"notFound"`
+	Failed    int               `json:"failed"`
+	TakeTime  int64             `json:"takeTime"`
+	// 报告文件路径
+	ReportPath string `json:"reportPath"`
+}
+
+// BatchProgress 每完成一本书通知一次
+type BatchProgress struct {
+	Done   int             `json:"done"`
+	Total  int             `json:"total"`
+	Result BatchItemResult `json:"result"`
+}
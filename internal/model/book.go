@@ -14,3 +14,12 @@ type Book struct {
 	IsEnd         string `json:"isEnd"`
 	Catalog       string `json:"catalog"`
 }
+
+// BookDetails 书籍详情与目录, 不下载章节
+type BookDetails struct {
+	Book     Book          `json:"book"`
+	SourceID int           `json:"sourceId"`
+	Chapters []CatalogItem `json:"chapters"`
+	// 目录中缺失或重复的章节号
+	Catalog CatalogReport `json:"catalog"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// CacheStats 磁盘缓存统计, 命中次数从程序启动时开始计算
+type CacheStats struct {
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"`
+	MaxSize int64 `json:"maxSize"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
@@ -0,0 +1,27 @@
+package model
+
+// DictEntry represents a user-defined text replacement
+type DictEntry struct {
+	ID      string `json:"id"`
+	Pattern string `json:"pattern"`
+	Replace string `json:"replace"`
+	// Regex 为 true 时 Pattern 按正则表达式匹配, Replace 支持 $1 分组引用
+	Regex bool `json:"regex"`
+	// Book 限定书名, 为空表示不限
+	Book string `json:"book"`
+	// SourceID 限定书源, 0 表示不限
+	SourceID int    `json:"sourceId"`
+	Disabled bool   `json:"disabled"`
+	Comment  string `json:"comment"`
+}
+
+// DictDryRunChange represents a chapter a dictionary entry would change
+type DictDryRunChange struct {
+	BookName     string
+	Author       string
+	ChapterIndex int
+	ChapterTitle string
+	Count        int
+	Before       string
+	After        string
+}
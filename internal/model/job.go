@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// 下载任务状态
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// DownloadJobRequest 创建下载任务的参数
+type DownloadJobRequest struct {
+	URL string `json:"url"`
+	// 为 0 或空时使用配置中的书源与导出格式
+	SourceID int    `json:"sourceId,omitempty"`
+	Format   string `json:"format,omitempty"`
+	// 下载的章节范围, 从 1 开始且包含两端, 为 0 时不限制
+	Start int `json:"start,omitempty"`
+	End   int `json:"end,omitempty"`
+}
+
+// DownloadJob 后台下载任务
+type DownloadJob struct {
+	ID      string             `json:"id"`
+	Request DownloadJobRequest `json:"request"`
+	Status  string             `json:"status"`
+	// 已完成与总任务数 (章节数 + 合并)
+	Completed  int64        `json:"completed"`
+	Total      int64        `json:"total"`
+	Result     *CrawlResult `json:"result,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	FinishedAt *time.Time   `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// LibraryEntry 本地书库中的一本书
+type LibraryEntry struct {
+	ID       string `json:"id"`
+	Book     Book   `json:"book"`
+	SourceID int    `json:"sourceId"`
+	// 各格式最近一次导出的文件, 最新的在前
+	Outputs []LibraryOutput `json:"outputs"`
+	// 成功写入的章节数与目录章节数
+	ChapterCount int       `json:"chapterCount"`
+	CatalogCount int       `json:"catalogCount"`
+	DownloadedAt time.Time `json:"downloadedAt"`
+}
+
+// LibraryOutput 一次导出的文件
+type LibraryOutput struct {
+	Format    string    `json:"format"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CatalogItem 下载时的目录快照
+type CatalogItem struct {
+	ChapterNo int    `json:"chapterNo"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// ReaderBook 可在内置阅读器中阅读的书, 即有抓取日志的书, 导出前也可阅读
+type ReaderBook struct {
+	ID       string `json:"id"`
+	Book     Book   `json:"book"`
+	SourceID int    `json:"sourceId"`
+	// 已导出并记录在书库中
+	InLibrary bool `json:"inLibrary"`
+	// 最近一次抓取的时间
+	FetchedAt time.Time `json:"fetchedAt"`
+	// 阅读进度, 还没有读过时为空
+	Reading *ReadingState `json:"reading,omitempty"`
+}
+
+// ReaderChapter 阅读器目录中的一章, Seq 为章节在下载目录中的下标
+type ReaderChapter struct {
+	Seq       int    `json:"seq"`
+	ChapterNo int    `json:"chapterNo"`
+	Title     string `json:"title"`
+	// 已有正文 (抓取日志或在线阅读缓存中), 抓取失败或尚未抓取的章节为 false
+	Available bool `json:"available"`
+}
+
+// ReaderChapterContent 一章的正文, Content 为清理后只含段落与基本格式的 HTML
+type ReaderChapterContent struct {
+	BookID  string `json:"bookId"`
+	Seq     int    `json:"seq"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// 前后可阅读章节的 Seq, 没有时为 -1
+	Prev int `json:"prev"`
+	Next int `json:"next"`
+}
+
+// ReadingState 一本书的阅读进度与书签
+type ReadingState struct {
+	BookID string `json:"bookId"`
+	Seq    int    `json:"seq"`
+	// 章节内的滚动位置, 0 为开头, 1 为结尾
+	Offset    float64    `json:"offset"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Bookmarks []Bookmark `json:"bookmarks"`
+}
+
+// Bookmark 书签, Title 为所在章节的标题
+type Bookmark struct {
+	ID        string    `json:"id"`
+	Seq       int       `json:"seq"`
+	Offset    float64   `json:"offset"`
+	Title     string    `json:"title"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OnlineBook 在线阅读打开的书, 目录为书源中的完整目录, Available 表示章节已在缓存中
+type OnlineBook struct {
+	ID       string          `json:"id"`
+	Book     Book            `json:"book"`
+	SourceID int             `json:"sourceId"`
+	Chapters []ReaderChapter `json:"chapters"`
+	// 阅读进度, 与下载后阅读共用
+	Reading *ReadingState `json:"reading,omitempty"`
+}
@@ -11,6 +11,8 @@ type Rule struct {
 	Book    book    `json:"book"`
 	Chapter chapter `json:"chapter"`
 	Catalog catalog `json:"catalog"`
+	// RateLimit 对书源所在域名的请求限制, 未设置的项使用默认值
+	RateLimit rateLimit `json:"rateLimit"`
 }
 
 // Search represents the search rules
@@ -59,8 +61,22 @@ type chapter struct {
 	ChapterNo          int    `json:"chapterNo"`
 	Title              string `json:"title"`
 	Content            string `json:"content"`
-	ParagraphTagClosed bool   `json:"paragraphTagClosed"`
-	ParagraphTag       string `json:"paragraphTag"`
+	ParagraphTagClosed bool   `json:"paragraphTagClosed"` // 已弃用, 段落由 DOM 结构自动识别
+	ParagraphTag       string `json:"paragraphTag"`       // 已弃用, 同上
 	FilterTxt          string `json:"filterTxt"`
 	FilterTag          string `json:"filterTag"`
+	// DisableFilters 对该书源关闭的清洗阶段, 例如 ["watermark"]
+	DisableFilters []string `json:"disableFilters"`
+}
+
+// RateLimit 同一域名的全部采集器共用, 被限流时自动降速, 之后逐步恢复
+type rateLimit struct {
+	// 每秒请求数
+	RPS float64 `json:"rps"`
+	// 空闲后允许连续发出的请求数
+	Burst int `json:"burst"`
+	// 同时进行的请求数上限, 同时限制下载线程数, 0 不限制
+	Concurrency int `json:"concurrency"`
+	// 书源返回 200 但内容为限流提示时, 页面包含的文字
+	ThrottleText []string `json:"throttleText"`
 }
@@ -12,4 +12,77 @@ type SearchResult struct {
 type CrawlResult struct {
 	OutputPath string
 	TakeTime   int64
+	// 去重删除的段落, 每章每种类型一条
+	Dedup []DedupReport
+	// 质量检查未通过的章节, 可以稍后重新获取或换书源获取
+	Suspects []SuspectChapter
+	// 目录中缺失或重复的章节号
+	Catalog CatalogReport
+}
+
+// DedupReport 一章中因重复被删除的段落
+type DedupReport struct {
+	ChapterNo    int
+	ChapterTitle string
+	// repeat: 章内重复; overlap: 与上一章末尾重叠
+	Kind       string
+	Paragraphs []string
+}
+
+// SuspectChapter 可能为空、被截断或只有占位文字的章节
+type SuspectChapter struct {
+	// 章节在目录中的下标, 重新获取时使用
+	Seq       int
+	ChapterNo int
+	Title     string
+	URL       string
+	Length    int
+	// 分数越高越可疑
+	Score int
+	// fetch-failed, empty, short, placeholder, garbage, no-paragraphs
+	Reasons []string
+}
+
+// CatalogReport 目录章节号检查结果
+type CatalogReport struct {
+	Total int
+	// 能识别出章节号的章节数
+	Numbered   int
+	Gaps       []CatalogGap
+	Duplicates []CatalogDuplicate
+}
+
+// CatalogGap 缺失的章节号范围 [From, To]
+type CatalogGap struct {
+	Volume string
+	From   int
+	To     int
+}
+
+// CatalogDuplicate 同一章节号对应多个标题
+type CatalogDuplicate struct {
+	Volume string
+	Number int
+	Titles []string
+}
+
+// FullTextHit 全文搜索命中的章节
+type FullTextHit struct {
+	BookID       string `json:"bookId"`
+	BookName     string `json:"bookName"`
+	Author       string `json:"author"`
+	BookURL      string `json:"bookUrl"`
+	Seq          int    `json:"seq"`
+	ChapterNo    int    `json:"chapterNo"`
+	ChapterTitle string `json:"chapterTitle"`
+	// 命中文字前后的一段原文
+	Snippet string `json:"snippet"`
+	// 命中文字在 Snippet 中的位置, 按字符计
+	Highlights []TextRange `json:"highlights"`
+}
+
+// TextRange 文字范围 [Start, End)
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
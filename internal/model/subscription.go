@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Subscription 追更订阅的书籍
+type Subscription struct {
+	// 与书库 ID 相同
+	ID       string `json:"id"`
+	Book     Book   `json:"book"`
+	SourceID int    `json:"sourceId"`
+	// 发现新章节后自动下载
+	AutoDownload bool `json:"autoDownload"`
+	// 最近一次检查时的目录章节数与最新章节
+	ChapterCount  int    `json:"chapterCount"`
+	LatestChapter string `json:"latestChapter"`
+	// 书库中没有目录快照时, 订阅时的章节数之后的章节视为新章节
+	Baseline int `json:"baseline"`
+	// 尚未下载的新章节数
+	NewChapters int       `json:"newChapters"`
+	CheckedAt   time.Time `json:"checkedAt"`
+	// 最近一次发现新章节的时间
+	UpdatedAt time.Time `json:"updatedAt"`
+	// 最近一次检查或自动下载失败的原因
+	LastError string `json:"lastError"`
+}
+
+// SubscriptionUpdate 一次检查发现的新章节, 通过事件通知界面
+type SubscriptionUpdate struct {
+	Subscription Subscription `json:"subscription"`
+	// 本次检查新出现的章节标题
+	NewTitles []string `json:"newTitles"`
+	// 自动下载后生成的文件, 未下载时为空
+	OutputPath string `json:"outputPath"`
+}
@@ -4,6 +4,7 @@ import (
 	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 	"fy-novel/internal/source"
+	httpcacheTool "fy-novel/internal/tools/httpcache"
 	"fy-novel/pkg/utils"
 
 	"github.com/gocolly/colly/v2"
@@ -23,8 +24,8 @@ func NewBookParser(conf config.Info) *BookParser {
 }
 
 func (b *BookParser) Parse(bookUrl string) (*model.Book, error) {
-	book := &model.Book{}
-	collector := getCollector(nil, b.conf.Retry.MaxAttempts, b.conf.GetRandomDelay())
+	book := &model.Book{URL: bookUrl}
+	collector := getCollector(b.conf, httpcacheTool.PageBook, nil)
 	// 抓取书名
 	collector.OnHTML(b.rule.Book.BookName, func(e *colly.HTMLElement) {
 		bookName := e.Attr("content")
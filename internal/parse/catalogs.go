@@ -3,10 +3,12 @@ package parse
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 	"fy-novel/internal/source"
+	httpcacheTool "fy-novel/internal/tools/httpcache"
 	"fy-novel/pkg/utils"
 
 	"github.com/gocolly/colly/v2"
@@ -26,7 +28,7 @@ func NewCatalogsParser(conf config.Info) *CatalogsParser {
 }
 
 func (b *CatalogsParser) Parse(bookUrl string, start, end int) ([]*model.Chapter, error) {
-	collector := getCollector(nil, b.conf.Retry.MaxAttempts, b.conf.GetRandomDelay())
+	collector := getCollector(b.conf, httpcacheTool.PageCatalog, nil)
 
 	var chapters = make(map[string]*model.Chapter)
 
@@ -35,13 +37,17 @@ func (b *CatalogsParser) Parse(bookUrl string, start, end int) ([]*model.Chapter
 		bookUrl = fmt.Sprintf(b.rule.Catalog.URL, id)
 	}
 
+	// Keyed by url: chapters sharing a title are kept, so the catalog check can report them.
+	// A link listed twice (e.g. a "latest chapters" block) keeps its last position.
+	position := 0
 	collector.OnHTML(b.rule.Catalog.Result, func(e *colly.HTMLElement) {
+		position++
 		chapter := &model.Chapter{
-			Title: e.Text,
-			URL:   utils.NormalizeURL(e.Attr("href"), b.rule.URL),
+			Title:     strings.TrimSpace(e.Text),
+			URL:       utils.NormalizeURL(e.Attr("href"), b.rule.URL),
+			ChapterNo: position,
 		}
-		chapter.ChapterNo = len(chapters) + 1
-		chapters[chapter.Title] = chapter
+		chapters[chapter.URL] = chapter
 	})
 
 	err := collector.Visit(bookUrl)
@@ -58,5 +64,14 @@ func (b *CatalogsParser) Parse(bookUrl string, start, end int) ([]*model.Chapter
 	sort.Slice(res, func(i, j int) bool {
 		return res[i].ChapterNo < res[j].ChapterNo
 	})
-	return res, nil
+	for i, chapter := range res {
+		chapter.ChapterNo = i + 1
+	}
+	// start and end are 1-based and inclusive, ChapterNo keeps the position in the whole catalog
+	start = max(start, 1)
+	end = min(end, len(res))
+	if start > end {
+		return nil, nil
+	}
+	return res[start-1 : end], nil
 }
@@ -3,12 +3,14 @@ package parse
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"strings"
 
 	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 	"fy-novel/internal/source"
 	chapterTool "fy-novel/internal/tools/chapter"
+	httpcacheTool "fy-novel/internal/tools/httpcache"
 	"fy-novel/pkg/utils"
 	"github.com/gocolly/colly/v2"
 )
@@ -29,7 +31,6 @@ func (b *ChapterParser) Parse(
 	chapter *model.Chapter,
 	res *model.SearchResult,
 	book *model.Book,
-	bookDir string,
 ) (err error) {
 	// Prevent duplicate fetching
 	chapter.Content, err = b.crawl(chapter.URL)
@@ -37,11 +38,9 @@ func (b *ChapterParser) Parse(
 		// Attempt retry
 		return err
 	}
-	err = chapterTool.ConvertChapter(chapter, b.conf.Base.Extname, b.rule)
-	if err != nil {
-		return err
-	}
-	return nil
+	// Clean the content, format conversion is left to the caller
+	chapter.Content, err = chapterTool.CleanChapter(chapter, b.conf, b.rule)
+	return err
 }
 
 func (b *ChapterParser) crawl(url string) (string, error) {
@@ -49,14 +48,14 @@ func (b *ChapterParser) crawl(url string) (string, error) {
 	sb := bytes.NewBufferString("")
 
 	for {
-		collector := getCollector(nil, b.conf.Retry.MaxAttempts, b.conf.GetRandomDelay())
+		collector := getCollector(b.conf, httpcacheTool.PageChapter, nil)
 		collector.OnHTML(b.rule.Chapter.Content, func(e *colly.HTMLElement) {
 			html, err := e.DOM.Html()
 			if err == nil {
 				sb.WriteString(html)
 			} else {
 				// Print error
-				fmt.Printf("ChapterParser crawl Error parsing HTML: %v\n", err)
+				fmt.Fprintf(os.Stderr, "ChapterParser crawl Error parsing HTML: %v\n", err)
 			}
 		})
 		if !b.rule.Chapter.Pagination {
@@ -1,10 +1,19 @@
 package parse
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
 	"sync"
 	"time"
 
+	"fy-novel/internal/config"
+	"fy-novel/internal/source"
+	harTool "fy-novel/internal/tools/har"
+	httpcacheTool "fy-novel/internal/tools/httpcache"
+	ratelimitTool "fy-novel/internal/tools/ratelimit"
+
 	"github.com/gocolly/colly/v2"
 	// "github.com/gocolly/colly/v2/debug"
 	"github.com/gocolly/colly/v2/extensions"
@@ -18,11 +27,13 @@ var urlLock sync.Mutex
 
 var saveErrorUrl = make(map[string]int)
 
+// getCollector 创建采集器, page 决定页面在抓取缓存中的保存时间
 func getCollector(
+	conf config.Info,
+	page httpcacheTool.Page,
 	cookies map[string]string,
-	retry int,
-	randomDelay time.Duration,
 ) *colly.Collector {
+	retry := conf.Retry.MaxAttempts
 	c := colly.NewCollector(
 		colly.Async(true),
 		// Attach a debugger to the collector
@@ -30,15 +41,14 @@ func getCollector(
 	)
 	extensions.RandomUserAgent(c)
 	c.SetRequestTimeout(timeoutMillis * time.Millisecond)
-	limitRule := &colly.LimitRule{
+	c.Limit(&colly.LimitRule{
 		DomainGlob:  "*",
 		Parallelism: 1,
-		// RandomDelay: 600 * time.Millisecond,
-	}
-	if randomDelay != 0 {
-		limitRule.RandomDelay = randomDelay
-	}
-	c.Limit(limitRule)
+	})
+	// 频率限制放在缓存之后, 命中缓存的请求不等待
+	transport := ratelimitTool.NewTransport(source.GetRuleBySourceID(conf.Base.SourceID), http.DefaultTransport)
+	// 录制时记录解析器拿到的页面 (含命中缓存的), 回放时不经过缓存
+	c.WithTransport(harTool.Wrap(httpcacheTool.NewTransport(conf, page, transport)))
 
 	if retry == 0 {
 		retry = retryDefault
@@ -48,6 +58,11 @@ func getCollector(
 	c.OnError(func(r *colly.Response, err error) {
 		// 加入一个自动重试机制
 		link := r.Request.URL.String()
+		// 回放文件中没有的请求重试也不会有
+		if errors.Is(err, harTool.ErrNotRecorded) {
+			fmt.Fprintf(os.Stderr, "\nReplay Request URL: %s, Error: %v", link, err)
+			return
+		}
 		urlLock.Lock()
 		time.Sleep(sleepSecond * time.Duration(retry))
 		if _, ok := saveErrorUrl[link]; !ok {
@@ -57,7 +72,7 @@ func getCollector(
 			saveErrorUrl[link]++
 			r.Request.Retry()
 		} else {
-			fmt.Printf("\nRetry %d Request URL: %s, Error: %v", saveErrorUrl[link], link, err)
+			fmt.Fprintf(os.Stderr, "\nRetry %d Request URL: %s, Error: %v", saveErrorUrl[link], link, err)
 		}
 		urlLock.Unlock()
 	})
@@ -0,0 +1,41 @@
+package parse
+
+import (
+	"testing"
+
+	"fy-novel/internal/config"
+	harTool "fy-novel/internal/tools/har"
+)
+
+// replay 以 testdata 中录制的 HAR 文件回答本测试的请求.
+// 用户反馈的录制文件放入 testdata 即可写成回归测试
+func replay(t *testing.T, name string) {
+	t.Helper()
+	archive, err := harTool.Load("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := harTool.Replay(harTool.NewReplayer(archive)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { harTool.Stop() })
+}
+
+func TestBookParserReplay(t *testing.T) {
+	replay(t, "book1.har")
+	var conf config.Info
+	conf.Base.SourceID = 1
+	book, err := NewBookParser(conf).Parse("http://www.mcmssc.la/1_1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.BookName != "剑来" || book.Author != "烽火戏诸侯" {
+		t.Errorf("book = %+v", book)
+	}
+	if book.Intro != "少年持剑，行走江湖。" {
+		t.Errorf("intro = %q", book.Intro)
+	}
+	if book.CoverURL != "http://www.mcmssc.la/files/article/image/1/1/1s.jpg" {
+		t.Errorf("cover = %q", book.CoverURL)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 	"fy-novel/internal/source"
+	httpcacheTool "fy-novel/internal/tools/httpcache"
 	"fy-novel/pkg/utils"
 
 	"github.com/gocolly/colly/v2"
@@ -30,11 +31,7 @@ func (p *SearchResultParser) Parse(keyword string) ([]*model.SearchResult, error
 	search := p.rule.Search
 	isPaging := search.Pagination
 
-	collector := getCollector(
-		p.rule.Search.Cookies,
-		p.conf.Retry.MaxAttempts,
-		p.conf.GetRandomDelay(),
-	)
+	collector := getCollector(p.conf, httpcacheTool.PageSearch, p.rule.Search.Cookies)
 
 	urls := make(map[string]struct{})
 
@@ -73,7 +70,7 @@ func (p *SearchResultParser) Parse(keyword string) ([]*model.SearchResult, error
 	errorChan := make(chan error, len(urls))
 	semaphore := make(
 		chan struct{},
-		p.conf.GetConcurrencyNum(),
+		source.ConcurrencyNum(p.conf),
 	) // Limit concurrency to 20
 
 	for url := range urls {
@@ -117,11 +114,7 @@ func (p *SearchResultParser) getSearchResults(
 	keyword string,
 ) ([]*model.SearchResult, error) {
 	if collector == nil {
-		collector = getCollector(
-			p.rule.Search.Cookies,
-			p.conf.Retry.MaxAttempts,
-			p.conf.GetRandomDelay(),
-		)
+		collector = getCollector(p.conf, httpcacheTool.PageSearch, p.rule.Search.Cookies)
 	}
 	var results []*model.SearchResult
 	collector.OnHTML(p.rule.Search.Result, func(e *colly.HTMLElement) {
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/functions"
+	"fy-novel/internal/model"
+	libraryTool "fy-novel/internal/tools/library"
+)
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+	res, err := s.downloader.Serach(q)
+	if err != nil {
+		s.log.Errorf("server search error: %v", err)
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if res == nil {
+		res = []*model.SearchResult{}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
+	url := strings.TrimSpace(r.URL.Query().Get("url"))
+	if url == "" {
+		writeError(w, http.StatusBadRequest, "missing query parameter url")
+		return
+	}
+	details, err := s.downloader.Details(&model.SearchResult{Url: url})
+	if err != nil {
+		s.log.Errorf("server book error: %v", err)
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.jobs.List())
+}
+
+func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	var req model.DownloadJobRequest
+	if err := decodeBody(r, &req); errors.Is(err, errNotJSON) {
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	job, err := s.jobs.Create(req)
+	switch {
+	case errors.Is(err, functions.ErrJobConflict):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		w.Header().Set("Location", "/api/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.jobs.Get(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleCancelJob 取消任务, 任务会在正在获取的章节完成后结束
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.jobs.Cancel(r.PathValue("id"))
+	switch {
+	case errors.Is(err, functions.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, functions.ErrJobFinished):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func (s *Server) handleLibrary(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.library.Search(r.URL.Query().Get("q"))
+	if err != nil {
+		s.log.Errorf("server library error: %v", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []model.LibraryEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleDeleteLibrary files=true 时同时删除导出的文件
+func (s *Server) handleDeleteLibrary(w http.ResponseWriter, r *http.Request) {
+	removeFiles, _ := strconv.ParseBool(r.URL.Query().Get("files"))
+	err := s.library.Delete(r.PathValue("id"), removeFiles)
+	switch {
+	case errors.Is(err, libraryTool.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		s.log.Errorf("server library delete error: %v", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.conf.GetConfig())
+}
+
+// handleSetConfig 请求体与图形界面的 SetConfig 相同, 只需包含要修改的项, 返回修改后的配置
+func (s *Server) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+	if !isJSON(r) {
+		writeError(w, http.StatusUnsupportedMediaType, errNotJSON.Error())
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var patch config.Info
+	if err := json.Unmarshal(body, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid config: "+err.Error())
+		return
+	}
+	if err := s.conf.SetConfig(string(body)); err != nil {
+		s.log.Errorf("server set config error: %v", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.conf.GetConfig())
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.getHint.GetUsageInfo())
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	res := s.checkUpdater.CheckUpdate()
+	if res.ErrorMsg != "" {
+		writeError(w, http.StatusBadGateway, res.ErrorMsg)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// errNotJSON 请求体不是 JSON. 要求 Content-Type 可使跨站表单无法直接提交
+var errNotJSON = errors.New("content type must be application/json")
+
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// decodeBody 解析 JSON 请求体, 不允许未知字段
+func decodeBody(r *http.Request, v any) error {
+	if !isJSON(r) {
+		return errNotJSON
+	}
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errors.New("invalid request body: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fy-novel/internal/definition"
+	epubTool "fy-novel/internal/tools/epub"
+	opdsTool "fy-novel/internal/tools/opds"
+)
+
+const opdsBase = "/opds"
+
+var opdsCatalog = opdsTool.Catalog{Base: opdsBase, Title: "fy-novel"}
+
+func (s *Server) opdsRoutes() {
+	s.mux.HandleFunc("GET "+opdsBase, s.auth(s.handleOPDSRoot))
+	s.mux.HandleFunc("GET "+opdsBase+"/{$}", s.auth(s.handleOPDSRoot))
+	s.mux.HandleFunc("GET "+opdsBase+"/opensearch.xml", s.auth(s.handleOPDSOpenSearch))
+	s.mux.HandleFunc("GET "+opdsBase+"/recent", s.auth(s.handleOPDSRecent))
+	s.mux.HandleFunc("GET "+opdsBase+"/books", s.auth(s.handleOPDSBooks))
+	s.mux.HandleFunc("GET "+opdsBase+"/authors", s.auth(s.handleOPDSAuthors))
+	s.mux.HandleFunc("GET "+opdsBase+"/authors/{author}", s.auth(s.handleOPDSAuthor))
+	s.mux.HandleFunc("GET "+opdsBase+"/search", s.auth(s.handleOPDSSearch))
+	s.mux.HandleFunc("GET "+opdsBase+"/books/{id}/cover", s.auth(s.handleOPDSCover))
+	s.mux.HandleFunc("GET "+opdsBase+"/books/{id}/{format}", s.auth(s.handleOPDSFile))
+}
+
+// shelf 每次请求重新读取书库与下载目录, 新下载的书立即可见
+func (s *Server) shelf(w http.ResponseWriter) ([]opdsTool.Book, bool) {
+	books, err := s.library.Shelf()
+	if err != nil {
+		s.log.Errorf("server opds error: %v", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return books, true
+}
+
+func (s *Server) writeFeed(w http.ResponseWriter, feed *opdsTool.Feed, kind string) {
+	data, err := feed.Marshal()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", kind)
+	w.Write(data)
+}
+
+func (s *Server) handleOPDSRoot(w http.ResponseWriter, r *http.Request) {
+	if books, ok := s.shelf(w); ok {
+		s.writeFeed(w, opdsCatalog.Root(books), opdsTool.NavigationType)
+	}
+}
+
+func (s *Server) handleOPDSOpenSearch(w http.ResponseWriter, r *http.Request) {
+	data, err := opdsCatalog.OpenSearch()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", opdsTool.OpenSearchType)
+	w.Write(data)
+}
+
+func (s *Server) handleOPDSRecent(w http.ResponseWriter, r *http.Request) {
+	if books, ok := s.shelf(w); ok {
+		books = books[:min(len(books), opdsTool.RecentLimit)]
+		s.writeFeed(w, opdsCatalog.Books("recent", "最近添加", r.URL.Path, books), opdsTool.AcquisitionType)
+	}
+}
+
+func (s *Server) handleOPDSBooks(w http.ResponseWriter, r *http.Request) {
+	if books, ok := s.shelf(w); ok {
+		s.writeFeed(w, opdsCatalog.Books("books", "全部书籍", r.URL.Path, books), opdsTool.AcquisitionType)
+	}
+}
+
+func (s *Server) handleOPDSAuthors(w http.ResponseWriter, r *http.Request) {
+	if books, ok := s.shelf(w); ok {
+		s.writeFeed(w, opdsCatalog.AuthorList(books), opdsTool.NavigationType)
+	}
+}
+
+func (s *Server) handleOPDSAuthor(w http.ResponseWriter, r *http.Request) {
+	books, ok := s.shelf(w)
+	if !ok {
+		return
+	}
+	author := r.PathValue("author")
+	books = opdsTool.Authors(books)[author]
+	if len(books) == 0 {
+		writeError(w, http.StatusNotFound, "author not found")
+		return
+	}
+	feed := opdsCatalog.Books("author:"+url.PathEscape(author), author, opdsCatalog.AuthorURL(author), books)
+	s.writeFeed(w, feed, opdsTool.AcquisitionType)
+}
+
+func (s *Server) handleOPDSSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+	if books, ok := s.shelf(w); ok {
+		feed := opdsCatalog.Books("search:"+url.QueryEscape(q), "搜索: "+q, opdsCatalog.SearchURL(q), opdsTool.Search(books, q))
+		s.writeFeed(w, feed, opdsTool.AcquisitionType)
+	}
+}
+
+// handleOPDSCover 优先使用 EPUB 中的封面, 否则跳转到书源的封面地址
+func (s *Server) handleOPDSCover(w http.ResponseWriter, r *http.Request) {
+	books, ok := s.shelf(w)
+	if !ok {
+		return
+	}
+	book, found := opdsTool.Find(books, r.PathValue("id"))
+	if !found {
+		writeError(w, http.StatusNotFound, "book not found")
+		return
+	}
+	if file, ok := book.File(definition.NovelExtname_EPUB); ok && book.EmbeddedCover {
+		data, mediaType, err := epubTool.Cover(file.Path)
+		if err == nil {
+			w.Header().Set("Content-Type", mediaType)
+			w.Write(data)
+			return
+		}
+		if !errors.Is(err, epubTool.ErrNoCover) {
+			s.log.Errorf("server opds cover error: %v", err)
+		}
+	}
+	if book.CoverURL != "" {
+		http.Redirect(w, r, book.CoverURL, http.StatusFound)
+		return
+	}
+	writeError(w, http.StatusNotFound, "book has no cover")
+}
+
+func (s *Server) handleOPDSFile(w http.ResponseWriter, r *http.Request) {
+	books, ok := s.shelf(w)
+	if !ok {
+		return
+	}
+	book, found := opdsTool.Find(books, r.PathValue("id"))
+	if !found {
+		writeError(w, http.StatusNotFound, "book not found")
+		return
+	}
+	file, found := book.File(r.PathValue("format"))
+	if !found {
+		writeError(w, http.StatusNotFound, "format not available")
+		return
+	}
+	f, err := os.Open(file.Path)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := book.Title + filepath.Ext(file.Path)
+	w.Header().Set("Content-Type", opdsTool.MediaType(file.Format))
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(name))
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
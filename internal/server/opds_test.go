@@ -0,0 +1,94 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/config"
+	epubTool "fy-novel/internal/tools/epub"
+	opdsTool "fy-novel/internal/tools/opds"
+)
+
+func get(t *testing.T, url string, auth func(*http.Request)) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	if auth != nil {
+		auth(req)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestOPDS(t *testing.T) {
+	ts := newTestServer(t, "secret")
+	dir := t.TempDir()
+	conf := config.GetConf()
+	conf.Base.DownloadPath = dir
+	config.Override(conf)
+
+	os.WriteFile(filepath.Join(dir, "剑来（烽火戏诸侯）.txt"), []byte("正文"), 0644)
+	f, _ := os.Create(filepath.Join(dir, "雪中悍刀行.epub"))
+	w, _ := epubTool.NewWriter(f, epubTool.Metadata{Title: "雪中悍刀行", Author: "烽火戏诸侯"}, epubTool.Style{})
+	w.SetCover([]byte("cover image"), "cover.jpg")
+	w.AddChapter("第一章", "<p>正文</p>")
+	w.Close()
+	f.Close()
+
+	basic := func(r *http.Request) { r.SetBasicAuth("reader", "secret") }
+	resp, _ := get(t, ts.URL+"/opds", nil)
+	if resp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Basic") {
+		t.Errorf("no auth: %d %q", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+	resp, body := get(t, ts.URL+"/opds", basic)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != opdsTool.NavigationType || !strings.Contains(body, "共 2 本书") {
+		t.Fatalf("root: %d %s", resp.StatusCode, body)
+	}
+
+	resp, body = get(t, ts.URL+"/opds/authors/"+"烽火戏诸侯", basic)
+	if resp.StatusCode != http.StatusOK || strings.Count(body, "<entry>") != 2 {
+		t.Errorf("author: %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := get(t, ts.URL+"/opds/authors/nobody", basic); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown author: %d", resp.StatusCode)
+	}
+	_, body = get(t, ts.URL+"/opds/search?q=剑", basic)
+	if strings.Count(body, "<entry>") != 1 {
+		t.Errorf("search: %s", body)
+	}
+
+	books, err := opdsTool.Load(dir)
+	if err != nil || len(books) != 2 {
+		t.Fatalf("books: %v %v", books, err)
+	}
+	for _, b := range books {
+		format := b.Files[0].Format
+		resp, body := get(t, ts.URL+opdsCatalog.FileURL(b.ID, format), basic)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != opdsTool.MediaType(format) || len(body) != int(b.Files[0].Size) {
+			t.Errorf("download %s: %d %q", b.Title, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		resp, body = get(t, ts.URL+opdsCatalog.CoverURL(b.ID), basic)
+		switch format {
+		case "epub":
+			if resp.StatusCode != http.StatusOK || body != "cover image" || resp.Header.Get("Content-Type") != "image/jpeg" {
+				t.Errorf("epub cover: %d %q", resp.StatusCode, body)
+			}
+		default:
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("txt cover: %d", resp.StatusCode)
+			}
+		}
+		if resp, _ := get(t, ts.URL+opdsCatalog.FileURL(b.ID, "pdf"), basic); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("missing format: %d", resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package server 本地 HTTP 服务, 以 JSON REST API 提供搜索、书籍详情、下载任务、书库与配置,
+// 与图形界面共用 functions 中的实现. 接口说明见 /api/openapi.json.
+// 另在 /opds 提供 OPDS 1.2 目录, 供阅读器浏览与下载已下载的书籍,
+// 并可在根路径提供与桌面版相同的网页界面 (见 web.go)
+package server
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"fy-novel/internal/functions"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// 请求体大小上限
+const maxBodySize = 1 << 20
+
+// Options 服务参数
+type Options struct {
+	// 为空时不校验, 否则请求需带 Authorization: Bearer <token>
+	Token string
+	// 前端构建产物目录 (frontend/dist), 为空时不提供网页界面
+	WebDir string
+	// 监听地址, 没有 token 时只接受 Host 为该地址、localhost 或 IP 的请求, 防止 DNS rebinding
+	Addr string
+}
+
+type Server struct {
+	log          *logrus.Logger
+	token        string
+	addr         string
+	webDir       string
+	mux          *http.ServeMux
+	downloader   *functions.Downloader
+	jobs         *functions.DownloadJobs
+	library      *functions.LibraryHandler
+	conf         *functions.ConfHandler
+	getHint      *functions.GetHint
+	checkUpdater *functions.CheckUpdater
+}
+
+func New(l *logrus.Logger, opts Options) *Server {
+	s := &Server{
+		log:          l,
+		token:        opts.Token,
+		addr:         opts.Addr,
+		webDir:       opts.WebDir,
+		mux:          http.NewServeMux(),
+		downloader:   functions.NewDownload(l),
+		jobs:         functions.NewDownloadJobs(l),
+		library:      functions.NewLibraryHandler(l),
+		conf:         functions.NewGetConf(l),
+		getHint:      functions.NewGetHint(l),
+		checkUpdater: functions.NewCheckUpdate(l, 5000),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("GET /api/search", s.auth(s.handleSearch))
+	s.mux.HandleFunc("GET /api/book", s.auth(s.handleBook))
+	s.mux.HandleFunc("GET /api/jobs", s.auth(s.handleListJobs))
+	s.mux.HandleFunc("POST /api/jobs", s.auth(s.handleCreateJob))
+	s.mux.HandleFunc("GET /api/jobs/{id}", s.auth(s.handleGetJob))
+	s.mux.HandleFunc("DELETE /api/jobs/{id}", s.auth(s.handleCancelJob))
+	s.mux.HandleFunc("GET /api/library", s.auth(s.handleLibrary))
+	s.mux.HandleFunc("DELETE /api/library/{id}", s.auth(s.handleDeleteLibrary))
+	s.mux.HandleFunc("GET /api/config", s.auth(s.handleGetConfig))
+	s.mux.HandleFunc("PUT /api/config", s.auth(s.handleSetConfig))
+	s.mux.HandleFunc("GET /api/info", s.auth(s.handleInfo))
+	s.mux.HandleFunc("GET /api/update", s.auth(s.handleUpdate))
+	s.opdsRoutes()
+	s.webRoutes()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		writeError(w, http.StatusForbidden, "cross-site request rejected")
+		return
+	}
+	if s.token == "" && !s.allowedHost(r.Host) {
+		writeError(w, http.StatusForbidden, "unknown host "+r.Host)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// sameOrigin 拒绝其他网站的页面发来的请求. 浏览器会自动带上 Basic 认证信息,
+// 有 token 时同样需要检查; 非浏览器客户端一般不带 Origin
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// allowedHost 没有 token 时, 恶意网站可以把自己的域名解析到 127.0.0.1 后访问本服务,
+// 因此只接受监听地址的主机名、localhost 与 IP, 端口需与监听地址一致
+func (s *Server) allowedHost(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, "80"
+	}
+	if s.addr != "" {
+		addrHost, addrPort, err := net.SplitHostPort(s.addr)
+		if err != nil || port != addrPort {
+			return false
+		}
+		if addrHost != "" && strings.EqualFold(host, addrHost) {
+			return true
+		}
+	}
+	return strings.EqualFold(host, "localhost") || net.ParseIP(strings.Trim(host, "[]")) != nil
+}
+
+// Close 取消未结束的下载任务
+func (s *Server) Close() {
+	s.jobs.CancelAll()
+}
+
+// auth 配置了 token 时校验 Bearer token, 说明文档不需要校验.
+// 阅读器与浏览器一般只支持 Basic 认证, 因此也接受密码为 token 的 Basic 认证 (用户名任意),
+// 网页界面以 Basic 认证打开后, 浏览器会为其 API 请求带上同样的认证信息
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				_, token, ok = r.BasicAuth()
+			}
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				if strings.HasPrefix(r.URL.Path, "/api/") {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="fy-novel"`)
+				} else {
+					w.Header().Set("WWW-Authenticate", `Basic realm="fy-novel", charset="UTF-8"`)
+				}
+				writeError(w, http.StatusUnauthorized, "invalid or missing token")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// writeError 错误统一返回 {"error": "..."}
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
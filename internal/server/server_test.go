@@ -0,0 +1,275 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestServer(t *testing.T, token string) *httptest.Server {
+	return newTestServerWith(t, Options{Token: token})
+}
+
+func newTestServerWith(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	saved := config.GetConf()
+	t.Cleanup(func() { config.Override(saved) })
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	ts := httptest.NewUnstartedServer(nil)
+	opts.Addr = ts.Listener.Addr().String()
+	s := New(log, opts)
+	ts.Config.Handler = s
+	ts.Start()
+	t.Cleanup(func() {
+		s.Close()
+		ts.Close()
+	})
+	return ts
+}
+
+func do(t *testing.T, method, url, token, body string, v any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestOpenAPI(t *testing.T) {
+	ts := newTestServer(t, "secret")
+	var spec struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	// 说明文档不需要 token
+	if code := do(t, "GET", ts.URL+"/api/openapi.json", "", "", &spec); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	routes := map[string][]string{
+		"/api/search":       {"get"},
+		"/api/book":         {"get"},
+		"/api/jobs":         {"get", "post"},
+		"/api/jobs/{id}":    {"get", "delete"},
+		"/api/library":      {"get"},
+		"/api/library/{id}": {"delete"},
+		"/api/config":       {"get", "put"},
+		"/api/info":         {"get"},
+		"/api/update":       {"get"},
+	}
+	for path, methods := range routes {
+		for _, m := range methods {
+			if _, ok := spec.Paths[path][m]; !ok {
+				t.Errorf("spec is missing %s %s", m, path)
+			}
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	ts := newTestServer(t, "secret")
+	var e map[string]string
+	if code := do(t, "GET", ts.URL+"/api/jobs", "", "", &e); code != http.StatusUnauthorized || e["error"] == "" {
+		t.Errorf("no token: %d %v", code, e)
+	}
+	if code := do(t, "GET", ts.URL+"/api/jobs", "wrong", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: %d", code)
+	}
+	var jobs []model.DownloadJob
+	if code := do(t, "GET", ts.URL+"/api/jobs", "secret", "", &jobs); code != http.StatusOK || len(jobs) != 0 {
+		t.Errorf("token: %d %v", code, jobs)
+	}
+}
+
+func TestRequestGuards(t *testing.T) {
+	ts := newTestServer(t, "")
+	request := func(method, path, body string, header map[string]string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		for k, v := range header {
+			if k == "Host" {
+				req.Host = v
+			} else {
+				req.Header.Set(k, v)
+			}
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	port := ts.URL[strings.LastIndex(ts.URL, ":")+1:]
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header map[string]string
+		want   int
+	}{
+		{"same origin", "GET", "/api/jobs", "", map[string]string{"Origin": ts.URL}, http.StatusOK},
+		{"localhost", "GET", "/api/jobs", "", map[string]string{"Host": "localhost:" + port}, http.StatusOK},
+		{"cross-site origin", "GET", "/api/jobs", "", map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden},
+		{"null origin", "GET", "/api/jobs", "", map[string]string{"Origin": "null"}, http.StatusForbidden},
+		{"cross-site fetch", "GET", "/api/jobs", "", map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		// DNS rebinding: 其他域名解析到本机
+		{"rebound host", "GET", "/api/jobs", "", map[string]string{"Host": "evil.example:" + port}, http.StatusForbidden},
+		{"other port", "GET", "/api/jobs", "", map[string]string{"Host": "127.0.0.1:1"}, http.StatusForbidden},
+		// 跨站表单只能提交 text/plain 等类型
+		{"form job", "POST", "/api/jobs", `{"url": "not a url"}`, map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"no content type", "PUT", "/api/config", `{}`, nil, http.StatusUnsupportedMediaType},
+		{"json charset", "POST", "/api/jobs", `{"url": "not a url"}`,
+			map[string]string{"Content-Type": "application/json; charset=utf-8"}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if code := request(c.method, c.path, c.body, c.header); code != c.want {
+			t.Errorf("%s: %d, want %d", c.name, code, c.want)
+		}
+	}
+
+	// 有 token 时不限制 Host, 但仍拒绝跨站请求
+	ts = newTestServer(t, "secret")
+	auth := map[string]string{"Authorization": "Bearer secret", "Host": "nas.local:" + port}
+	if code := request("GET", "/api/jobs", "", auth); code != http.StatusOK {
+		t.Errorf("token with host name: %d", code)
+	}
+	auth["Origin"] = "https://evil.example"
+	if code := request("GET", "/api/jobs", "", auth); code != http.StatusForbidden {
+		t.Errorf("token with cross-site origin: %d", code)
+	}
+}
+
+func TestRouting(t *testing.T) {
+	ts := newTestServer(t, "")
+	if code := do(t, "GET", ts.URL+"/api/nope", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("unknown endpoint: %d", code)
+	}
+	if code := do(t, "PATCH", ts.URL+"/api/jobs", "", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("wrong method: %d", code)
+	}
+	if code := do(t, "GET", ts.URL+"/api/search", "", "", nil); code != http.StatusBadRequest {
+		t.Errorf("search without q: %d", code)
+	}
+	if code := do(t, "GET", ts.URL+"/api/book", "", "", nil); code != http.StatusBadRequest {
+		t.Errorf("book without url: %d", code)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	ts := newTestServer(t, "")
+	var conf config.Info
+	if code := do(t, "PUT", ts.URL+"/api/config", "", `{"base": {"extname": "fb2"}}`, &conf); code != http.StatusOK {
+		t.Fatalf("put: %d", code)
+	}
+	if conf.Base.Extname != "fb2" {
+		t.Errorf("put returned %q", conf.Base.Extname)
+	}
+	conf = config.Info{}
+	if code := do(t, "GET", ts.URL+"/api/config", "", "", &conf); code != http.StatusOK || conf.Base.Extname != "fb2" {
+		t.Errorf("get: %d %q", code, conf.Base.Extname)
+	}
+	if code := do(t, "PUT", ts.URL+"/api/config", "", `{"base": {"extname": 1}}`, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid config: %d", code)
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	ts := newTestServer(t, "")
+	var entries []model.LibraryEntry
+	if code := do(t, "GET", ts.URL+"/api/library?q=x", "", "", &entries); code != http.StatusOK || entries == nil {
+		t.Errorf("list: %d %v", code, entries)
+	}
+	if code := do(t, "DELETE", ts.URL+"/api/library/nope", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("delete unknown: %d", code)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	ts := newTestServer(t, "")
+	for _, body := range []string{
+		`{"url": "not a url"}`,
+		`{"url": "http://example.com/1/", "format": "doc"}`,
+		`{"url": "http://example.com/1/", "start": 5, "end": 2}`,
+		`{"url": "http://example.com/1/", "extra": true}`,
+	} {
+		if code := do(t, "POST", ts.URL+"/api/jobs", "", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: %d", body, code)
+		}
+	}
+	if code := do(t, "GET", ts.URL+"/api/jobs/nope", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("get unknown: %d", code)
+	}
+	if code := do(t, "DELETE", ts.URL+"/api/jobs/nope", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("cancel unknown: %d", code)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	// 书籍页面在取消之后才返回
+	release := make(chan struct{})
+	book := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer book.Close()
+	releaseOnce := sync.OnceFunc(func() { close(release) })
+	defer releaseOnce()
+
+	ts := newTestServer(t, "")
+	body := `{"url": "` + book.URL + `/book/1/", "format": "txt"}`
+	var job model.DownloadJob
+	if code := do(t, "POST", ts.URL+"/api/jobs", "", body, &job); code != http.StatusAccepted || job.ID == "" {
+		t.Fatalf("create: %d %+v", code, job)
+	}
+	if code := do(t, "POST", ts.URL+"/api/jobs", "", body, nil); code != http.StatusConflict {
+		t.Errorf("duplicate: %d", code)
+	}
+	if code := do(t, "DELETE", ts.URL+"/api/jobs/"+job.ID, "", "", nil); code != http.StatusAccepted {
+		t.Fatalf("cancel: %d", code)
+	}
+	releaseOnce()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for job.Status != model.JobCanceled {
+		if time.Now().After(deadline) {
+			t.Fatalf("job not canceled: %+v", job)
+		}
+		time.Sleep(50 * time.Millisecond)
+		do(t, "GET", ts.URL+"/api/jobs/"+job.ID, "", "", &job)
+	}
+	if job.FinishedAt == nil || job.Result != nil {
+		t.Errorf("canceled job: %+v", job)
+	}
+	if code := do(t, "DELETE", ts.URL+"/api/jobs/"+job.ID, "", "", nil); code != http.StatusConflict {
+		t.Errorf("cancel finished: %d", code)
+	}
+}
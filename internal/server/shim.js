@@ -0,0 +1,282 @@
+// fy-novel 浏览器传输层: 由 fy-novel serve 注入 index.html, 在浏览器中以 REST API 实现
+// wailsjs 绑定 (window.go.main.App) 与 window.runtime, 使同一前端无需 Wails 即可运行.
+// 返回值与 app.go 中的方法一致; 需要桌面环境的功能 (Ollama、打开文件夹等) 返回错误.
+(function () {
+  'use strict';
+
+  var UNAVAILABLE = 'not available in the browser';
+  // 下载任务状态的轮询间隔
+  var POLL_INTERVAL = 1000;
+
+  function query(params) {
+    var search = new URLSearchParams();
+    Object.keys(params || {}).forEach(function (key) {
+      if (params[key] !== undefined && params[key] !== null && params[key] !== '') {
+        search.set(key, String(params[key]));
+      }
+    });
+    var s = search.toString();
+    return s ? '?' + s : '';
+  }
+
+  // request 发送请求, 非 2xx 时以 {"error": "..."} 中的信息抛出错误.
+  // 页面使用 Basic 认证打开时, 浏览器会为同源请求带上同样的认证信息
+  async function request(method, path, body) {
+    var init = { method: method, headers: {}, credentials: 'same-origin' };
+    if (body !== undefined) {
+      init.headers['Content-Type'] = 'application/json';
+      init.body = typeof body === 'string' ? body : JSON.stringify(body);
+    }
+    var resp = await fetch(path, init);
+    var text = await resp.text();
+    var data = null;
+    if (text) {
+      try {
+        data = JSON.parse(text);
+      } catch (e) {
+        data = { error: text };
+      }
+    }
+    if (!resp.ok) {
+      throw new Error((data && data.error) || resp.status + ' ' + resp.statusText);
+    }
+    return data;
+  }
+
+  function get(path, params) {
+    return request('GET', path + query(params));
+  }
+
+  function message(err) {
+    return String((err && err.message) || err);
+  }
+
+  function sleep(ms) {
+    return new Promise(function (resolve) {
+      setTimeout(resolve, ms);
+    });
+  }
+
+  function finished(status) {
+    return status === 'done' || status === 'failed' || status === 'canceled';
+  }
+
+  function emptyReport() {
+    return { Total: 0, Numbered: 0, Gaps: null, Duplicates: null };
+  }
+
+  function libraryResult(promise) {
+    return promise.then(
+      function (entries) {
+        return { Entries: entries, ErrorMsg: '' };
+      },
+      function (err) {
+        return { Entries: [], ErrorMsg: message(err) };
+      }
+    );
+  }
+
+  function unavailable(result) {
+    return function () {
+      return Promise.resolve(result);
+    };
+  }
+
+  var App = {
+    SerachNovel: function (name) {
+      return get('/api/search', { q: name }).catch(function (err) {
+        console.error('SerachNovel', err);
+        return null;
+      });
+    },
+
+    CheckCatalog: function (sr) {
+      return get('/api/book', { url: sr.url }).then(
+        function (details) {
+          return { Report: details.catalog, ErrorMsg: '' };
+        },
+        function (err) {
+          return { Report: emptyReport(), ErrorMsg: message(err) };
+        }
+      );
+    },
+
+    // DownLoadNovel 创建下载任务并等待其结束, 与桌面版一样失败时返回 null
+    DownLoadNovel: async function (sr) {
+      try {
+        var job = await request('POST', '/api/jobs', { url: sr.url });
+        while (!finished(job.status)) {
+          await sleep(POLL_INTERVAL);
+          job = await get('/api/jobs/' + encodeURIComponent(job.id));
+        }
+        if (job.status !== 'done') {
+          console.error('DownLoadNovel', job.error || job.status);
+          return null;
+        }
+        return job.result;
+      } catch (err) {
+        console.error('DownLoadNovel', err);
+        return null;
+      }
+    },
+
+    GetDownloadProgress: async function (sr) {
+      var jobs = await get('/api/jobs');
+      for (var i = 0; i < jobs.length; i++) {
+        var job = jobs[i];
+        if (job.request.url === sr.url && job.status === 'running' && job.total > 0) {
+          return { Exists: true, Completed: job.completed, Total: job.total };
+        }
+      }
+      return { Exists: false, Completed: 0, Total: 0 };
+    },
+
+    GetConfig: function () {
+      return get('/api/config').then(function (conf) {
+        return { Config: conf };
+      });
+    },
+
+    SetConfig: function (conf) {
+      return request('PUT', '/api/config', conf).then(function () {
+        return '';
+      }, message);
+    },
+
+    GetUsageInfo: function () {
+      return get('/api/info');
+    },
+
+    GetUpdateInfo: function () {
+      return get('/api/update').catch(function (err) {
+        return { ErrorMsg: message(err), NeedUpdate: false, LatestVersion: '', CurrentVersion: '', LatestUrl: '' };
+      });
+    },
+
+    ListLibrary: function () {
+      return libraryResult(get('/api/library'));
+    },
+
+    SearchLibrary: function (keyword) {
+      return libraryResult(get('/api/library', { q: keyword }));
+    },
+
+    DeleteLibraryEntry: function (id, removeFiles) {
+      var path = '/api/library/' + encodeURIComponent(id) + query({ files: removeFiles ? 'true' : '' });
+      return request('DELETE', path).then(function () {
+        return '';
+      }, message);
+    },
+
+    // 以下功能依赖本机环境, 在浏览器中不可用
+    HasInitOllama: unavailable({ Has: false, IsInit: false, IsSetModel: false, ErrorMsg: UNAVAILABLE }),
+    InitOllama: unavailable({ ErrorMsg: UNAVAILABLE }),
+    GetInitOllamaProgress: unavailable({ Exists: false, Completed: 0, Total: 0 }),
+    InitSetOllamaModelTask: unavailable({ ErrorMsg: UNAVAILABLE }),
+    SetOllamaModel: unavailable({ ErrorMsg: UNAVAILABLE }),
+    GetCurrentUseModel: unavailable({ Model: '' }),
+    GetSelectModelList: unavailable({ Models: [] }),
+    GetSetOllamaModelProgress: unavailable({ Exists: false, Completed: 0, Total: 0 }),
+    StartChatbot: unavailable({ Response: '', ErrorMsg: UNAVAILABLE }),
+    DeepSeekChat: unavailable({ Response: '', ErrorMsg: UNAVAILABLE }),
+    GenerateAsciiImage: unavailable({ Response: '', ErrorMsg: UNAVAILABLE }),
+    OpenLibraryFolder: unavailable(UNAVAILABLE),
+  };
+
+  // 没有对应 REST 接口的其他方法直接报错
+  window.go = {
+    main: {
+      App: new Proxy(App, {
+        get: function (target, name) {
+          if (name in target) {
+            return target[name];
+          }
+          return function () {
+            return Promise.reject(new Error(String(name) + ' is ' + UNAVAILABLE));
+          };
+        },
+      }),
+    },
+  };
+
+  // 事件只在页面内传递, 服务端不推送事件
+  var listeners = {};
+
+  function off(name, listener) {
+    listeners[name] = (listeners[name] || []).filter(function (l) {
+      return l !== listener;
+    });
+  }
+
+  var runtime = {
+    EventsOnMultiple: function (name, callback, maxCallbacks) {
+      var listener = { callback: callback, remaining: maxCallbacks };
+      (listeners[name] = listeners[name] || []).push(listener);
+      return function () {
+        off(name, listener);
+      };
+    },
+    EventsOff: function () {
+      for (var i = 0; i < arguments.length; i++) {
+        delete listeners[arguments[i]];
+      }
+    },
+    EventsOffAll: function () {
+      listeners = {};
+    },
+    EventsEmit: function (name) {
+      var data = Array.prototype.slice.call(arguments, 1);
+      (listeners[name] || []).slice().forEach(function (listener) {
+        listener.callback.apply(null, data);
+        if (listener.remaining > 0 && --listener.remaining === 0) {
+          off(name, listener);
+        }
+      });
+    },
+    BrowserOpenURL: function (url) {
+      window.open(url, '_blank', 'noopener');
+    },
+    WindowSetTitle: function (title) {
+      document.title = title;
+    },
+    WindowReload: function () {
+      window.location.reload();
+    },
+    WindowReloadApp: function () {
+      window.location.reload();
+    },
+    Environment: function () {
+      return Promise.resolve({ buildType: 'production', platform: 'web', arch: '' });
+    },
+    ClipboardGetText: function () {
+      return navigator.clipboard.readText();
+    },
+    ClipboardSetText: function (text) {
+      return navigator.clipboard.writeText(text).then(
+        function () {
+          return true;
+        },
+        function () {
+          return false;
+        }
+      );
+    },
+    LogPrint: console.log.bind(console),
+    LogTrace: console.debug.bind(console),
+    LogDebug: console.debug.bind(console),
+    LogInfo: console.info.bind(console),
+    LogWarning: console.warn.bind(console),
+    LogError: console.error.bind(console),
+    LogFatal: console.error.bind(console),
+  };
+
+  // 窗口控制等其他运行时函数在浏览器中不做任何事
+  window.runtime = new Proxy(runtime, {
+    get: function (target, name) {
+      if (name in target) {
+        return target[name];
+      }
+      return function () {};
+    },
+  });
+})();
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//go:embed shim.js
+var shimJS []byte
+
+// shimPath 注入 index.html 的传输层脚本, 以 REST API 替代 wailsjs 绑定
+const shimPath = "/wails-shim.js"
+
+var startTime = time.Now()
+
+func (s *Server) webRoutes() {
+	if s.webDir == "" {
+		return
+	}
+	s.mux.HandleFunc("GET "+shimPath, s.auth(s.handleShim))
+	s.mux.HandleFunc("GET /", s.auth(s.handleWeb))
+}
+
+func (s *Server) handleShim(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	http.ServeContent(w, r, "shim.js", startTime, bytes.NewReader(shimJS))
+}
+
+// handleWeb 提供前端构建产物, 不存在的路径返回 index.html, 由前端路由处理
+func (s *Server) handleWeb(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, opdsBase+"/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	name := path.Clean(r.URL.Path)
+	if name != "/" && name != "/index.html" {
+		f, err := http.Dir(s.webDir).Open(name)
+		if err == nil {
+			defer f.Close()
+			if info, err := f.Stat(); err == nil && !info.IsDir() {
+				http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+				return
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	s.serveIndex(w, r)
+}
+
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index, err := os.ReadFile(filepath.Join(s.webDir, "index.html"))
+	if err != nil {
+		s.log.Errorf("server web index error: %v", err)
+		writeError(w, http.StatusNotFound, "web UI not found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// 前端更新后立即生效
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(injectShim(index))
+}
+
+// injectShim 在其他脚本之前加载传输层脚本
+func injectShim(index []byte) []byte {
+	tag := []byte(`<script src="` + shimPath + `"></script>`)
+	lower := bytes.ToLower(index)
+	if i := bytes.Index(lower, []byte("<head")); i >= 0 {
+		if j := bytes.IndexByte(index[i:], '>'); j >= 0 {
+			at := i + j + 1
+			return append(append(append([]byte{}, index[:at]...), tag...), index[at:]...)
+		}
+	}
+	return append(tag, index...)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWeb(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte(`<!DOCTYPE html><html><HEAD lang="zh"><script type="module" src="/assets/index.js"></script></HEAD></html>`), 0644)
+	os.MkdirAll(filepath.Join(dir, "assets"), 0755)
+	os.WriteFile(filepath.Join(dir, "assets", "index.js"), []byte("console.log(1)"), 0644)
+	ts := newTestServerWith(t, Options{Token: "secret", WebDir: dir})
+	basic := func(r *http.Request) { r.SetBasicAuth("", "secret") }
+
+	resp, _ := get(t, ts.URL+"/", nil)
+	if resp.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Basic") {
+		t.Errorf("no auth: %d %q", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+	// 前端路由与根路径都返回注入了传输层的 index.html
+	for _, path := range []string{"/", "/index.html", "/download/search"} {
+		resp, body := get(t, ts.URL+path, basic)
+		want := `<HEAD lang="zh"><script src="/wails-shim.js"></script><script type="module"`
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, want) {
+			t.Errorf("%s: %d %s", path, resp.StatusCode, body)
+		}
+	}
+	if resp, body := get(t, ts.URL+"/assets/index.js", basic); resp.StatusCode != http.StatusOK || body != "console.log(1)" {
+		t.Errorf("asset: %d %q", resp.StatusCode, body)
+	}
+	if resp, body := get(t, ts.URL+"/wails-shim.js", basic); resp.StatusCode != http.StatusOK || !strings.Contains(body, "window.go") {
+		t.Errorf("shim: %d", resp.StatusCode)
+	}
+	// 同源的 API 请求带着 Basic 认证
+	if resp, _ := get(t, ts.URL+"/api/info", basic); resp.StatusCode != http.StatusOK {
+		t.Errorf("api with basic auth: %d", resp.StatusCode)
+	}
+	if resp, _ := get(t, ts.URL+"/api/nope", basic); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown api: %d", resp.StatusCode)
+	}
+}
+
+func TestWebDisabled(t *testing.T) {
+	ts := newTestServer(t, "")
+	if resp, _ := get(t, ts.URL+"/", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("no web dir: %d", resp.StatusCode)
+	}
+}
+
+func TestInjectShim(t *testing.T) {
+	tag := `<script src="/wails-shim.js"></script>`
+	if got := string(injectShim([]byte("<p>x</p>"))); got != tag+"<p>x</p>" {
+		t.Errorf("no head: %s", got)
+	}
+	if got := string(injectShim([]byte("<head><title>x</title></head>"))); got != "<head>"+tag+"<title>x</title></head>" {
+		t.Errorf("head: %s", got)
+	}
+}
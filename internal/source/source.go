@@ -4,8 +4,10 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 
+	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 )
 
@@ -39,3 +41,29 @@ func GetRuleBySourceID(sourceId int) model.Rule {
 	actual, _ := ruleCache.LoadOrStore(sourceId, rule)
 	return actual.(model.Rule)
 }
+
+// IDs returns the IDs of the built-in rules in ascending order
+func IDs() []int {
+	entries, err := ruleFS.ReadDir("rule")
+	if err != nil {
+		return nil
+	}
+	var ids []int
+	for _, e := range entries {
+		var id int
+		if _, err := fmt.Sscanf(e.Name(), "rule%d.json", &id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// ConcurrencyNum returns the configured thread count, capped by the concurrency limit of the rule
+func ConcurrencyNum(conf config.Info) int {
+	threads := conf.GetConcurrencyNum()
+	if limit := GetRuleBySourceID(conf.Base.SourceID).RateLimit.Concurrency; limit > 0 {
+		return min(threads, limit)
+	}
+	return threads
+}
@@ -0,0 +1,249 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fy-novel/internal/model"
+)
+
+// 书单格式:
+//   - 纯文本: 每行一个书名, 空行与 # 开头的行忽略
+//   - CSV: 首行为表头, 支持 name, author, source, range 列 (也可用 书名, 作者, 书源, 范围)
+//   - JSON: 对象数组, 字段为 name, author, source, range
+// range 形如 "1-100", "100-" 或 "-50", 表示下载的章节范围
+
+// jsonItem JSON 书单中的一项, source 与 range 可以是数字或字符串
+type jsonItem struct {
+	Name   string          `json:"name"`
+	Author string          `json:"author"`
+	Source json.RawMessage `json:"source"`
+	Range  json.RawMessage `json:"range"`
+}
+
+var csvColumns = map[string]string{
+	"name": "name", "书名": "name",
+	"author": "author", "作者": "author",
+	"source": "source", "source id": "source", "sourceid": "source", "书源": "source",
+	"range": "range", "范围": "range",
+}
+
+// Load 按扩展名读取书单
+func Load(path string) ([]model.BatchItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// 去掉 Excel 等工具写入的 BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var items []model.BatchItem
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		items, err = parseJSON(data)
+	case ".csv":
+		items, err = parseCSV(data)
+	default:
+		items, err = parseText(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("batch list %s: %v", path, err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch list %s: no books", path)
+	}
+	return items, nil
+}
+
+func parseText(data []byte) ([]model.BatchItem, error) {
+	var items []model.BatchItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		items = append(items, model.BatchItem{Line: line, Name: name})
+	}
+	return items, scanner.Err()
+}
+
+func parseCSV(data []byte) ([]model.BatchItem, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		if name, ok := csvColumns[strings.ToLower(strings.TrimSpace(h))]; ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("missing name column")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []model.BatchItem
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		item := model.BatchItem{Line: line, Name: field(record, "name"), Author: field(record, "author")}
+		if item.Name == "" {
+			continue
+		}
+		if item.SourceID, err = parseSource(field(record, "source")); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if item.Start, item.End, err = ParseRange(field(record, "range")); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseJSON(data []byte) ([]model.BatchItem, error) {
+	var list []jsonItem
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	var items []model.BatchItem
+	for i, it := range list {
+		item := model.BatchItem{Line: i + 1, Name: strings.TrimSpace(it.Name), Author: strings.TrimSpace(it.Author)}
+		if item.Name == "" {
+			continue
+		}
+		var err error
+		if item.SourceID, err = parseSource(rawString(it.Source)); err != nil {
+			return nil, fmt.Errorf("item %d: %v", i+1, err)
+		}
+		if item.Start, item.End, err = ParseRange(rawString(it.Range)); err != nil {
+			return nil, fmt.Errorf("item %d: %v", i+1, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// rawString 数字或字符串形式的 JSON 值
+func rawString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func parseSource(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid source id %q", s)
+	}
+	return id, nil
+}
+
+// ParseRange 解析 "1-100", "100-", "-50" 或单个章节 "5", 为空时不限制
+func ParseRange(s string) (start, end int, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, nil
+	}
+	from, to, isRange := strings.Cut(s, "-")
+	if !isRange {
+		to = from
+	}
+	if from = strings.TrimSpace(from); from != "" {
+		if start, err = strconv.Atoi(from); err != nil || start < 1 {
+			return 0, 0, fmt.Errorf("invalid range %q", s)
+		}
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		if end, err = strconv.Atoi(to); err != nil || end < 1 {
+			return 0, 0, fmt.Errorf("invalid range %q", s)
+		}
+	}
+	if start > 0 && end > 0 && start > end {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return start, end, nil
+}
+
+// Resolve 在搜索结果中找出书单中的书, 返回匹配结果与状态 (model.BatchOK 等).
+// 书名需完全一致 (忽略空白与大小写), 填写了作者时作者也需一致;
+// 未填写作者且同名的书有多个作者时为歧义, 此时与作者不符时一样返回同名的候选
+func Resolve(item model.BatchItem, results []*model.SearchResult) (*model.SearchResult, []*model.SearchResult, string) {
+	var sameName []*model.SearchResult
+	for _, r := range results {
+		if sameText(r.BookName, item.Name) {
+			sameName = append(sameName, r)
+		}
+	}
+	if item.Author != "" {
+		for _, r := range sameName {
+			if sameText(r.Author, item.Author) {
+				return r, nil, model.BatchOK
+			}
+		}
+		return nil, sameName, model.BatchNotFound
+	}
+	if len(sameName) == 0 {
+		return nil, nil, model.BatchNotFound
+	}
+	// 同一作者的重复结果 (例如分页重复) 不算歧义
+	for _, r := range sameName[1:] {
+		if !sameText(r.Author, sameName[0].Author) {
+			return nil, sameName, model.BatchAmbiguous
+		}
+	}
+	return sameName[0], nil, model.BatchOK
+}
+
+func sameText(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), ""), strings.Join(strings.Fields(b), ""))
+}
+
+// WriteReport 将报告以 JSON 写入文件
+func WriteReport(report *model.BatchReport, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReportPath 默认的报告路径: 与书单同目录, 例如 books.txt -> books.report.json
+func ReportPath(listPath string) string {
+	return strings.TrimSuffix(listPath, filepath.Ext(listPath)) + ".report.json"
+}
@@ -0,0 +1,121 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"fy-novel/internal/model"
+)
+
+func writeList(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []model.BatchItem
+	}{
+		{
+			"text", "books.txt", "\ufeff剑来\n\n# 稍后再看\n 雪中悍刀行 \n",
+			[]model.BatchItem{{Line: 1, Name: "剑来"}, {Line: 4, Name: "雪中悍刀行"}},
+		},
+		{
+			"csv", "books.csv", "书名,作者,书源,范围\n剑来,烽火戏诸侯,2,1-100\n\"雪中, 悍刀行\",,,\n",
+			[]model.BatchItem{
+				{Line: 2, Name: "剑来", Author: "烽火戏诸侯", SourceID: 2, Start: 1, End: 100},
+				{Line: 3, Name: "雪中, 悍刀行"},
+			},
+		},
+		{
+			"json", "books.json", `[{"name": "剑来", "source": 3, "range": "100-"}, {"name": ""}, {"name": "雪中", "author": "烽火", "source": "1"}]`,
+			[]model.BatchItem{
+				{Line: 1, Name: "剑来", SourceID: 3, Start: 100},
+				{Line: 3, Name: "雪中", Author: "烽火", SourceID: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(writeList(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []struct{ file, content string }{
+		{"empty.txt", "# nothing\n"},
+		{"noname.csv", "author\nx\n"},
+		{"source.csv", "name,source\n剑来,abc\n"},
+		{"range.json", `[{"name": "剑来", "range": "9-1"}]`},
+	} {
+		if _, err := Load(writeList(t, bad.file, bad.content)); err == nil {
+			t.Errorf("%s: expected error", bad.file)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := map[string][2]int{"": {0, 0}, "1-100": {1, 100}, "100-": {100, 0}, "-50": {0, 50}, " 5 ": {5, 5}}
+	for s, want := range tests {
+		start, end, err := ParseRange(s)
+		if err != nil || start != want[0] || end != want[1] {
+			t.Errorf("ParseRange(%q) = %d, %d, %v", s, start, end, err)
+		}
+	}
+	for _, s := range []string{"a", "0-5", "5-1", "1-x"} {
+		if _, _, err := ParseRange(s); err == nil {
+			t.Errorf("ParseRange(%q) should fail", s)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	a := &model.SearchResult{BookName: "剑来", Author: "烽火戏诸侯", Url: "a"}
+	dup := &model.SearchResult{BookName: " 剑来", Author: "烽火戏诸侯 ", Url: "dup"}
+	b := &model.SearchResult{BookName: "剑来", Author: "别人", Url: "b"}
+	other := &model.SearchResult{BookName: "剑来前传", Author: "烽火戏诸侯", Url: "c"}
+
+	tests := []struct {
+		name       string
+		item       model.BatchItem
+		results    []*model.SearchResult
+		want       *model.SearchResult
+		candidates int
+		status     string
+	}{
+		{"single", model.BatchItem{Name: "剑来"}, []*model.SearchResult{other, a}, a, 0, model.BatchOK},
+		{"same author twice", model.BatchItem{Name: "剑来"}, []*model.SearchResult{a, dup}, a, 0, model.BatchOK},
+		{"ambiguous", model.BatchItem{Name: "剑来"}, []*model.SearchResult{a, b}, nil, 2, model.BatchAmbiguous},
+		{"author picks", model.BatchItem{Name: "剑来", Author: "别人"}, []*model.SearchResult{a, b}, b, 0, model.BatchOK},
+		{"author mismatch", model.BatchItem{Name: "剑来", Author: "无名"}, []*model.SearchResult{a}, nil, 1, model.BatchNotFound},
+		{"not found", model.BatchItem{Name: "剑"}, []*model.SearchResult{a, other}, nil, 0, model.BatchNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, candidates, status := Resolve(tt.item, tt.results)
+			if got != tt.want || len(candidates) != tt.candidates || status != tt.status {
+				t.Errorf("got %v, %d candidates, %s", got, len(candidates), status)
+			}
+		})
+	}
+}
+
+func TestReportPath(t *testing.T) {
+	if got := ReportPath(filepath.Join("lists", "books.txt")); got != filepath.Join("lists", "books.report.json") {
+		t.Errorf("ReportPath = %s", got)
+	}
+}
@@ -0,0 +1,44 @@
+package chapter
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// fb2Format 每段对应一个 <p>, 加粗与斜体转为 <strong> 与 <emphasis>
+type fb2Format struct{}
+
+func (fb2Format) escape(s string) string {
+	return fb2Escape(s)
+}
+
+func (fb2Format) inline(tag string, open bool) string {
+	name := "emphasis"
+	if tag == "b" || tag == "strong" {
+		name = "strong"
+	}
+	if open {
+		return "<" + name + ">"
+	}
+	return "</" + name + ">"
+}
+
+func (fb2Format) paragraph(out *strings.Builder, text string) {
+	out.WriteString("<p>" + text + "</p>")
+}
+
+// fb2Convert 将章节转为 FictionBook 的 <section>
+func fb2Convert(title, content string) string {
+	body := walkParagraphs(content, fb2Format{})
+	// 空章节也需要至少一个段落级元素
+	if body == "" {
+		body = "<empty-line/>"
+	}
+	return "<section><title><p>" + fb2Escape(strings.TrimSpace(title)) + "</p></title>" + body + "</section>"
+}
+
+func fb2Escape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,19 @@
+package chapter
+
+import "testing"
+
+func TestFb2Convert(t *testing.T) {
+	content := `<p>　　第一段 &amp; <span>内联</span></p><p>第二段<br>换行</p><p>   </p>`
+	want := "<section><title><p>第一章 &lt;上&gt;</p></title>" +
+		"<p>第一段 &amp; 内联</p><p>第二段</p><p>换行</p></section>"
+	if got := fb2Convert("第一章 <上>", content); got != want {
+		t.Errorf("fb2Convert() =\n%s\nwant\n%s", got, want)
+	}
+	if got := fb2Convert("格式", "<p><b>加粗</b>与<i>斜体</i></p>"); got !=
+		"<section><title><p>格式</p></title><p><strong>加粗</strong>与<emphasis>斜体</emphasis></p></section>" {
+		t.Errorf("fb2Convert() with inline formats = %s", got)
+	}
+	if got := fb2Convert("空", ""); got != "<section><title><p>空</p></title><empty-line/></section>" {
+		t.Errorf("fb2Convert() for empty chapter = %s", got)
+	}
+}
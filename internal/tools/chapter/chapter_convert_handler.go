@@ -1,22 +1,44 @@
 package chapter
 
 import (
+	"fy-novel/internal/config"
 	"fy-novel/internal/definition"
 	"fy-novel/internal/model"
+	dictTool "fy-novel/internal/tools/dict"
+	filterTool "fy-novel/internal/tools/filter"
 )
 
+// CleanChapter 清洗抓取到的正文, 返回由 <p> 段落组成的 HTML
+func CleanChapter(chapter *model.Chapter, conf config.Info, rule model.Rule) (string, error) {
+	pipeline, err := filterTool.Get(rule, conf)
+	if err != nil {
+		return "", err
+	}
+	return extractParagraphs(pipeline.Apply(chapter.Title, chapter.Content)), nil
+}
+
+// ConvertChapter 对清洗后的正文应用替换词典, 并转换为导出格式
 func ConvertChapter(
 	chapter *model.Chapter,
 	extName string,
-	rule model.Rule,
+	scope dictTool.Scope,
 ) error {
-	var content string
-	var err error
-	content = formatForChapter(filterForChapter(chapter, rule), rule)
+	replacer, err := dictTool.ForScope(scope)
+	if err != nil {
+		return err
+	}
+	content, _ := replacer.Apply(chapter.Content)
 
 	switch extName {
 	case definition.NovelExtname_TXT:
 		content = txtConvert(chapter.Title, content)
+	case definition.NovelExtname_MD:
+		content = mdConvert(chapter.Title, content)
+	case definition.NovelExtname_FB2:
+		content = fb2Convert(chapter.Title, content)
+	case definition.NovelExtname_PDF:
+		// 标题由 PDF 排版时单独绘制
+		content = txtParagraphs(content)
 	case definition.NovelExtname_EPUB, definition.NovelExtname_HTML:
 		content, err = templateConvert(chapter.Title, content, extName)
 		if err != nil {
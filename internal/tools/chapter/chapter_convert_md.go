@@ -0,0 +1,61 @@
+package chapter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"*", `\*`,
+		"_", `\_`,
+		"`", "\\`",
+		"[", `\[`,
+		"]", `\]`,
+		"<", `\<`,
+		">", `\>`,
+	)
+	// 行首会被解析为标题、列表或引用的字符
+	mdLineStart = regexp.MustCompile(`^(#{1,6}|[-+=]|\d+\.)(\s|$)`)
+)
+
+// mdFormat 每段之间空一行, 加粗与斜体保留为强调
+type mdFormat struct{}
+
+func (mdFormat) escape(s string) string {
+	return mdEscape(s)
+}
+
+func (mdFormat) inline(tag string, _ bool) string {
+	if tag == "b" || tag == "strong" {
+		return "**"
+	}
+	return "*"
+}
+
+func (mdFormat) paragraph(out *strings.Builder, text string) {
+	out.WriteString(mdEscapeLineStart(text))
+	out.WriteString("\n\n")
+}
+
+// mdConvert 将 <p> 规范化后的章节 HTML 转为 Markdown
+func mdConvert(title, content string) string {
+	return "## " + mdEscape(strings.TrimSpace(title)) + "\n\n" + walkParagraphs(content, mdFormat{})
+}
+
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+func mdEscapeLineStart(line string) string {
+	m := mdLineStart.FindStringSubmatch(line)
+	if m == nil {
+		return line
+	}
+	if strings.HasSuffix(m[1], ".") {
+		// 有序列表需转义点号, 例如 1\.
+		return strings.Replace(line, ".", `\.`, 1)
+	}
+	return `\` + line
+}
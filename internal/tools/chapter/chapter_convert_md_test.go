@@ -0,0 +1,16 @@
+package chapter
+
+import "testing"
+
+func TestMdConvert(t *testing.T) {
+	content := `<p>　　第一段 *重点*</p><p>第二段<br>换行</p><p>   </p><p>1. 不是列表</p><p><b>加粗</b>与[方括号]</p>`
+	want := "## 第一章 \\[上\\]\n\n" +
+		"第一段 \\*重点\\*\n\n" +
+		// <br> 与其他导出格式一样分段
+		"第二段\n\n换行\n\n" +
+		"1\\. 不是列表\n\n" +
+		"**加粗**与\\[方括号\\]\n\n"
+	if got := mdConvert("第一章 [上]", content); got != want {
+		t.Errorf("mdConvert() =\n%q\nwant\n%q", got, want)
+	}
+}
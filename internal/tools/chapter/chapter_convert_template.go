@@ -3,6 +3,7 @@ package chapter
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"sync"
 	"text/template"
 
@@ -21,7 +22,7 @@ func templateConvert(title, content, extName string) (string, error) {
 		Title   string
 		Content string
 	}{
-		Title:   title,
+		Title:   html.EscapeString(title),
 		Content: content,
 	}
 
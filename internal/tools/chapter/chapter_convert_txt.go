@@ -2,35 +2,32 @@ package chapter
 
 import (
 	"fmt"
-	"golang.org/x/net/html"
 	"strings"
 )
 
+// 全角空格, 用于首行缩进
+var txtIndent = strings.Repeat("\u3000", 2)
+
+// txtFormat 每段一行并以全角空格首行缩进, 不保留行内格式
+type txtFormat struct{}
+
+func (txtFormat) escape(s string) string {
+	return s
+}
+
+func (txtFormat) inline(string, bool) string {
+	return ""
+}
+
+func (txtFormat) paragraph(out *strings.Builder, text string) {
+	out.WriteString(txtIndent + text + "\n")
+}
+
 func txtConvert(title, content string) string {
-	// 全角空格, 用于首行缩进
-	indent := strings.Repeat("\u3000", 2)
-
-	doc, err := html.Parse(strings.NewReader(content))
-	if err != nil {
-		return fmt.Sprintf("Error parsing HTML: %v", err)
-	}
-
-	var result strings.Builder
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.TextNode {
-			text := strings.TrimSpace(n.Data)
-			if text != "" {
-				result.WriteString(indent)
-				result.WriteString(html.UnescapeString(text))
-				result.WriteString("\n")
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
-	}
-	f(doc)
-
-	return fmt.Sprintf("%s\n\n%s", title, result.String())
+	return fmt.Sprintf("%s\n\n%s", title, txtParagraphs(content))
+}
+
+// txtParagraphs 提取章节文本, 每段一行, 段内的行内元素不拆行
+func txtParagraphs(content string) string {
+	return walkParagraphs(content, txtFormat{})
 }
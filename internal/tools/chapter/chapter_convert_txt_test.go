@@ -10,3 +10,10 @@ func TestTxtConvert(t *testing.T) {
 	res := txtConvert("Title", content)
 	fmt.Println(res)
 }
+
+func TestTxtParagraphsInline(t *testing.T) {
+	got := txtParagraphs(`<p>甲<a href="#">乙</a><span>丙</span></p><p>a &amp;lt; b</p>`)
+	if want := "　　甲乙丙\n　　a &lt; b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package chapter
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 段落边界: 块级元素前后, 以及 <br> <hr>
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Center: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Table: true, atom.Tr: true, atom.Td: true, atom.Th: true,
+}
+
+// 保留的行内格式, 其余行内元素 (a, span, font 等) 只保留文字
+var inlineFormats = map[atom.Atom]bool{
+	atom.B: true, atom.Strong: true, atom.Em: true, atom.I: true,
+}
+
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true, atom.Template: true,
+}
+
+var spaceRun = regexp.MustCompile(`\s+`)
+
+// 段首段尾去除的空白, 包括全角空格缩进
+const paragraphSpace = " \t\r\n　\u00a0"
+
+// paragraphFormat 段落的输出格式. 各导出格式共用 paragraphWalker 的分段规则, 只在此处不同
+type paragraphFormat interface {
+	// escape 转义一段文字
+	escape(s string) string
+	// inline 行内格式 (b, strong, em, i) 的开始或结束标记
+	inline(tag string, open bool) string
+	// paragraph 输出一段, text 已转义并去除首尾空白, 不为空
+	paragraph(out *strings.Builder, text string)
+}
+
+// htmlFormat 清洗后保存的 <p> 段落
+type htmlFormat struct{}
+
+func (htmlFormat) escape(s string) string {
+	return html.EscapeString(s)
+}
+
+func (htmlFormat) inline(tag string, open bool) string {
+	if open {
+		return "<" + tag + ">"
+	}
+	return "</" + tag + ">"
+}
+
+func (htmlFormat) paragraph(out *strings.Builder, text string) {
+	out.WriteString("<p>" + text + "</p>")
+}
+
+// extractParagraphs 将清洗后的正文整理为 <p> 段落, 每行文字一段.
+// 兼容 <br> 分隔、每行一个 <div>/<p>、嵌套的行内元素及混合写法;
+// 没有任何标签结构的纯文本按换行分段.
+func extractParagraphs(content string) string {
+	return walkParagraphs(content, htmlFormat{})
+}
+
+// walkParagraphs 按段落遍历 HTML, 以 format 输出
+func walkParagraphs(content string, format paragraphFormat) string {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return ""
+	}
+	w := &paragraphWalker{format: format, splitLines: !hasStructure(nodes)}
+	for _, n := range nodes {
+		w.walk(n)
+	}
+	w.flush()
+	return w.out.String()
+}
+
+func hasStructure(nodes []*html.Node) bool {
+	for _, n := range nodes {
+		if n.Type == html.ElementNode && (blockElements[n.DataAtom] || n.DataAtom == atom.Br) {
+			return true
+		}
+		var children []*html.Node
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			children = append(children, c)
+		}
+		if hasStructure(children) {
+			return true
+		}
+	}
+	return false
+}
+
+type paragraphWalker struct {
+	format paragraphFormat
+	out    strings.Builder
+	// 当前段落
+	cur     strings.Builder
+	hasText bool
+	// 当前打开的行内格式, 跨段落时在新段落中重新打开
+	open []string
+	// 是否按文字中的换行分段 (纯文本或 <pre> 内)
+	splitLines bool
+	pre        int
+}
+
+func (w *paragraphWalker) walk(n *html.Node) {
+	if n.Type == html.TextNode {
+		w.text(n.Data)
+		return
+	}
+	if n.Type == html.ElementNode {
+		switch {
+		case droppedElements[n.DataAtom]:
+			return
+		case n.DataAtom == atom.Br || n.DataAtom == atom.Hr:
+			w.flush()
+			return
+		case blockElements[n.DataAtom]:
+			w.flush()
+			if n.DataAtom == atom.Pre {
+				w.pre++
+				defer func() { w.pre-- }()
+			}
+			w.children(n)
+			w.flush()
+			return
+		case inlineFormats[n.DataAtom]:
+			w.open = append(w.open, n.Data)
+			if w.hasText {
+				w.cur.WriteString(w.format.inline(n.Data, true))
+			}
+			w.children(n)
+			w.open = w.open[:len(w.open)-1]
+			if w.hasText {
+				w.cur.WriteString(w.format.inline(n.Data, false))
+			}
+			return
+		}
+	}
+	w.children(n)
+}
+
+func (w *paragraphWalker) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+}
+
+func (w *paragraphWalker) text(s string) {
+	if w.splitLines || w.pre > 0 {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if i > 0 {
+				w.flush()
+			}
+			w.write(line)
+		}
+		return
+	}
+	w.write(collapseSpace(s))
+}
+
+func (w *paragraphWalker) write(s string) {
+	if !w.hasText {
+		// 去除段首缩进, 段落开始于第一段文字, 此时补上已打开的格式
+		s = strings.TrimLeft(s, paragraphSpace)
+		if s == "" {
+			return
+		}
+		w.hasText = true
+		for _, tag := range w.open {
+			w.cur.WriteString(w.format.inline(tag, true))
+		}
+	}
+	w.cur.WriteString(w.format.escape(s))
+}
+
+func (w *paragraphWalker) flush() {
+	if w.hasText {
+		for i := len(w.open) - 1; i >= 0; i-- {
+			w.cur.WriteString(w.format.inline(w.open[i], false))
+		}
+		w.format.paragraph(&w.out, strings.TrimRight(w.cur.String(), paragraphSpace))
+	}
+	w.cur.Reset()
+	w.hasText = false
+}
+
+// collapseSpace 将连续的 ASCII 空白折叠为一个空格, 保留文本节点间的分隔
+func collapseSpace(s string) string {
+	return spaceRun.ReplaceAllString(s, " ")
+}
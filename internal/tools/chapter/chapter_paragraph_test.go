@@ -0,0 +1,74 @@
+package chapter
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"fy-novel/internal/source"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestExtractParagraphs(t *testing.T) {
+	cases := []struct{ name, in, want string }{
+		{"br runs", "甲<br><br><br>乙<br/>丙", "<p>甲</p><p>乙</p><p>丙</p>"},
+		{"nested inline", `<span>甲<a href="#">乙<font>丙</font></a>丁</span>`, "<p>甲乙丙丁</p>"},
+		{"div per line", "<div>甲</div><div><div>乙</div></div>", "<p>甲</p><p>乙</p>"},
+		{"mixed", "甲<p>乙</p>丙<br>丁", "<p>甲</p><p>乙</p><p>丙</p><p>丁</p>"},
+		{"format across br", "<b>甲<br>乙</b>丙", "<p><b>甲</b></p><p><b>乙</b>丙</p>"},
+		{"plain text", "　　甲\n\n　　乙\r\n", "<p>甲</p><p>乙</p>"},
+		{"escape", "a &lt; b &amp; c", "<p>a &lt; b &amp; c</p>"},
+		{"dropped", "<script>x</script><style>y</style>甲", "<p>甲</p>"},
+		{"empty", "<p> </p><br><b></b>", ""},
+	}
+	for _, c := range cases {
+		if got := extractParagraphs(c.in); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+// TestCleanChapterGolden 按各内置书源的真实正文结构检查清洗与分段结果, -update 重新生成
+func TestCleanChapterGolden(t *testing.T) {
+	titles := []string{"第一章 少年", "第二章 入城", "第3章 拜师", "第四章 练剑"}
+	for i, title := range titles {
+		sourceID := i + 1
+		t.Run(fmt.Sprintf("rule%d", sourceID), func(t *testing.T) {
+			rule := source.GetRuleBySourceID(sourceID)
+			if rule.ID == "" && rule.URL == "" {
+				t.Fatalf("rule %d not found", sourceID)
+			}
+			input, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("rule%d.input.html", sourceID)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			chapter := &model.Chapter{Title: title, Content: string(input)}
+			got, err := CleanChapter(chapter, config.Info{}, rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 每段一行便于阅读
+			got = strings.ReplaceAll(got, "</p>", "</p>\n")
+
+			golden := filepath.Join("testdata", fmt.Sprintf("rule%d.golden.html", sourceID))
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package dedup
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 去重作用于清洗后的 <p> 段落:
+//   - 章内重复: 分页拼接或注入导致同一段落出现多次, 保留第一次出现
+//   - 跨章重叠: 部分书源在章节开头重复上一章末尾的若干段落
+
+const (
+	KindRepeat  = "repeat"
+	KindOverlap = "overlap"
+
+	// 短于该字数的段落 (如 "嗯。" "……") 允许正常重复
+	minLength = 8
+	// 与下一章比较的上一章末尾段落数
+	tailSize = 30
+)
+
+var paragraphPattern = regexp.MustCompile(`(?s)<p\b[^>]*>.*?</p>`)
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Removal 一次去重删除的段落 (纯文本)
+type Removal struct {
+	Kind       string
+	Paragraphs []string
+}
+
+// Tracker 按目录顺序处理章节, 记住上一章的末尾段落. 非并发安全.
+type Tracker struct {
+	tail []string
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Reset 忘记上一章, 用于中间有章节缺失时
+func (t *Tracker) Reset() {
+	t.tail = nil
+}
+
+// Apply 去除章内重复段落及与上一章重叠的开头段落
+func (t *Tracker) Apply(content string) (string, []Removal) {
+	locs := paragraphPattern.FindAllStringIndex(content, -1)
+	keys := make([]string, len(locs))
+	texts := make([]string, len(locs))
+	for i, loc := range locs {
+		texts[i] = text(content[loc[0]:loc[1]])
+		keys[i] = normalize(texts[i])
+	}
+
+	drop := make([]bool, len(locs))
+	var removals []Removal
+
+	// 开头连续出现在上一章末尾的段落, 至少包含一个足够长的段落才视为重叠
+	prev := make(map[string]bool, len(t.tail))
+	for _, k := range t.tail {
+		prev[k] = true
+	}
+	overlap, significant := 0, false
+	for overlap < len(keys) && keys[overlap] != "" && prev[keys[overlap]] {
+		significant = significant || utf8.RuneCountInString(keys[overlap]) >= minLength
+		overlap++
+	}
+	if significant {
+		r := Removal{Kind: KindOverlap}
+		for i := 0; i < overlap; i++ {
+			drop[i] = true
+			r.Paragraphs = append(r.Paragraphs, texts[i])
+		}
+		removals = append(removals, r)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	repeat := Removal{Kind: KindRepeat}
+	for i, k := range keys {
+		if drop[i] || utf8.RuneCountInString(k) < minLength {
+			continue
+		}
+		if seen[k] {
+			drop[i] = true
+			repeat.Paragraphs = append(repeat.Paragraphs, texts[i])
+			continue
+		}
+		seen[k] = true
+	}
+	if len(repeat.Paragraphs) > 0 {
+		removals = append(removals, repeat)
+	}
+
+	// 记录保留下来的末尾段落供下一章比较
+	t.tail = t.tail[:0]
+	for i := len(keys) - 1; i >= 0 && len(t.tail) < tailSize; i-- {
+		if !drop[i] && keys[i] != "" {
+			t.tail = append(t.tail, keys[i])
+		}
+	}
+
+	if len(removals) == 0 {
+		return content, nil
+	}
+	var sb strings.Builder
+	last := 0
+	for i, loc := range locs {
+		if !drop[i] {
+			continue
+		}
+		sb.WriteString(content[last:loc[0]])
+		last = loc[1]
+	}
+	sb.WriteString(content[last:])
+	return sb.String(), removals
+}
+
+func text(paragraph string) string {
+	return strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(paragraph, "")))
+}
+
+// normalize 忽略空白差异
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,44 @@
+package dedup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepeatWithinChapter(t *testing.T) {
+	tr := NewTracker()
+	in := "<p>这是第一页最后一段内容。</p><p>嗯。</p><p>这是第一页最后一段内容。</p><p>嗯。</p><p>第二页。</p>"
+	got, removals := tr.Apply(in)
+	if want := "<p>这是第一页最后一段内容。</p><p>嗯。</p><p>嗯。</p><p>第二页。</p>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want := []Removal{{Kind: KindRepeat, Paragraphs: []string{"这是第一页最后一段内容。"}}}
+	if !reflect.DeepEqual(removals, want) {
+		t.Errorf("removals = %+v", removals)
+	}
+}
+
+func TestOverlapWithPreviousChapter(t *testing.T) {
+	tr := NewTracker()
+	tr.Apply("<p>第一章开头的内容。</p><p>第一章倒数第二段内容。</p><p>第一章 最后一段内容。</p>")
+
+	got, removals := tr.Apply("<p>第一章倒数第二段内容。</p><p>第一章最后一段内容。</p><p>第二章正文开始了。</p>")
+	if got != "<p>第二章正文开始了。</p>" {
+		t.Errorf("got %q", got)
+	}
+	if len(removals) != 1 || removals[0].Kind != KindOverlap || len(removals[0].Paragraphs) != 2 {
+		t.Errorf("removals = %+v", removals)
+	}
+
+	// 仅短段落相同不算重叠
+	tr.Apply("<p>第三章的最后一段内容。</p><p>好。</p>")
+	if got, removals := tr.Apply("<p>好。</p><p>第四章正文。</p>"); removals != nil || got != "<p>好。</p><p>第四章正文。</p>" {
+		t.Errorf("got %q, %+v", got, removals)
+	}
+
+	tr.Apply("<p>第五章的最后一段内容。</p>")
+	tr.Reset()
+	if _, removals := tr.Apply("<p>第五章的最后一段内容。</p>"); removals != nil {
+		t.Errorf("reset should forget the previous chapter: %+v", removals)
+	}
+}
@@ -0,0 +1,325 @@
+package dict
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"golang.org/x/net/html"
+)
+
+// 替换词典保存在 $HOME/.fynovel/dict.json, 可以直接手动编辑:
+//
+//	{ "entries": [ { "pattern": "ｗｗｗ", "replace": "", "regex": false, "book": "", "sourceId": 0 } ] }
+
+const fileName = "dict.json"
+
+type file struct {
+	Entries []model.DictEntry `json:"entries"`
+}
+
+// Scope 章节所属的书与书源, 决定哪些条目生效
+type Scope struct {
+	Book     string
+	SourceID int
+}
+
+var (
+	mu sync.Mutex
+	// 按文件修改时间缓存, 手动编辑文件后自动重新加载
+	cachedModTime time.Time
+	cachedSize    int64
+	cachedEntries []model.DictEntry
+	replacers     = make(map[Scope]*Replacer)
+)
+
+// Path 词典文件路径
+func Path() string {
+	return filepath.Join(config.DataDir(), fileName)
+}
+
+// Load 读取全部条目, 文件不存在时返回空
+func Load() ([]model.DictEntry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return append([]model.DictEntry(nil), entries...), nil
+}
+
+func load() ([]model.DictEntry, error) {
+	info, err := os.Stat(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		cachedModTime, cachedSize, cachedEntries = time.Time{}, 0, nil
+		replacers = make(map[Scope]*Replacer)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.ModTime().Equal(cachedModTime) && info.Size() == cachedSize {
+		return cachedEntries, nil
+	}
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid dictionary %s: %v", Path(), err)
+	}
+	cachedModTime, cachedSize, cachedEntries = info.ModTime(), info.Size(), f.Entries
+	replacers = make(map[Scope]*Replacer)
+	return cachedEntries, nil
+}
+
+func save(entries []model.DictEntry) error {
+	if err := os.MkdirAll(filepath.Dir(Path()), 0755); err != nil {
+		return fmt.Errorf("failed to create dictionary directory: %v", err)
+	}
+	data, err := json.MarshalIndent(file{Entries: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(Path(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write dictionary: %v", err)
+	}
+	// 强制下次读取时重新加载
+	cachedModTime = time.Time{}
+	return nil
+}
+
+// Put 新增或更新 (按 ID) 条目, 返回保存后的条目
+func Put(entry model.DictEntry) (model.DictEntry, error) {
+	if err := Validate(entry); err != nil {
+		return entry, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := load()
+	if err != nil {
+		return entry, err
+	}
+	entries = append([]model.DictEntry(nil), entries...)
+	if entry.ID == "" {
+		entry.ID = newID()
+		entries = append(entries, entry)
+	} else {
+		found := false
+		for i := range entries {
+			if entries[i].ID == entry.ID {
+				entries[i] = entry
+				found = true
+				break
+			}
+		}
+		if !found {
+			return entry, fmt.Errorf("dictionary entry not found: %s", entry.ID)
+		}
+	}
+	return entry, save(entries)
+}
+
+// Delete 删除条目
+func Delete(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	res := make([]model.DictEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.ID != id {
+			res = append(res, e)
+		}
+	}
+	if len(res) == len(entries) {
+		return fmt.Errorf("dictionary entry not found: %s", id)
+	}
+	return save(res)
+}
+
+// Validate 检查条目是否可用
+func Validate(entry model.DictEntry) error {
+	if entry.Pattern == "" {
+		return errors.New("pattern is empty")
+	}
+	if entry.Regex {
+		if _, err := regexp.Compile(entry.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", entry.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// Matches 条目是否适用于该范围
+func Matches(entry model.DictEntry, scope Scope) bool {
+	if entry.Disabled {
+		return false
+	}
+	if entry.Book != "" && entry.Book != scope.Book {
+		return false
+	}
+	return entry.SourceID == 0 || entry.SourceID == scope.SourceID
+}
+
+// ForScope 返回该范围生效的替换器 (已缓存)
+func ForScope(scope Scope) (*Replacer, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := replacers[scope]; ok {
+		return r, nil
+	}
+	var matched []model.DictEntry
+	for _, e := range entries {
+		// 手动编辑出错的条目直接跳过, 不影响其余条目
+		if Matches(e, scope) && Validate(e) == nil {
+			matched = append(matched, e)
+		}
+	}
+	r, err := NewReplacer(matched)
+	if err != nil {
+		return nil, err
+	}
+	replacers[scope] = r
+	return r, nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type rule struct {
+	literal string
+	re      *regexp.Regexp
+	replace string
+}
+
+// Replacer 按顺序执行的替换规则, 只作用于 HTML 中的文字, 不影响标签
+type Replacer struct {
+	rules []rule
+}
+
+// NewReplacer 编译条目, 按条目顺序执行
+func NewReplacer(entries []model.DictEntry) (*Replacer, error) {
+	r := &Replacer{}
+	for _, e := range entries {
+		if err := Validate(e); err != nil {
+			return nil, err
+		}
+		ru := rule{literal: e.Pattern, replace: e.Replace}
+		if e.Regex {
+			ru.re = regexp.MustCompile(e.Pattern)
+		}
+		r.rules = append(r.rules, ru)
+	}
+	return r, nil
+}
+
+// Empty 没有任何规则
+func (r *Replacer) Empty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+// ReplaceText 替换纯文本, 返回替换次数
+func (r *Replacer) ReplaceText(text string) (string, int) {
+	count := 0
+	for _, ru := range r.rules {
+		if ru.re != nil {
+			n := len(ru.re.FindAllStringIndex(text, -1))
+			if n > 0 {
+				text = ru.re.ReplaceAllString(text, ru.replace)
+				count += n
+			}
+			continue
+		}
+		if n := strings.Count(text, ru.literal); n > 0 {
+			text = strings.ReplaceAll(text, ru.literal, ru.replace)
+			count += n
+		}
+	}
+	return text, count
+}
+
+// Change 一处文字节点的替换结果
+type Change struct {
+	Before string
+	After  string
+	Count  int
+}
+
+// Apply 替换 HTML 片段中的文字, 返回结果与每个被修改的文字节点
+func (r *Replacer) Apply(content string) (string, []Change) {
+	if r.Empty() {
+		return content, nil
+	}
+	var sb strings.Builder
+	var changes []Change
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.TextToken {
+			sb.Write(z.Raw())
+			continue
+		}
+		text := string(z.Text())
+		replaced, n := r.ReplaceText(text)
+		if n == 0 {
+			sb.Write(z.Raw())
+			continue
+		}
+		changes = append(changes, Change{Before: text, After: replaced, Count: n})
+		sb.WriteString(html.EscapeString(replaced))
+	}
+	return sb.String(), changes
+}
+
+// Snippet 截取替换前后文字中第一处差异附近的片段, radius 为两侧保留的字数
+func Snippet(before, after string, radius int) (string, string) {
+	b, a := []rune(before), []rune(after)
+	start := 0
+	for start < len(b) && start < len(a) && b[start] == a[start] {
+		start++
+	}
+	// 从末尾找到差异结束的位置
+	endB, endA := len(b), len(a)
+	for endB > start && endA > start && b[endB-1] == a[endA-1] {
+		endB--
+		endA--
+	}
+	from := max(start-radius, 0)
+	cut := func(r []rune, end int) string {
+		to := min(end+radius, len(r))
+		s := string(r[from:to])
+		if from > 0 {
+			s = "…" + s
+		}
+		if to < len(r) {
+			s += "…"
+		}
+		return s
+	}
+	return cut(b, endB), cut(a, endA)
+}
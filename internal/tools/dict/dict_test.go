@@ -0,0 +1,92 @@
+package dict
+
+import (
+	"testing"
+
+	"fy-novel/internal/model"
+)
+
+func TestPutDeleteAndScope(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	global, err := Put(model.DictEntry{Pattern: "ｗｗｗ", Replace: ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if global.ID == "" {
+		t.Fatal("new entry should get an id")
+	}
+	if _, err := Put(model.DictEntry{Pattern: "小明", Replace: "小红", Book: "书一"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Put(model.DictEntry{Pattern: `(\d+)`, Replace: "[$1]", Regex: true, SourceID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Put(model.DictEntry{Pattern: "(", Regex: true}); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+	if _, err := Put(model.DictEntry{ID: "missing", Pattern: "a"}); err == nil {
+		t.Error("expected not found error")
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+
+	r, err := ForScope(Scope{Book: "书一", SourceID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, n := r.ReplaceText("ｗｗｗ小明12"); got != "小红12" || n != 2 {
+		t.Errorf("got %q, %d", got, n)
+	}
+	r, _ = ForScope(Scope{Book: "书二", SourceID: 2})
+	if got, _ := r.ReplaceText("小明12"); got != "小明[12]" {
+		t.Errorf("got %q", got)
+	}
+
+	// 更新后缓存失效
+	global.Disabled = true
+	if _, err := Put(global); err != nil {
+		t.Fatal(err)
+	}
+	r, _ = ForScope(Scope{Book: "书二", SourceID: 2})
+	if got, _ := r.ReplaceText("ｗｗｗ"); got != "ｗｗｗ" {
+		t.Errorf("disabled entry applied: %q", got)
+	}
+
+	if err := Delete(global.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(global.ID); err == nil {
+		t.Error("expected not found error")
+	}
+	if entries, _ := Load(); len(entries) != 2 {
+		t.Errorf("entries = %d, want 2", len(entries))
+	}
+}
+
+func TestApplyOnlyText(t *testing.T) {
+	r, err := NewReplacer([]model.DictEntry{{Pattern: "p", Replace: "<b>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, changes := r.Apply(`<p class="p">pa</p><p>x</p>`)
+	if want := `<p class="p">&lt;b&gt;a</p><p>x</p>`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(changes) != 1 || changes[0].Before != "pa" || changes[0].Count != 1 {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	before, after := Snippet("一二三四五六七八九十", "一二三四甲六七八九十", 2)
+	if before != "…三四五六七…" || after != "…三四甲六七…" {
+		t.Errorf("got %q, %q", before, after)
+	}
+}
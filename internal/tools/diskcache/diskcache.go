@@ -0,0 +1,218 @@
+// Package diskcache 以文件保存的键值缓存, 总大小超过上限时删除最久未使用的条目 (LRU)
+package diskcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"fy-novel/internal/model"
+)
+
+// Cache 每个条目一个文件, 文件名为键的哈希. 最近使用时间记录在文件的修改时间中,
+// 重启后仍按使用顺序淘汰. 同一目录只应由一个 Cache 管理
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]*entry
+	size    int64
+	hits    int64
+	misses  int64
+}
+
+type entry struct {
+	size int64
+	used time.Time
+}
+
+// New 创建缓存, maxBytes 不大于 0 时不限制大小
+func New(dir string, maxBytes int64) *Cache {
+	return &Cache{dir: dir, maxBytes: maxBytes}
+}
+
+// Dir 缓存目录
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// load 首次使用时读取目录中已有的条目
+func (c *Cache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]*entry)
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil || !info.Mode().IsRegular() || filepath.Ext(f.Name()) != "" {
+			continue
+		}
+		c.entries[f.Name()] = &entry{size: info.Size(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+}
+
+// Get 读取条目并记为最近使用
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	name := fileName(key)
+	path := filepath.Join(c.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.misses++
+		if e, ok := c.entries[name]; ok {
+			c.size -= e.size
+			delete(c.entries, name)
+		}
+		return nil, false
+	}
+	c.hits++
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	if e, ok := c.entries[name]; ok {
+		e.used = now
+	} else {
+		c.entries[name] = &entry{size: int64(len(data)), used: now}
+		c.size += int64(len(data))
+	}
+	return data, true
+}
+
+// Has 条目是否存在, 不计入命中统计也不改变使用顺序
+func (c *Cache) Has(key string) bool {
+	_, err := os.Stat(filepath.Join(c.dir, fileName(key)))
+	return err == nil
+}
+
+// Put 写入条目, 超出上限时淘汰最久未使用的条目
+func (c *Cache) Put(key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("cache error creating directory: %v", err)
+	}
+	name := fileName(key)
+	path := filepath.Join(c.dir, name)
+	// 先写临时文件再改名, 读取时不会读到写了一半的条目
+	tmp, err := os.CreateTemp(c.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cache error writing %s: %v", name, err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cache error writing %s: %v", name, err)
+	}
+	if e, ok := c.entries[name]; ok {
+		c.size -= e.size
+	}
+	c.entries[name] = &entry{size: int64(len(data)), used: time.Now()}
+	c.size += int64(len(data))
+	c.evict(name)
+	return nil
+}
+
+// Delete 删除条目, 条目不存在时不报错
+func (c *Cache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	return c.remove(fileName(key))
+}
+
+func (c *Cache) remove(name string) error {
+	if e, ok := c.entries[name]; ok {
+		c.size -= e.size
+		delete(c.entries, name)
+	}
+	err := os.Remove(filepath.Join(c.dir, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// evict 淘汰最久未使用的条目直到不超过上限, keep 为刚写入的条目, 不会被淘汰
+func (c *Cache) evict(keep string) {
+	if c.maxBytes <= 0 || c.size <= c.maxBytes {
+		return
+	}
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		if name != keep {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return c.entries[names[i]].used.Before(c.entries[names[j]].used)
+	})
+	for _, name := range names {
+		if c.size <= c.maxBytes {
+			return
+		}
+		_ = c.remove(name)
+	}
+}
+
+// SetMaxBytes 修改大小上限, 立即淘汰超出的条目
+func (c *Cache) SetMaxBytes(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	c.maxBytes = maxBytes
+	c.evict("")
+}
+
+// Clear 删除全部条目
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("cache error clearing %s: %v", c.dir, err)
+	}
+	c.entries = make(map[string]*entry)
+	c.size = 0
+	c.loaded = true
+	return nil
+}
+
+// Stats 缓存统计, 命中次数从进程启动时开始计算
+func (c *Cache) Stats() model.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	return model.CacheStats{
+		Entries: len(c.entries),
+		Size:    c.size,
+		MaxSize: c.maxBytes,
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
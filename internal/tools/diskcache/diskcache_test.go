@@ -0,0 +1,66 @@
+package diskcache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c := New(dir, 25)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("empty cache hit")
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte(strings.Repeat(key, 10))); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 读取 a 后 b 成为最久未使用的条目
+	if data, ok := c.Get("a"); !ok || string(data) != "aaaaaaaaaa" {
+		t.Fatalf("get a = %q, %v", data, ok)
+	}
+	c.Put("c", []byte("cccccccccc"))
+	if c.Has("b") {
+		t.Error("b should be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a was used recently and should be kept")
+	}
+	stats := c.Stats()
+	if stats.Entries != 2 || stats.Size != 20 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// 重新打开时从目录恢复条目与使用顺序
+	reopened := New(dir, 25)
+	if s := reopened.Stats(); s.Entries != 2 || s.Size != 20 {
+		t.Errorf("reopened stats = %+v", s)
+	}
+	reopened.SetMaxBytes(10)
+	if _, ok := reopened.Get("c"); ok {
+		t.Error("c is older than a and should be evicted by the new limit")
+	}
+
+	if err := reopened.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Delete("a"); err != nil {
+		t.Errorf("delete twice: %v", err)
+	}
+	reopened.Put("d", []byte("d"))
+	if err := reopened.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("clear should remove the directory")
+	}
+	if s := reopened.Stats(); s.Entries != 0 || s.Size != 0 {
+		t.Errorf("stats after clear = %+v", s)
+	}
+}
@@ -0,0 +1,404 @@
+package epub
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	mimetype       = "application/epub+zip"
+	contentDir     = "OEBPS"
+	textDir        = "text"
+	styleDir       = "styles"
+	fontDir        = "fonts"
+	imageDir       = "images"
+	navFilename    = "nav.xhtml"
+	ncxFilename    = "toc.ncx"
+	opfFilename    = "content.opf"
+	coverFilename  = "cover.xhtml"
+	titleFilename  = "title.xhtml"
+	fontFamilyName = "fy-novel-body"
+)
+
+// Metadata 书籍级别的元数据, 写入 content.opf 与书名页
+type Metadata struct {
+	Identifier  string
+	Title       string
+	Author      string
+	Description string
+	Category    string
+	Lang        string
+}
+
+// Style 控制生成书籍的排版样式
+type Style struct {
+	// Vertical 竖排 (writing-mode: vertical-rl), 并按从右到左翻页
+	Vertical bool
+	// UserCSS 用户自定义样式, 链接在内置样式之后, 可覆盖默认规则
+	UserCSS []byte
+	// Font 嵌入的正文字体 (ttf/otf), FontName 为其文件名
+	Font     []byte
+	FontName string
+}
+
+type manifestItem struct {
+	ID         string
+	Href       string
+	MediaType  string
+	Properties string
+}
+
+type navPoint struct {
+	Title string
+	Href  string
+}
+
+type landmark struct {
+	Type  string
+	Title string
+	Href  string
+}
+
+// Writer 以流的方式生成 EPUB3 文件: 章节写入后即落入 zip,
+// 目录、导航与 content.opf 在 Close 时统一生成
+type Writer struct {
+	zw        *zip.Writer
+	meta      Metadata
+	style     Style
+	styles    []string
+	manifest  []manifestItem
+	spine     []string
+	toc       []navPoint
+	landmarks []landmark
+	coverID   string
+	chapters  int
+	closed    bool
+}
+
+// NewWriter 写入 mimetype、container.xml 与样式资源, 返回可追加章节的 Writer
+func NewWriter(w io.Writer, meta Metadata, style Style) (*Writer, error) {
+	if meta.Lang == "" {
+		meta.Lang = "zh-CN"
+	}
+	ew := &Writer{
+		zw:    zip.NewWriter(w),
+		meta:  meta,
+		style: style,
+	}
+
+	// mimetype 必须是第一个文件且不能压缩
+	mw, err := ew.zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, fmt.Errorf("epub error writing mimetype: %v", err)
+	}
+	if _, err := io.WriteString(mw, mimetype); err != nil {
+		return nil, fmt.Errorf("epub error writing mimetype: %v", err)
+	}
+	if err := ew.writeFile("META-INF/container.xml", []byte(containerXML)); err != nil {
+		return nil, err
+	}
+	if err := ew.addStyles(); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+// SetCover 添加封面图片与封面页, 需在添加书名页与章节之前调用
+func (w *Writer) SetCover(data []byte, name string) error {
+	mediaType := imageMediaType(name)
+	if mediaType == "" {
+		return fmt.Errorf("epub unsupported cover image: %s", name)
+	}
+	href := path.Join(imageDir, "cover"+path.Ext(name))
+	if err := w.addResource("cover-image", href, mediaType, "cover-image", data); err != nil {
+		return err
+	}
+	w.coverID = "cover-image"
+
+	body := fmt.Sprintf(
+		`<section class="cover" epub:type="cover"><img src="../%s" alt="%s"/></section>`,
+		href,
+		escapeXML(w.meta.Title),
+	)
+	href = path.Join(textDir, coverFilename)
+	if err := w.addPage("cover", href, w.meta.Title, body); err != nil {
+		return err
+	}
+	w.landmarks = append(w.landmarks, landmark{Type: "cover", Title: "封面", Href: href})
+	return nil
+}
+
+// AddTitlePage 根据元数据生成书名页 (书名、作者、分类、简介)
+func (w *Writer) AddTitlePage() error {
+	var sb strings.Builder
+	sb.WriteString(`<section class="title-page" epub:type="titlepage">`)
+	sb.WriteString("<h1>" + escapeXML(w.meta.Title) + "</h1>")
+	if w.meta.Author != "" {
+		sb.WriteString(`<p class="author">` + escapeXML(w.meta.Author) + "</p>")
+	}
+	if w.meta.Category != "" {
+		sb.WriteString(`<p class="category">` + escapeXML(w.meta.Category) + "</p>")
+	}
+	if w.meta.Description != "" {
+		sb.WriteString(`<div class="intro">`)
+		for _, line := range strings.Split(w.meta.Description, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				sb.WriteString("<p>" + escapeXML(line) + "</p>")
+			}
+		}
+		sb.WriteString("</div>")
+	}
+	sb.WriteString("</section>")
+
+	href := path.Join(textDir, titleFilename)
+	if err := w.addPage("title-page", href, w.meta.Title, sb.String()); err != nil {
+		return err
+	}
+	w.landmarks = append(w.landmarks, landmark{Type: "titlepage", Title: "书名页", Href: href})
+	return nil
+}
+
+// AddChapter 追加一个章节, body 为章节的 HTML 片段, 会被规范化为 XHTML
+func (w *Writer) AddChapter(title, body string) error {
+	if w.closed {
+		return fmt.Errorf("epub writer already closed")
+	}
+	content, err := toXHTML(body)
+	if err != nil {
+		return fmt.Errorf("epub error converting chapter %q: %v", title, err)
+	}
+	w.chapters++
+	id := fmt.Sprintf("chapter%05d", w.chapters)
+	href := path.Join(textDir, id+".xhtml")
+	if err := w.addPage(id, href, title, content); err != nil {
+		return err
+	}
+	if w.chapters == 1 {
+		w.landmarks = append(w.landmarks, landmark{Type: "bodymatter", Title: "正文", Href: href})
+	}
+	w.toc = append(w.toc, navPoint{Title: title, Href: href})
+	return nil
+}
+
+// Close 写入导航文档、NCX 与 content.opf, 并结束 zip
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.writeNav(); err != nil {
+		return err
+	}
+	if err := w.writeNcx(); err != nil {
+		return err
+	}
+	if err := w.writeOpf(); err != nil {
+		return err
+	}
+	if err := w.zw.Close(); err != nil {
+		return fmt.Errorf("epub error closing zip: %v", err)
+	}
+	return nil
+}
+
+func (w *Writer) addStyles() error {
+	defaultCSS, err := styleFS.ReadFile("style/default.css")
+	if err != nil {
+		return fmt.Errorf("epub error reading default style: %v", err)
+	}
+	if err := w.addStyle("default.css", defaultCSS); err != nil {
+		return err
+	}
+
+	if w.style.Vertical {
+		verticalCSS, err := styleFS.ReadFile("style/vertical.css")
+		if err != nil {
+			return fmt.Errorf("epub error reading vertical style: %v", err)
+		}
+		if err := w.addStyle("vertical.css", verticalCSS); err != nil {
+			return err
+		}
+	}
+
+	if len(w.style.Font) > 0 {
+		ext := strings.ToLower(path.Ext(w.style.FontName))
+		mediaType := fontMediaType(ext)
+		if mediaType == "" {
+			return fmt.Errorf("epub unsupported font: %s", w.style.FontName)
+		}
+		href := path.Join(fontDir, "body"+ext)
+		if err := w.addResource("font-body", href, mediaType, "", w.style.Font); err != nil {
+			return err
+		}
+		fontCSS := fmt.Sprintf(fontFaceCSS, fontFamilyName, "../"+href, fontFamilyName)
+		if err := w.addStyle("font.css", []byte(fontCSS)); err != nil {
+			return err
+		}
+	}
+
+	if len(w.style.UserCSS) > 0 {
+		if err := w.addStyle("user.css", w.style.UserCSS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) addStyle(name string, data []byte) error {
+	href := path.Join(styleDir, name)
+	id := "style-" + strings.TrimSuffix(name, path.Ext(name))
+	if err := w.addResource(id, href, "text/css", "", data); err != nil {
+		return err
+	}
+	w.styles = append(w.styles, href)
+	return nil
+}
+
+func (w *Writer) addPage(id, href, title, body string) error {
+	styles := make([]string, 0, len(w.styles))
+	for _, s := range w.styles {
+		styles = append(styles, "../"+s)
+	}
+	page, err := render(pageTemplate, struct {
+		Lang   string
+		Title  string
+		Styles []string
+		Body   string
+	}{w.meta.Lang, title, styles, body})
+	if err != nil {
+		return fmt.Errorf("epub error rendering %s: %v", href, err)
+	}
+	if err := w.addResource(id, href, "application/xhtml+xml", "", []byte(page)); err != nil {
+		return err
+	}
+	w.spine = append(w.spine, id)
+	return nil
+}
+
+func (w *Writer) addResource(id, href, mediaType, properties string, data []byte) error {
+	if err := w.writeFile(path.Join(contentDir, href), data); err != nil {
+		return err
+	}
+	w.manifest = append(w.manifest, manifestItem{
+		ID:         id,
+		Href:       href,
+		MediaType:  mediaType,
+		Properties: properties,
+	})
+	return nil
+}
+
+func (w *Writer) writeFile(name string, data []byte) error {
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("epub error creating %s: %v", name, err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		return fmt.Errorf("epub error writing %s: %v", name, err)
+	}
+	return nil
+}
+
+func (w *Writer) writeNav() error {
+	landmarks := append([]landmark(nil), w.landmarks...)
+	landmarks = append(landmarks, landmark{Type: "toc", Title: "目录", Href: navFilename})
+
+	nav, err := render(navTemplate, struct {
+		Lang      string
+		Title     string
+		Styles    []string
+		Toc       []navPoint
+		Landmarks []landmark
+	}{w.meta.Lang, w.meta.Title, w.styles, w.toc, landmarks})
+	if err != nil {
+		return fmt.Errorf("epub error rendering nav: %v", err)
+	}
+	if err := w.addResource("nav", navFilename, "application/xhtml+xml", "nav", []byte(nav)); err != nil {
+		return err
+	}
+	// 目录页紧跟在封面与书名页之后
+	pos := 0
+	for pos < len(w.spine) && (w.spine[pos] == "cover" || w.spine[pos] == "title-page") {
+		pos++
+	}
+	w.spine = append(w.spine[:pos], append([]string{"nav"}, w.spine[pos:]...)...)
+	return nil
+}
+
+func (w *Writer) writeNcx() error {
+	ncx, err := render(ncxTemplate, struct {
+		Identifier string
+		Title      string
+		Author     string
+		Toc        []navPoint
+	}{w.meta.Identifier, w.meta.Title, w.meta.Author, w.toc})
+	if err != nil {
+		return fmt.Errorf("epub error rendering ncx: %v", err)
+	}
+	return w.addResource("ncx", ncxFilename, "application/x-dtbncx+xml", "", []byte(ncx))
+}
+
+func (w *Writer) writeOpf() error {
+	direction := "ltr"
+	if w.style.Vertical {
+		direction = "rtl"
+	}
+	opf, err := render(opfTemplate, struct {
+		Metadata
+		Modified  string
+		CoverID   string
+		Direction string
+		Manifest  []manifestItem
+		Spine     []string
+	}{
+		Metadata:  w.meta,
+		Modified:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		CoverID:   w.coverID,
+		Direction: direction,
+		Manifest:  w.manifest,
+		Spine:     w.spine,
+	})
+	if err != nil {
+		return fmt.Errorf("epub error rendering opf: %v", err)
+	}
+	return w.writeFile(path.Join(contentDir, opfFilename), []byte(opf))
+}
+
+func imageMediaType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return ""
+	}
+}
+
+func fontMediaType(ext string) string {
+	switch ext {
+	case ".ttf":
+		return "font/ttf"
+	case ".otf":
+		return "font/otf"
+	case ".woff":
+		return "font/woff"
+	case ".woff2":
+		return "font/woff2"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,229 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriterZipStructure(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Metadata{
+		Identifier:  "urn:fy-novel:test",
+		Title:       "测试 & 书",
+		Author:      "作者",
+		Description: "第一行简介\n第二行简介",
+		Category:    "玄幻",
+	}, Style{
+		Vertical: true,
+		UserCSS:  []byte("p { color: #333; }"),
+		Font:     []byte("fake font"),
+		FontName: "kai.ttf",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetCover([]byte("fake image"), "cover.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddTitlePage(); err != nil {
+		t.Fatal(err)
+	}
+	chapters := []string{
+		`<section epub:type="chapter"><h2>第一章</h2><p>段落<br>换行&nbsp;</p></section>`,
+		`<p>第二章正文</p><img src="x.png">`,
+	}
+	for i, c := range chapters {
+		if err := w.AddChapter([]string{"第一章", "第二章 <上>"}[i], c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := zr.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store {
+		t.Fatalf("first entry = %s (method %d), want stored mimetype", first.Name, first.Method)
+	}
+	if got := readEntry(t, first); got != mimetype {
+		t.Fatalf("mimetype = %q", got)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{
+		"META-INF/container.xml",
+		"OEBPS/content.opf",
+		"OEBPS/nav.xhtml",
+		"OEBPS/toc.ncx",
+		"OEBPS/styles/default.css",
+		"OEBPS/styles/vertical.css",
+		"OEBPS/styles/font.css",
+		"OEBPS/styles/user.css",
+		"OEBPS/fonts/body.ttf",
+		"OEBPS/images/cover.jpg",
+		"OEBPS/text/cover.xhtml",
+		"OEBPS/text/title.xhtml",
+		"OEBPS/text/chapter00001.xhtml",
+		"OEBPS/text/chapter00002.xhtml",
+	} {
+		if files[name] == nil {
+			t.Errorf("missing entry %s", name)
+		}
+	}
+
+	// 所有 XML 文档必须格式良好
+	for name, f := range files {
+		if strings.HasSuffix(name, ".xhtml") || strings.HasSuffix(name, ".opf") ||
+			strings.HasSuffix(name, ".ncx") || strings.HasSuffix(name, ".xml") {
+			if err := wellFormed(readEntry(t, f)); err != nil {
+				t.Errorf("%s is not well-formed: %v", name, err)
+			}
+		}
+	}
+
+	var opf struct {
+		Version  string `xml:"version,attr"`
+		Manifest []struct {
+			ID         string `xml:"id,attr"`
+			Href       string `xml:"href,attr"`
+			Properties string `xml:"properties,attr"`
+		} `xml:"manifest>item"`
+		Spine struct {
+			Direction string `xml:"page-progression-direction,attr"`
+			Items     []struct {
+				IDRef string `xml:"idref,attr"`
+			} `xml:"itemref"`
+		} `xml:"spine"`
+	}
+	if err := xml.Unmarshal([]byte(readEntry(t, files["OEBPS/content.opf"])), &opf); err != nil {
+		t.Fatal(err)
+	}
+	if opf.Version != "3.0" {
+		t.Errorf("opf version = %s", opf.Version)
+	}
+	if opf.Spine.Direction != "rtl" {
+		t.Errorf("page-progression-direction = %s, want rtl", opf.Spine.Direction)
+	}
+	properties := make(map[string]string)
+	for _, item := range opf.Manifest {
+		properties[item.Href] = item.Properties
+		if files["OEBPS/"+item.Href] == nil {
+			t.Errorf("manifest item %s not in zip", item.Href)
+		}
+	}
+	if properties["nav.xhtml"] != "nav" {
+		t.Error("nav.xhtml not declared with nav property")
+	}
+	if properties["images/cover.jpg"] != "cover-image" {
+		t.Error("cover image not declared with cover-image property")
+	}
+	var spine []string
+	for _, item := range opf.Spine.Items {
+		spine = append(spine, item.IDRef)
+	}
+	wantSpine := "cover title-page nav chapter00001 chapter00002"
+	if strings.Join(spine, " ") != wantSpine {
+		t.Errorf("spine = %v, want %s", spine, wantSpine)
+	}
+
+	nav := readEntry(t, files["OEBPS/nav.xhtml"])
+	for _, want := range []string{
+		`epub:type="toc"`,
+		`epub:type="landmarks"`,
+		`<a epub:type="cover" href="text/cover.xhtml">`,
+		`<a epub:type="titlepage" href="text/title.xhtml">`,
+		`<a epub:type="bodymatter" href="text/chapter00001.xhtml">`,
+		`<a epub:type="toc" href="nav.xhtml">`,
+		`第二章 &lt;上&gt;`,
+	} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("nav.xhtml missing %s", want)
+		}
+	}
+
+	chapter := readEntry(t, files["OEBPS/text/chapter00001.xhtml"])
+	for _, want := range []string{
+		`<link rel="stylesheet" type="text/css" href="../styles/default.css"/>`,
+		`<link rel="stylesheet" type="text/css" href="../styles/user.css"/>`,
+		`<br/>`,
+	} {
+		if !strings.Contains(chapter, want) {
+			t.Errorf("chapter missing %s", want)
+		}
+	}
+	if css := readEntry(t, files["OEBPS/styles/font.css"]); !strings.Contains(css, "../fonts/body.ttf") {
+		t.Errorf("font.css does not reference embedded font: %s", css)
+	}
+	title := readEntry(t, files["OEBPS/text/title.xhtml"])
+	if !strings.Contains(title, "<p>第二行简介</p>") || !strings.Contains(title, "测试 &amp; 书") {
+		t.Errorf("unexpected title page: %s", title)
+	}
+}
+
+func TestWriterHorizontal(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Metadata{Identifier: "id", Title: "书"}, Style{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddChapter("第一章", "<p>正文</p>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "OEBPS/styles/vertical.css", "OEBPS/styles/font.css", "OEBPS/styles/user.css":
+			t.Errorf("unexpected entry %s", f.Name)
+		case "OEBPS/content.opf":
+			if !strings.Contains(readEntry(t, f), `page-progression-direction="ltr"`) {
+				t.Error("expected ltr page progression")
+			}
+		}
+	}
+}
+
+func readEntry(t *testing.T, f *zip.File) string {
+	t.Helper()
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func wellFormed(doc string) error {
+	d := xml.NewDecoder(strings.NewReader(doc))
+	d.Strict = true
+	d.Entity = xml.HTMLEntity
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package epub
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// 读取 EPUB 时单个文件的大小上限
+const maxReadSize = 32 << 20
+
+// Info 从 EPUB 中读取的书名、作者与封面
+type Info struct {
+	Title       string
+	Author      string
+	Description string
+	// 封面图片在 zip 中的路径与类型, 没有封面时为空
+	CoverPath      string
+	CoverMediaType string
+}
+
+var ErrNoCover = errors.New("epub has no cover image")
+
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type opf struct {
+	Title       []string `xml:"metadata>title"`
+	Creator     []string `xml:"metadata>creator"`
+	Description []string `xml:"metadata>description"`
+	Meta        []struct {
+		Name    string `xml:"name,attr"`
+		Content string `xml:"content,attr"`
+	} `xml:"metadata>meta"`
+	Items []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+}
+
+// ReadInfo 读取 EPUB 的元数据, 兼容 EPUB2 的 <meta name="cover"> 与 EPUB3 的 cover-image
+func ReadInfo(filePath string) (Info, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return Info{}, fmt.Errorf("epub error opening %s: %v", filePath, err)
+	}
+	defer zr.Close()
+	return readInfo(&zr.Reader)
+}
+
+// Cover 读取 EPUB 中的封面图片, 返回图片内容与类型
+func Cover(filePath string) ([]byte, string, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("epub error opening %s: %v", filePath, err)
+	}
+	defer zr.Close()
+	info, err := readInfo(&zr.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.CoverPath == "" {
+		return nil, "", ErrNoCover
+	}
+	data, err := readFile(&zr.Reader, info.CoverPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, info.CoverMediaType, nil
+}
+
+func readInfo(zr *zip.Reader) (Info, error) {
+	var c container
+	if err := readXML(zr, "META-INF/container.xml", &c); err != nil {
+		return Info{}, err
+	}
+	if len(c.Rootfiles) == 0 {
+		return Info{}, errors.New("epub container has no rootfile")
+	}
+	opfPath := c.Rootfiles[0].FullPath
+	var pkg opf
+	if err := readXML(zr, opfPath, &pkg); err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		Title:       first(pkg.Title),
+		Author:      first(pkg.Creator),
+		Description: first(pkg.Description),
+	}
+	coverID := ""
+	for _, m := range pkg.Meta {
+		if m.Name == "cover" {
+			coverID = m.Content
+		}
+	}
+	for _, item := range pkg.Items {
+		isCover := item.ID == coverID || strings.Contains(" "+item.Properties+" ", " cover-image ")
+		if isCover && strings.HasPrefix(item.MediaType, "image/") {
+			// href 相对于 content.opf 所在目录
+			info.CoverPath = path.Join(path.Dir(opfPath), item.Href)
+			info.CoverMediaType = item.MediaType
+			break
+		}
+	}
+	return info, nil
+}
+
+func readXML(zr *zip.Reader, name string, v any) error {
+	data, err := readFile(zr, name)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("epub error parsing %s: %v", name, err)
+	}
+	return nil
+}
+
+func readFile(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("epub error reading %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxReadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("epub error reading %s: %v", name, err)
+	}
+	if len(data) > maxReadSize {
+		return nil, fmt.Errorf("epub file %s is too large", name)
+	}
+	return data, nil
+}
+
+func first(values []string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package epub
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestBook(t *testing.T, cover bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "book.epub")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := NewWriter(f, Metadata{Identifier: "urn:fy-novel:test", Title: "测试 & 书", Author: "作者", Description: "简介"}, Style{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cover {
+		if err := w.SetCover([]byte("fake image"), "cover.png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddChapter("第一章", "<p>正文</p>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadInfo(t *testing.T) {
+	path := writeTestBook(t, true)
+	info, err := ReadInfo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "测试 & 书" || info.Author != "作者" || info.Description != "简介" {
+		t.Errorf("info = %+v", info)
+	}
+	data, mediaType, err := Cover(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "fake image" || mediaType != "image/png" {
+		t.Errorf("cover = %q %q", data, mediaType)
+	}
+}
+
+func TestCoverMissing(t *testing.T) {
+	if _, _, err := Cover(writeTestBook(t, false)); !errors.Is(err, ErrNoCover) {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := ReadInfo(filepath.Join(t.TempDir(), "nope.epub")); err == nil {
+		t.Error("missing file should fail")
+	}
+}
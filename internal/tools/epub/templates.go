@@ -0,0 +1,159 @@
+package epub
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"strings"
+	"text/template"
+)
+
+//go:embed style/*.css
+var styleFS embed.FS
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const fontFaceCSS = `@charset "utf-8";
+
+@font-face {
+  font-family: "%s";
+  src: url("%s");
+}
+
+body {
+  font-family: "%s", serif;
+}
+`
+
+var (
+	pageTemplate = template.Must(template.New("page").Funcs(funcs).Parse(
+		`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{ xml .Lang }}" lang="{{ xml .Lang }}">
+<head>
+  <meta charset="utf-8"/>
+  <title>{{ xml .Title }}</title>
+{{- range .Styles }}
+  <link rel="stylesheet" type="text/css" href="{{ xml . }}"/>
+{{- end }}
+</head>
+<body>
+{{ .Body }}
+</body>
+</html>
+`))
+
+	navTemplate = template.Must(template.New("nav").Funcs(funcs).Parse(
+		`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{ xml .Lang }}" lang="{{ xml .Lang }}">
+<head>
+  <meta charset="utf-8"/>
+  <title>{{ xml .Title }}</title>
+{{- range .Styles }}
+  <link rel="stylesheet" type="text/css" href="{{ xml . }}"/>
+{{- end }}
+</head>
+<body>
+  <nav epub:type="toc" id="toc" role="doc-toc">
+    <h1>目录</h1>
+    <ol>
+{{- range .Toc }}
+      <li><a href="{{ xml .Href }}">{{ xml .Title }}</a></li>
+{{- end }}
+    </ol>
+  </nav>
+  <nav epub:type="landmarks" id="landmarks" hidden="hidden">
+    <h2>导航</h2>
+    <ol>
+{{- range .Landmarks }}
+      <li><a epub:type="{{ xml .Type }}" href="{{ xml .Href }}">{{ xml .Title }}</a></li>
+{{- end }}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+	ncxTemplate = template.Must(template.New("ncx").Funcs(funcs).Parse(
+		`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{ xml .Identifier }}"/>
+    <meta name="dtb:depth" content="1"/>
+    <meta name="dtb:totalPageCount" content="0"/>
+    <meta name="dtb:maxPageNumber" content="0"/>
+  </head>
+  <docTitle><text>{{ xml .Title }}</text></docTitle>
+  <docAuthor><text>{{ xml .Author }}</text></docAuthor>
+  <navMap>
+{{- range $i, $p := .Toc }}
+    <navPoint id="navPoint-{{ inc $i }}" playOrder="{{ inc $i }}">
+      <navLabel><text>{{ xml $p.Title }}</text></navLabel>
+      <content src="{{ xml $p.Href }}"/>
+    </navPoint>
+{{- end }}
+  </navMap>
+</ncx>
+`))
+
+	opfTemplate = template.Must(template.New("opf").Funcs(funcs).Parse(
+		`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id" xml:lang="{{ xml .Lang }}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="pub-id">{{ xml .Identifier }}</dc:identifier>
+    <dc:title>{{ xml .Title }}</dc:title>
+    <dc:language>{{ xml .Lang }}</dc:language>
+{{- if .Author }}
+    <dc:creator id="creator">{{ xml .Author }}</dc:creator>
+    <meta refines="#creator" property="role" scheme="marc:relators">aut</meta>
+{{- end }}
+{{- if .Description }}
+    <dc:description>{{ xml .Description }}</dc:description>
+{{- end }}
+{{- if .Category }}
+    <dc:subject>{{ xml .Category }}</dc:subject>
+{{- end }}
+    <meta property="dcterms:modified">{{ .Modified }}</meta>
+{{- if .CoverID }}
+    <meta name="cover" content="{{ .CoverID }}"/>
+{{- end }}
+  </metadata>
+  <manifest>
+{{- range .Manifest }}
+    <item id="{{ xml .ID }}" href="{{ xml .Href }}" media-type="{{ .MediaType }}"{{ if .Properties }} properties="{{ .Properties }}"{{ end }}/>
+{{- end }}
+  </manifest>
+  <spine toc="ncx" page-progression-direction="{{ .Direction }}">
+{{- range .Spine }}
+    <itemref idref="{{ xml . }}"/>
+{{- end }}
+  </spine>
+</package>
+`))
+)
+
+var funcs = template.FuncMap{
+	"xml": escapeXML,
+	"inc": func(i int) int { return i + 1 },
+}
+
+func render(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func escapeXML(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
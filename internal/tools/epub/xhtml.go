@@ -0,0 +1,57 @@
+package epub
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// toXHTML 将 HTML 片段重新序列化为格式良好的 XHTML:
+// 空元素自闭合, 文本与属性按 XML 规则转义, 丢弃注释与脚本
+func toXHTML(fragment string) (string, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, n := range nodes {
+		writeXHTML(&sb, n)
+	}
+	return sb.String(), nil
+}
+
+func writeXHTML(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(escapeXML(n.Data))
+	case html.ElementNode:
+		if n.Data == "script" {
+			return
+		}
+		sb.WriteString("<" + n.Data)
+		for _, attr := range n.Attr {
+			name := attr.Key
+			if attr.Namespace != "" {
+				name = attr.Namespace + ":" + attr.Key
+			}
+			sb.WriteString(" " + name + `="` + escapeXML(attr.Val) + `"`)
+		}
+		if voidElements[n.Data] {
+			sb.WriteString("/>")
+			return
+		}
+		sb.WriteString(">")
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeXHTML(sb, c)
+		}
+		sb.WriteString("</" + n.Data + ">")
+	}
+}
@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+// Stage 正文清洗的一个阶段, 由 Factory 按书源规则创建, 正则在创建时预编译
+type Stage interface {
+	Apply(content string, ctx *Context) string
+}
+
+// Context 清洗单个章节时可用的信息
+type Context struct {
+	Title string
+}
+
+// Factory 创建清洗阶段, 返回 nil 表示该规则下此阶段无事可做
+type Factory func(rule model.Rule, conf config.Info) (Stage, error)
+
+type registration struct {
+	name    string
+	order   int
+	factory Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+
+	pipelineCache sync.Map
+)
+
+// Register 注册清洗阶段, order 决定未配置顺序时的默认执行顺序
+func Register(name string, order int, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = registration{name: name, order: order, factory: factory}
+	// 注册表变化后缓存的流水线可能不再准确
+	pipelineCache.Clear()
+}
+
+// DefaultStages 按默认顺序返回全部已注册的阶段
+func DefaultStages() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	regs := make([]registration, 0, len(registry))
+	for _, r := range registry {
+		regs = append(regs, r)
+	}
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].order < regs[j].order
+	})
+	names := make([]string, 0, len(regs))
+	for _, r := range regs {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// Pipeline 按顺序执行的清洗阶段
+type Pipeline struct {
+	names  []string
+	stages []Stage
+}
+
+// New 按配置的阶段顺序创建流水线, 并去除书源规则中关闭的阶段
+func New(rule model.Rule, conf config.Info) (*Pipeline, error) {
+	names := conf.Filter.Stages
+	if len(names) == 0 {
+		names = DefaultStages()
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p := &Pipeline{}
+	for _, name := range names {
+		reg, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter stage: %s", name)
+		}
+		if slices.Contains(rule.Chapter.DisableFilters, name) || slices.Contains(p.names, name) {
+			continue
+		}
+		stage, err := reg.factory(rule, conf)
+		if err != nil {
+			return nil, fmt.Errorf("filter stage %s: %v", name, err)
+		}
+		if stage == nil {
+			continue
+		}
+		p.names = append(p.names, name)
+		p.stages = append(p.stages, stage)
+	}
+	return p, nil
+}
+
+// Get 返回缓存的流水线, 书源规则或清洗配置变化时重新创建
+func Get(rule model.Rule, conf config.Info) (*Pipeline, error) {
+	key := cacheKey(rule, conf)
+	if v, ok := pipelineCache.Load(key); ok {
+		return v.(*Pipeline), nil
+	}
+	p, err := New(rule, conf)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := pipelineCache.LoadOrStore(key, p)
+	return actual.(*Pipeline), nil
+}
+
+func cacheKey(rule model.Rule, conf config.Info) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\x00%s\x00%s\x00%q\x00%q",
+		rule.ID, rule.Chapter.FilterTxt, rule.Chapter.FilterTag, rule.Chapter.DisableFilters, conf.Filter.Stages)
+	for _, r := range conf.Filter.Replacements {
+		fmt.Fprintf(&sb, "\x00%q\x00%q", r.Pattern, r.Replace)
+	}
+	return sb.String()
+}
+
+// Stages 返回实际生效的阶段名
+func (p *Pipeline) Stages() []string {
+	return slices.Clone(p.names)
+}
+
+// Apply 依次执行各阶段, 返回清洗后的 HTML 片段
+func (p *Pipeline) Apply(title, content string) string {
+	ctx := &Context{Title: title}
+	for _, stage := range p.stages {
+		content = stage.Apply(content, ctx)
+	}
+	return strings.TrimSpace(content)
+}
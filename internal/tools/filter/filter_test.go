@@ -0,0 +1,205 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+func newStage(t *testing.T, name string, rule model.Rule, conf config.Info) Stage {
+	t.Helper()
+	stage, err := registry[name].factory(rule, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stage == nil {
+		t.Fatalf("stage %s is nil", name)
+	}
+	return stage
+}
+
+func TestEntityStage(t *testing.T) {
+	stage := newStage(t, "entity", model.Rule{}, config.Info{})
+	in := "&nbsp;&nbsp;&emsp;正文&#12288;甲&amp;乙&lt;丙&gt;&bogus;&#x3000;"
+	want := "正文甲&amp;乙&lt;丙&gt;"
+	if got := stage.Apply(in, &Context{}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// 不是实体的 & 保留
+	if got := stage.Apply("A & B; C", &Context{}); got != "A & B; C" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestAdsStage(t *testing.T) {
+	rule := model.Rule{}
+	if stage, err := newAdsStage(rule, config.Info{}); stage != nil || err != nil {
+		t.Errorf("empty filterTxt should disable the stage: %v, %v", stage, err)
+	}
+	rule.Chapter.FilterTxt = `天才一秒记住本站地址：\[梦书中文\] .+最快更新！无广告！`
+	stage := newStage(t, "ads", rule, config.Info{})
+	in := "第一段<br><br>天才一秒记住本站地址：[梦书中文] http://x 最快更新！无广告！<br><br>第二段"
+	if got := stage.Apply(in, &Context{}); got != "第一段<br><br><br><br>第二段" {
+		t.Errorf("got %q", got)
+	}
+
+	rule.Chapter.FilterTxt = `(`
+	if _, err := newAdsStage(rule, config.Info{}); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestTagStage(t *testing.T) {
+	rule := model.Rule{}
+	rule.Chapter.FilterTag = "script div.ad"
+	stage := newStage(t, "tag", rule, config.Info{})
+	in := `正文一<br/><script>alert(1)</script><div class="ad">广告</div><div>保留</div>正文二`
+	want := `正文一<br/><div>保留</div>正文二`
+	if got := stage.Apply(in, &Context{}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDuplicateTitleStage(t *testing.T) {
+	stage := newStage(t, "duplicate-title", model.Rule{}, config.Info{})
+	cases := []struct{ title, in, want string }{
+		{"第一章 开始", "  第一章 开始<br>正文", "<br>正文"},
+		{" 第一章 开始 ", "第一章 开始 正文", "正文"},
+		{"第一章", "正文第一章", "正文第一章"},
+		{"第3章 拜师", "<br /><br />第3章 拜师<br>正文", "<br>正文"},
+		{"第3章 拜师", "<h1 class=\"hide\">第3章 拜师</h1>\n<br>第3章 拜师<br>正文", "<br>正文"},
+		{"第3章 拜师", "<h1>第3章 别的</h1>正文", "<h1>第3章 别的</h1>正文"},
+		{"", "正文", "正文"},
+	}
+	for _, c := range cases {
+		if got := stage.Apply(c.in, &Context{Title: c.title}); got != c.want {
+			t.Errorf("title %q: got %q, want %q", c.title, got, c.want)
+		}
+	}
+}
+
+func TestWatermarkStage(t *testing.T) {
+	stage := newStage(t, "watermark", model.Rule{}, config.Info{})
+	cases := []struct{ in, want string }{
+		{"正文(www.xbiquge.la 新笔趣阁)结束", "正文( 新笔趣阁)结束"},
+		{"正文【ｗｗｗ．ｂｉｑｕｇｅ．ｃｏｍ】结束", "正文结束"},
+		{"请访问 https://m.example.net/book/1.html 阅读", "请访问  阅读"},
+		{"www . abc . cc 正文", " 正文"},
+		{"他说：abc.community 很好", "他说：abc.community 很好"},
+		{"普通的句子。没有网址。", "普通的句子。没有网址。"},
+		{"看书到 m.xbiquge.la 最快", "看书到  最快"},
+		// 英文句子中的句点与单词不是域名
+		{"He was tired. So I left.", "He was tired. So I left."},
+		{"I said no. Me too. In fact.", "I said no. Me too. In fact."},
+		{"Call me Mr. Co at home.", "Call me Mr. Co at home."},
+		// 属性中的地址保留, 只去除文字中的
+		{`<a href="http://www.abc.com/x">链接</a>`, `<a href="http://www.abc.com/x">链接</a>`},
+		{`<img src="https://img.abc.cc/1.jpg"/>正文www.abc.cc`, `<img src="https://img.abc.cc/1.jpg"/>正文`},
+	}
+	for _, c := range cases {
+		if got := stage.Apply(c.in, &Context{}); got != c.want {
+			t.Errorf("got %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestBoilerplateStage(t *testing.T) {
+	stage := newStage(t, "boilerplate", model.Rule{}, config.Info{})
+	in := "第一段<br>请收藏本站：https://x.com。他推门而入<br>第二段<p>本章未完，请点击下一页继续阅读</p>" +
+		"<p>一秒记住【m.x.la】，精彩无弹窗免费阅读！</p><p>最新网址：www.x.com</p>"
+	want := "第一段<br>他推门而入<br>第二段<p></p><p></p><p></p>"
+	if got := stage.Apply(in, &Context{}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// 正文里的相同字眼不能误删
+	for _, prose := range []string{
+		"他一秒记住了口诀，转身离去",
+		"她把最新网址：抄在纸上，却忘了是哪一家",
+		"请收藏好这枚玉佩。",
+	} {
+		if got := stage.Apply(prose, &Context{}); got != prose {
+			t.Errorf("got %q, want %q", got, prose)
+		}
+	}
+}
+
+func TestReplaceStage(t *testing.T) {
+	conf := config.Info{}
+	if stage, err := newReplaceStage(model.Rule{}, conf); stage != nil || err != nil {
+		t.Errorf("no replacements should disable the stage: %v, %v", stage, err)
+	}
+	conf.Filter.Replacements = []config.Replacement{
+		{Pattern: `(?i)ps[:：].*`, Replace: ""},
+		{Pattern: `(\p{Han})\*(\p{Han})`, Replace: "$1$2"},
+	}
+	stage := newStage(t, "replace", model.Rule{}, conf)
+	if got := stage.Apply("敏*感词PS：求月票", &Context{}); got != "敏感词" {
+		t.Errorf("got %q", got)
+	}
+
+	conf.Filter.Replacements = []config.Replacement{{Pattern: `[`}}
+	if _, err := newReplaceStage(model.Rule{}, conf); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	rule := model.Rule{ID: "test"}
+	rule.Chapter.FilterTxt = `广告`
+	rule.Chapter.FilterTag = "script"
+
+	p, err := New(rule, config.Info{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 默认使用全部阶段, 无自定义替换时 replace 阶段不生效
+	want := []string{"entity", "ads", "tag", "duplicate-title", "watermark", "boilerplate"}
+	if !reflect.DeepEqual(p.Stages(), want) {
+		t.Errorf("stages = %v, want %v", p.Stages(), want)
+	}
+	in := "&nbsp;&nbsp;第一章<br>正文广告<script>x</script>www.abc.com<br>请收藏本站"
+	if got := p.Apply("第一章", in); got != "<br/>正文<br/>" {
+		t.Errorf("got %q", got)
+	}
+
+	// 配置决定顺序与开关, 书源规则可以关闭阶段
+	conf := config.Info{}
+	conf.Filter.Stages = []string{"watermark", "ads", "entity"}
+	rule.Chapter.DisableFilters = []string{"entity"}
+	p, err = New(rule, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.Stages(), []string{"watermark", "ads"}) {
+		t.Errorf("stages = %v", p.Stages())
+	}
+	if got := p.Apply("", "&nbsp;广告正文"); !strings.HasPrefix(got, "&nbsp;") {
+		t.Errorf("entity stage should be disabled: %q", got)
+	}
+
+	conf.Filter.Stages = []string{"unknown"}
+	if _, err := New(rule, conf); err == nil {
+		t.Error("expected unknown stage error")
+	}
+}
+
+func TestGetCachesPipeline(t *testing.T) {
+	rule := model.Rule{ID: "cache"}
+	conf := config.Info{}
+	p1, err := Get(rule, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, _ := Get(rule, conf)
+	if p1 != p2 {
+		t.Error("pipeline should be cached")
+	}
+	conf.Filter.Replacements = []config.Replacement{{Pattern: "a", Replace: "b"}}
+	p3, _ := Get(rule, conf)
+	if p3 == p1 {
+		t.Error("config change should rebuild the pipeline")
+	}
+}
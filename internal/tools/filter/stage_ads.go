@@ -0,0 +1,32 @@
+package filter
+
+import (
+	"regexp"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+func init() {
+	Register("ads", 20, newAdsStage)
+}
+
+// adsStage 按书源规则中的 filterTxt 正则去除广告文字
+type adsStage struct {
+	pattern *regexp.Regexp
+}
+
+func newAdsStage(rule model.Rule, _ config.Info) (Stage, error) {
+	if rule.Chapter.FilterTxt == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(rule.Chapter.FilterTxt)
+	if err != nil {
+		return nil, err
+	}
+	return &adsStage{pattern: re}, nil
+}
+
+func (s *adsStage) Apply(content string, _ *Context) string {
+	return s.pattern.ReplaceAllString(content, "")
+}
@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+// 站点插入的固定套话. 触发词需带有站点特征 (本站、网址等), 避免误删正文里的 "一秒记住" 之类
+var boilerplateTriggers = []string{
+	`请收藏本站`,
+	`天才一秒记住`,
+	`一秒记住(?:本站|本书|[【\[:：]|\s*` + boilerplateURL + `)`,
+	`本章未完[,，]?\s*请点击下一页继续阅读`,
+	`手机用户请浏览`,
+	`请记住本书首发域名`,
+	`最新网址[:：]\s*` + boilerplateURL,
+	`(?:章节)?错误[,，]?\s*点此举报`,
+}
+
+// 网址 (可省略协议)
+const boilerplateURL = `(?:https?://)?[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)+`
+
+// 套话连同其后的内容一起去除, 直到句末标点 (包含) 或行尾
+var boilerplatePattern = regexp.MustCompile(
+	`(?:` + strings.Join(boilerplateTriggers, "|") + `)[^<\n。！？!]*[。！？!]?`,
+)
+
+func init() {
+	Register("boilerplate", 60, func(model.Rule, config.Info) (Stage, error) {
+		return stageFunc(filterBoilerplate), nil
+	})
+}
+
+// filterBoilerplate 去除 "请收藏本站" 等固定套话
+func filterBoilerplate(content string, _ *Context) string {
+	return boilerplatePattern.ReplaceAllString(content, "")
+}
@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+var entityPattern = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+func init() {
+	Register("entity", 10, func(model.Rule, config.Info) (Stage, error) {
+		return stageFunc(filterEntity), nil
+	})
+}
+
+// stageFunc 无需预编译状态的阶段
+type stageFunc func(content string, ctx *Context) string
+
+func (f stageFunc) Apply(content string, ctx *Context) string {
+	return f(content, ctx)
+}
+
+// filterEntity 去除空白实体 (&nbsp; &emsp; 等用于缩进) 与无法识别的实体, 其余实体保留给后续 HTML 解析
+func filterEntity(content string, _ *Context) string {
+	return entityPattern.ReplaceAllStringFunc(content, func(entity string) string {
+		decoded := html.UnescapeString(entity)
+		if decoded == entity {
+			return ""
+		}
+		if strings.TrimFunc(decoded, unicode.IsSpace) == "" {
+			return ""
+		}
+		return entity
+	})
+}
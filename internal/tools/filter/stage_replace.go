@@ -0,0 +1,45 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+func init() {
+	Register("replace", 70, newReplaceStage)
+}
+
+type replacement struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+// replaceStage 执行配置中的自定义正则替换
+type replaceStage struct {
+	replacements []replacement
+}
+
+func newReplaceStage(_ model.Rule, conf config.Info) (Stage, error) {
+	if len(conf.Filter.Replacements) == 0 {
+		return nil, nil
+	}
+	s := &replaceStage{}
+	for _, r := range conf.Filter.Replacements {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", r.Pattern, err)
+		}
+		s.replacements = append(s.replacements, replacement{pattern: re, replace: r.Replace})
+	}
+	return s, nil
+}
+
+func (s *replaceStage) Apply(content string, _ *Context) string {
+	for _, r := range s.replacements {
+		content = r.pattern.ReplaceAllString(content, r.replace)
+	}
+	return content
+}
@@ -0,0 +1,42 @@
+package filter
+
+import (
+	"strings"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+func init() {
+	Register("tag", 30, newTagStage)
+}
+
+// tagStage 按书源规则中的 filterTag 去除标签 (连同其内容), 支持任意 CSS 选择器
+type tagStage struct {
+	selectors []string
+}
+
+func newTagStage(rule model.Rule, _ config.Info) (Stage, error) {
+	selectors := strings.Fields(rule.Chapter.FilterTag)
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	return &tagStage{selectors: selectors}, nil
+}
+
+func (s *tagStage) Apply(content string, _ *Context) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	body := doc.Find("body")
+	for _, selector := range s.selectors {
+		body.Find(selector).Remove()
+	}
+	html, err := body.Html()
+	if err != nil {
+		return content
+	}
+	return html
+}
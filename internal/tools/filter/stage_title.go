@@ -0,0 +1,51 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+func init() {
+	Register("duplicate-title", 40, func(model.Rule, config.Info) (Stage, error) {
+		return stageFunc(filterDuplicateTitle), nil
+	})
+}
+
+var (
+	leadingBreaks  = regexp.MustCompile(`^(?:\s|<br\s*/?>)+`)
+	leadingHeading = regexp.MustCompile(`^<(h[1-6]|p|div)\b[^>]*>([^<]*)</(?:h[1-6]|p|div)>`)
+)
+
+// filterDuplicateTitle 去除正文开头重复的章节标题, 包括标题前的空行及 <h1> 等标签包裹的标题,
+// 标题重复多次时全部去除
+func filterDuplicateTitle(content string, ctx *Context) string {
+	content = strings.TrimSpace(content)
+	title := strings.TrimSpace(ctx.Title)
+	if title == "" {
+		return content
+	}
+	for {
+		rest := leadingBreaks.ReplaceAllString(content, "")
+		if m := leadingHeading.FindStringSubmatch(rest); m != nil && strings.TrimSpace(m[2]) == title {
+			content = strings.TrimSpace(rest[len(m[0]):])
+			continue
+		}
+		if t, ok := titlePrefix(rest, ctx.Title, title); ok {
+			content = strings.TrimSpace(strings.TrimPrefix(rest, t))
+			continue
+		}
+		return content
+	}
+}
+
+func titlePrefix(content string, titles ...string) (string, bool) {
+	for _, t := range titles {
+		if strings.HasPrefix(content, t) {
+			return t, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+// 水印中常见的顶级域名, 同时匹配全角写法, 例如 "ｗｗｗ．ｘｘｘ．ｃｏｍ"
+var watermarkTLDs = []string{
+	"com", "net", "org", "info", "biz", "cc", "la", "me", "co", "cn", "tw", "hk",
+	"io", "in", "so", "tv", "us", "xyz", "top", "vip", "club", "site", "online",
+}
+
+var (
+	watermarkPattern = compileWatermarkPattern()
+	// 去除网址后留下的空括号
+	emptyBracketPattern = regexp.MustCompile(`[(（\[【]\s*[)）\]】]`)
+	// 只处理标签之间的文字, 不改动 href、src 等属性中的地址
+	watermarkTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+func init() {
+	Register("watermark", 50, func(model.Rule, config.Info) (Stage, error) {
+		return stageFunc(filterWatermark), nil
+	})
+}
+
+// compileWatermarkPattern 区分大小写, 避免 "tired. So I left" 这类英文句子被当作域名.
+// 半角点两侧不能有空格, 以 www 或 http:// 开头的网址才允许 "www . abc . cc" 这种写法
+func compileWatermarkPattern() *regexp.Regexp {
+	const alnum = `a-zA-Z0-9ａ-ｚＡ-Ｚ０-９`
+	label := `[` + alnum + `](?:[` + alnum + `-]*[` + alnum + `])?`
+	dot := `(?:\.|[．。])`
+	spacedDot := `[ 　]?[.．。][ 　]?`
+	scheme := `[hｈ][tｔ][tｔ][pｐ][sｓ]?[:：][/／]{2}`
+	www := `[wｗ]{3}`
+	path := `(?:[/／][` + alnum + `/／._?=&%-]*)?`
+
+	tlds := make([]string, 0, len(watermarkTLDs)*2)
+	for _, tld := range watermarkTLDs {
+		tlds = append(tlds, tld, toFullWidth(tld))
+	}
+	// 长的优先, 避免 "co" 抢先匹配 "com"
+	sort.Slice(tlds, func(i, j int) bool {
+		return utf8.RuneCountInString(tlds[i]) > utf8.RuneCountInString(tlds[j])
+	})
+	tld := `(?:` + strings.Join(tlds, "|") + `)`
+	loose := `(?:` + scheme + `(?:` + www + spacedDot + `)?|` + www + spacedDot + `)(?:` + label + spacedDot + `)*` + tld
+	strict := `(?:` + scheme + `)?(?:` + label + dot + `)+` + tld
+	return regexp.MustCompile(`(?:` + loose + `|` + strict + `)` + path)
+}
+
+func toFullWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0x20 && r < 0x7f {
+			return r + 0xfee0
+		}
+		return r
+	}, s)
+}
+
+// filterWatermark 去除正文中的网址与域名水印
+func filterWatermark(content string, _ *Context) string {
+	var sb strings.Builder
+	changed := false
+	last := 0
+	for _, tag := range watermarkTagPattern.FindAllStringIndex(content, -1) {
+		text, removed := removeWatermarks(content[last:tag[0]])
+		changed = changed || removed
+		sb.WriteString(text)
+		sb.WriteString(content[tag[0]:tag[1]])
+		last = tag[1]
+	}
+	text, removed := removeWatermarks(content[last:])
+	if !changed && !removed {
+		return content
+	}
+	sb.WriteString(text)
+	return emptyBracketPattern.ReplaceAllString(sb.String(), "")
+}
+
+// removeWatermarks 去除一段文字中的网址, 返回是否有改动
+func removeWatermarks(text string) (string, bool) {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range watermarkPattern.FindAllStringIndex(text, -1) {
+		// 顶级域名之后仍是字母数字, 说明只是普通单词的一部分
+		if r, _ := utf8.DecodeRuneInString(text[loc[1]:]); isWatermarkAlnum(r) {
+			continue
+		}
+		sb.WriteString(text[last:loc[0]])
+		last = loc[1]
+	}
+	if last == 0 {
+		return text, false
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), true
+}
+
+func isWatermarkAlnum(r rune) bool {
+	if r >= 0xff10 && r <= 0xff5a {
+		r -= 0xfee0
+	}
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
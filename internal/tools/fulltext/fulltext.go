@@ -0,0 +1,459 @@
+package fulltext
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	journalTool "fy-novel/internal/tools/journal"
+	"fy-novel/pkg/utils"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// 全文索引保存在 $HOME/.fynovel/fulltext.db (bbolt), 由抓取日志中的章节文字生成.
+// terms 中每本书一个子 bucket: 索引词 -> 包含该词的章节 Seq (varint 差值编码),
+// 重建一本书的索引时整个子 bucket 替换. 索引只用于筛选候选章节, 结果以原文核对.
+
+const (
+	fileName = "fulltext.db"
+	// 摘要中命中文字前后保留的字数
+	snippetRadius = 30
+	// 默认最多返回的结果数
+	DefaultLimit = 50
+)
+
+var (
+	booksBucket = []byte("books")
+	termsBucket = []byte("terms")
+
+	// 同一进程内写索引串行进行, 其他进程由 bbolt 文件锁等待
+	writeMu sync.Mutex
+)
+
+// indexedBook 已索引的书籍, 日志修改时间变化后需要重建
+type indexedBook struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	BookName   string    `json:"bookName"`
+	Author     string    `json:"author"`
+	Chapters   int       `json:"chapters"`
+	JournalMod time.Time `json:"journalMod"`
+	IndexedAt  time.Time `json:"indexedAt"`
+}
+
+// Path 索引文件路径
+func Path() string {
+	return filepath.Join(config.DataDir(), fileName)
+}
+
+func bookID(bookURL string) string {
+	return fmt.Sprintf("%x", utils.StringToUniqueHash(bookURL))
+}
+
+func open() (*bolt.DB, error) {
+	if err := os.MkdirAll(config.DataDir(), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("fulltext error creating directory: %v", err)
+	}
+	db, err := bolt.Open(Path(), 0644, &bolt.Options{Timeout: 30 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("fulltext error opening index: %v", err)
+	}
+	return db, nil
+}
+
+func update(fn func(tx *bolt.Tx) error) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{booksBucket, termsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+func view(fn func(tx *bolt.Tx) error) error {
+	if _, err := os.Stat(Path()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(booksBucket) == nil {
+			return nil
+		}
+		return fn(tx)
+	})
+}
+
+// IndexBook 重建一本书的索引, 书籍没有抓取日志时返回 os.ErrNotExist
+func IndexBook(bookURL string) error {
+	j, err := journalTool.Read(bookURL)
+	if err != nil {
+		return err
+	}
+	return index(j)
+}
+
+func index(j *journalTool.Journal) error {
+	info, err := os.Stat(j.Path)
+	if err != nil {
+		return err
+	}
+	// 章节按 Seq 升序, 每个词的列表自然有序
+	postings := make(map[string][]int)
+	for _, e := range j.Chapters {
+		eachTerm(e.Title+"\n"+plainText(e.Content), func(term string) {
+			seqs := postings[term]
+			if len(seqs) == 0 || seqs[len(seqs)-1] != e.Seq {
+				postings[term] = append(seqs, e.Seq)
+			}
+		})
+	}
+	book := indexedBook{
+		ID:         bookID(j.Book.URL),
+		URL:        j.Book.URL,
+		BookName:   j.Book.BookName,
+		Author:     j.Book.Author,
+		Chapters:   len(j.Chapters),
+		JournalMod: info.ModTime(),
+		IndexedAt:  time.Now(),
+	}
+	meta, err := json.Marshal(book)
+	if err != nil {
+		return err
+	}
+	err = update(func(tx *bolt.Tx) error {
+		terms := tx.Bucket(termsBucket)
+		if terms.Bucket([]byte(book.ID)) != nil {
+			if err := terms.DeleteBucket([]byte(book.ID)); err != nil {
+				return err
+			}
+		}
+		b, err := terms.CreateBucket([]byte(book.ID))
+		if err != nil {
+			return err
+		}
+		// 按键顺序写入, bbolt 页分裂更少
+		b.FillPercent = 0.9
+		keys := make([]string, 0, len(postings))
+		for term := range postings {
+			keys = append(keys, term)
+		}
+		sort.Strings(keys)
+		for _, term := range keys {
+			if err := b.Put([]byte(term), encodeSeqs(postings[term])); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(booksBucket).Put([]byte(book.ID), meta)
+	})
+	if err != nil {
+		return fmt.Errorf("fulltext error indexing %s: %v", j.Book.BookName, err)
+	}
+	return nil
+}
+
+// Remove 删除一本书的索引, 还没有建立过索引时什么也不做
+func Remove(bookURL string) error {
+	if _, err := os.Stat(Path()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	id := []byte(bookID(bookURL))
+	return update(func(tx *bolt.Tx) error {
+		if tx.Bucket(termsBucket).Bucket(id) != nil {
+			if err := tx.Bucket(termsBucket).DeleteBucket(id); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(booksBucket).Delete(id)
+	})
+}
+
+// Sync 为新增或修改过的抓取日志建立索引, 删除日志已不存在的书籍, 返回重建的书籍数
+func Sync() (int, error) {
+	journals, err := journalTool.List()
+	if err != nil {
+		return 0, err
+	}
+	books, err := indexedBooks()
+	if err != nil {
+		return 0, err
+	}
+	indexed := make(map[string]indexedBook, len(books))
+	for _, b := range books {
+		indexed[b.ID] = b
+	}
+
+	count := 0
+	for _, j := range journals {
+		id := bookID(j.Book.URL)
+		info, err := os.Stat(j.Path)
+		if err != nil {
+			continue
+		}
+		if b, ok := indexed[id]; ok {
+			delete(indexed, id)
+			if b.JournalMod.Equal(info.ModTime()) {
+				continue
+			}
+		}
+		full, err := journalTool.ReadFile(j.Path)
+		if err != nil {
+			return count, err
+		}
+		if err := index(full); err != nil {
+			return count, err
+		}
+		count++
+	}
+	for _, b := range indexed {
+		if err := Remove(b.URL); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+func indexedBooks() ([]indexedBook, error) {
+	var res []indexedBook
+	err := view(func(tx *bolt.Tx) error {
+		return tx.Bucket(booksBucket).ForEach(func(_, data []byte) error {
+			var b indexedBook
+			if err := json.Unmarshal(data, &b); err != nil {
+				return err
+			}
+			res = append(res, b)
+			return nil
+		})
+	})
+	return res, err
+}
+
+// Search 搜索包含全部关键字 (空格分隔) 的章节, 最近下载或更新的书在前, 同一本书按目录顺序.
+// 超过 limit 条时截断并返回 truncated
+func Search(query string, limit int) (hits []model.FullTextHit, truncated bool, err error) {
+	phrases := strings.Fields(strings.ToLower(query))
+	if len(phrases) == 0 {
+		return nil, false, nil
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	var terms []queryTerm
+	for _, p := range phrases {
+		terms = append(terms, queryTerms(p)...)
+	}
+	if len(terms) == 0 {
+		return nil, false, nil
+	}
+
+	type candidate struct {
+		book indexedBook
+		seqs []int
+	}
+	var candidates []candidate
+	err = view(func(tx *bolt.Tx) error {
+		books := tx.Bucket(booksBucket)
+		return tx.Bucket(termsBucket).ForEach(func(id, _ []byte) error {
+			data := books.Get(id)
+			if data == nil {
+				return nil
+			}
+			var book indexedBook
+			if err := json.Unmarshal(data, &book); err != nil {
+				return err
+			}
+			seqs := lookup(tx.Bucket(termsBucket).Bucket(id), terms)
+			if len(seqs) > 0 {
+				candidates = append(candidates, candidate{book: book, seqs: seqs})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].book.JournalMod.After(candidates[j].book.JournalMod)
+	})
+
+	// 核对原文并生成摘要
+	for _, c := range candidates {
+		j, err := journalTool.Read(c.book.URL)
+		if err != nil {
+			// 日志已删除, 下次同步时移除索引
+			continue
+		}
+		for _, e := range j.Chapters {
+			if _, ok := slices.BinarySearch(c.seqs, e.Seq); !ok {
+				continue
+			}
+			snippet, highlights, ok := match(e.Title+"\n"+plainText(e.Content), phrases)
+			if !ok {
+				continue
+			}
+			if len(hits) >= limit {
+				return hits, true, nil
+			}
+			hits = append(hits, model.FullTextHit{
+				BookID:       c.book.ID,
+				BookName:     c.book.BookName,
+				Author:       c.book.Author,
+				BookURL:      c.book.URL,
+				Seq:          e.Seq,
+				ChapterNo:    e.ChapterNo,
+				ChapterTitle: e.Title,
+				Snippet:      snippet,
+				Highlights:   highlights,
+			})
+		}
+	}
+	return hits, false, nil
+}
+
+// lookup 返回同时包含全部查询词的章节 Seq
+func lookup(b *bolt.Bucket, terms []queryTerm) []int {
+	var res []int
+	for i, t := range terms {
+		var seqs []int
+		if t.prefix {
+			// 字母数字词按前缀匹配, 合并所有以其开头的词
+			c := b.Cursor()
+			set := make(map[int]bool)
+			for k, v := c.Seek([]byte(t.text)); k != nil && strings.HasPrefix(string(k), t.text); k, v = c.Next() {
+				for _, seq := range decodeSeqs(v) {
+					set[seq] = true
+				}
+			}
+			for seq := range set {
+				seqs = append(seqs, seq)
+			}
+			slices.Sort(seqs)
+		} else {
+			seqs = decodeSeqs(b.Get([]byte(t.text)))
+		}
+		if i == 0 {
+			res = seqs
+		} else {
+			res = intersect(res, seqs)
+		}
+		if len(res) == 0 {
+			return nil
+		}
+	}
+	return res
+}
+
+func intersect(a, b []int) []int {
+	var res []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// match 检查文字是否包含全部关键字 (不区分大小写), 返回第一个关键字附近的摘要与其中命中文字的位置
+func match(s string, phrases []string) (string, []model.TextRange, bool) {
+	text := []rune(strings.ToLower(s))
+	// 个别字符转小写后字数会变, 此时摘要显示小写文字
+	display := []rune(s)
+	if len(display) != len(text) {
+		display = text
+	}
+	first := -1
+	for i, p := range phrases {
+		pos := runeIndex(text, []rune(p), 0)
+		if pos < 0 {
+			return "", nil, false
+		}
+		if i == 0 {
+			first = pos
+		}
+	}
+	start := max(first-snippetRadius, 0)
+	end := min(first+len([]rune(phrases[0]))+snippetRadius, len(text))
+	window := text[start:end]
+
+	var highlights []model.TextRange
+	for _, p := range phrases {
+		pr := []rune(p)
+		for pos := runeIndex(window, pr, 0); pos >= 0; pos = runeIndex(window, pr, pos+len(pr)) {
+			highlights = append(highlights, model.TextRange{Start: pos, End: pos + len(pr)})
+		}
+	}
+	sort.Slice(highlights, func(i, j int) bool { return highlights[i].Start < highlights[j].Start })
+
+	// 换行换成空格, 字数不变, 位置仍然有效
+	snippet := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, string(display[start:end]))
+	return snippet, highlights, true
+}
+
+func runeIndex(s, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func encodeSeqs(seqs []int) []byte {
+	buf := make([]byte, 0, len(seqs)*2)
+	prev := 0
+	for _, seq := range seqs {
+		buf = binary.AppendUvarint(buf, uint64(seq-prev))
+		prev = seq
+	}
+	return buf
+}
+
+func decodeSeqs(data []byte) []int {
+	var res []int
+	prev := 0
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			break
+		}
+		prev += int(delta)
+		res = append(res, prev)
+		data = data[n:]
+	}
+	return res
+}
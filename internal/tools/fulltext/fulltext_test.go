@@ -0,0 +1,110 @@
+package fulltext
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"fy-novel/internal/model"
+	journalTool "fy-novel/internal/tools/journal"
+)
+
+func writeJournal(t *testing.T, book *model.Book, contents ...string) {
+	t.Helper()
+	w, err := journalTool.Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range contents {
+		w.Append(i, &model.Chapter{ChapterNo: i + 1, Title: book.BookName + string(rune('A'+i)), URL: book.URL + "/" + string(rune('a'+i)), Content: c})
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	var got []string
+	eachTerm("剑来 Hello，天", func(term string) { got = append(got, term) })
+	want := []string{"剑", "剑来", "来", "hello", "天"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("terms = %v, want %v", got, want)
+	}
+	if q := queryTerms("陈平安Go"); !reflect.DeepEqual(q, []queryTerm{{"陈平", false}, {"平安", false}, {"go", true}}) {
+		t.Errorf("query terms = %v", q)
+	}
+	if q := queryTerms("剑"); !reflect.DeepEqual(q, []queryTerm{{"剑", false}}) {
+		t.Errorf("single char query = %v", q)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := plainText("<p>第一段<b>加粗</b></p><p>a &amp; b</p>")
+	if got != "第一段加粗\na & b" {
+		t.Errorf("plainText = %q", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	jian := &model.Book{URL: "https://example.com/1", BookName: "剑来", Author: "烽火"}
+	writeJournal(t, jian,
+		"<p>陈平安站在小镇的泥瓶巷里。</p>",
+		"<p>齐先生说，读书明理。</p><p>陈平安点头。</p>",
+		"<p>平安二字, 陈旧的门。</p>",
+	)
+	time.Sleep(10 * time.Millisecond)
+	xue := &model.Book{URL: "https://example.com/2", BookName: "雪中", Author: "烽火"}
+	writeJournal(t, xue, "<p>Xu Fengnian 说陈平安是谁</p>")
+
+	if n, err := Sync(); err != nil || n != 2 {
+		t.Fatalf("sync = %d, %v", n, err)
+	}
+	if n, _ := Sync(); n != 0 {
+		t.Errorf("unchanged journals indexed again: %d", n)
+	}
+
+	hits, truncated, err := Search("陈平安", 0)
+	if err != nil || truncated {
+		t.Fatal(err, truncated)
+	}
+	// "平安" 与 "陈旧" 分开出现的第三章只是候选, 核对原文后排除
+	if len(hits) != 3 {
+		t.Fatalf("hits = %+v", hits)
+	}
+	first := hits[0]
+	if first.BookName != "雪中" || first.Snippet != "雪中A Xu Fengnian 说陈平安是谁" {
+		t.Errorf("most recently downloaded first with original case: %+v", first)
+	}
+	if !reflect.DeepEqual(first.Highlights, []model.TextRange{{Start: 17, End: 20}}) {
+		t.Errorf("highlights = %v", first.Highlights)
+	}
+
+	// 多个关键字需要同时出现
+	if hits, _, _ := Search("陈平安 读书", 0); len(hits) != 1 || hits[0].Seq != 1 {
+		t.Errorf("and search = %+v", hits)
+	}
+	// 字母按前缀匹配, 不区分大小写
+	if hits, _, _ := Search("fENG", 0); len(hits) != 1 || hits[0].BookID != bookID(xue.URL) {
+		t.Errorf("prefix search = %+v", hits)
+	}
+	if hits, truncated, _ := Search("陈平安", 2); len(hits) != 2 || !truncated {
+		t.Errorf("limit: %d hits, truncated %v", len(hits), truncated)
+	}
+
+	// 日志更新后重新索引, 删除后移除. 重新下载第一章只替换这一章
+	time.Sleep(10 * time.Millisecond)
+	writeJournal(t, jian, "<p>宁姚出剑</p>")
+	os.Remove(journalTool.Path(xue.URL))
+	if n, err := Sync(); err != nil || n != 1 {
+		t.Fatalf("resync = %d, %v", n, err)
+	}
+	if hits, _, _ := Search("陈平安", 0); len(hits) != 1 || hits[0].Seq != 1 {
+		t.Errorf("stale hits = %+v", hits)
+	}
+	if hits, _, _ := Search("宁姚", 0); len(hits) != 1 {
+		t.Errorf("updated book not found: %+v", hits)
+	}
+}
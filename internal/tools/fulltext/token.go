@@ -0,0 +1,104 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// 分词: 连续的中日韩文字取单字与相邻两字 (n-gram), 字母数字按词转为小写.
+// 单字让一个字的查询也能命中, 两字组合减少需要核对原文的候选章节.
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// eachTerm 依次回调文本中的索引词, 同一个词可能出现多次
+func eachTerm(text string, fn func(term string)) {
+	runes := []rune(strings.ToLower(text))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			fn(string(r))
+			if i+1 < len(runes) && isCJK(runes[i+1]) {
+				fn(string(runes[i : i+2]))
+			}
+			i++
+		case isWord(r):
+			j := i
+			for j < len(runes) && isWord(runes[j]) && !isCJK(runes[j]) {
+				j++
+			}
+			fn(string(runes[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+}
+
+// queryTerm 查询词, 字母数字词按前缀匹配
+type queryTerm struct {
+	text   string
+	prefix bool
+}
+
+// queryTerms 查询短语对应的索引词, 中日韩文字只取两字组合 (只有一个字时取单字)
+func queryTerms(phrase string) []queryTerm {
+	var res []queryTerm
+	runes := []rune(strings.ToLower(phrase))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			if j-i == 1 {
+				res = append(res, queryTerm{text: string(r)})
+			}
+			for k := i; k+1 < j; k++ {
+				res = append(res, queryTerm{text: string(runes[k : k+2])})
+			}
+			i = j
+		case isWord(r):
+			j := i
+			for j < len(runes) && isWord(runes[j]) && !isCJK(runes[j]) {
+				j++
+			}
+			res = append(res, queryTerm{text: string(runes[i:j]), prefix: true})
+			i = j
+		default:
+			i++
+		}
+	}
+	return res
+}
+
+// plainText 提取章节 HTML 中的文字, 段落之间以换行分隔
+func plainText(content string) string {
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(sb.String())
+		case html.TextToken:
+			sb.Write(z.Text())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if string(name) == "p" || string(name) == "br" || string(name) == "div" {
+				if sb.Len() > 0 {
+					sb.WriteString("\n")
+				}
+			}
+		}
+	}
+}
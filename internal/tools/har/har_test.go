@@ -0,0 +1,143 @@
+package har
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/book", http.StatusFound)
+		case "/gbk":
+			w.Header().Set("Content-Type", "text/html; charset=gbk")
+			w.Write([]byte{0xd5, 0xfd, 0xce, 0xc4})
+		case "/search":
+			body, _ := io.ReadAll(r.Body)
+			io.WriteString(w, "results for "+string(body))
+		default:
+			w.Header().Set("X-Count", string(rune('0'+n)))
+			io.WriteString(w, "第"+string(rune('0'+n))+"次")
+		}
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder()
+	client := &http.Client{Transport: rec.Wrap(http.DefaultTransport)}
+	do := func(c *http.Client, method, path, body string) (string, http.Header, error) {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		resp, err := c.Do(req)
+		if err != nil {
+			return "", nil, err
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return string(data), resp.Header, nil
+	}
+	var live []string
+	for _, r := range []struct{ method, path, body string }{
+		{"GET", "/book", ""},
+		{"GET", "/book", ""},
+		{"GET", "/old", ""},
+		{"GET", "/gbk", ""},
+		{"POST", "/search", "kw=a"},
+		{"POST", "/search", "kw=b"},
+	} {
+		body, _, err := do(client, r.method, r.path, r.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		live = append(live, body)
+	}
+	// 重定向记录为两次请求
+	if rec.Len() != 7 {
+		t.Fatalf("recorded %d requests, want 7", rec.Len())
+	}
+
+	path := filepath.Join(t.TempDir(), "session.har")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archive.Log.Version != "1.2" || archive.Log.Entries[0].Request.URL != srv.URL+"/book" {
+		t.Errorf("archive log = %+v", archive.Log)
+	}
+	gbk := archive.Log.Entries[4].Response.Content
+	if gbk.Encoding != "base64" || gbk.Size != 4 {
+		t.Errorf("non UTF-8 body should be base64: %+v", gbk)
+	}
+
+	srv.Close()
+	replay := &http.Client{Transport: NewReplayer(archive)}
+	var replayed []string
+	for _, r := range []struct{ method, path, body string }{
+		{"GET", "/book", ""},
+		{"GET", "/book", ""},
+		{"GET", "/old", ""},
+		{"GET", "/gbk", ""},
+		{"POST", "/search", "kw=a"},
+		{"POST", "/search", "kw=b"},
+	} {
+		body, _, err := do(replay, r.method, r.path, r.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replayed = append(replayed, body)
+	}
+	if strings.Join(replayed, "|") != strings.Join(live, "|") {
+		t.Errorf("replayed %q, recorded %q", replayed, live)
+	}
+	// 录制的响应用完后重复最后一次, 即重定向到 /book 的那次
+	body, header, _ := do(replay, "GET", "/book", "")
+	if body != live[2] || header.Get("X-Count") != "4" {
+		t.Errorf("extra replay = %q, %v", body, header)
+	}
+	if _, _, err := do(replay, "GET", "/missing", ""); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("missing request error = %v", err)
+	}
+	if _, _, err := do(replay, "POST", "/search", "kw=c"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded body error = %v", err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	base := http.DefaultTransport
+	if Wrap(base) != base {
+		t.Fatal("no session should keep the transport")
+	}
+	rec := NewRecorder()
+	if err := Record(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replay(NewReplayer(newArchive())); !errors.Is(err, ErrSessionActive) {
+		t.Errorf("second session error = %v", err)
+	}
+	if _, ok := Wrap(base).(*recordTransport); !ok {
+		t.Error("recording should wrap the transport")
+	}
+	if Stop() != rec || Stop() != nil {
+		t.Error("stop should return the recorder once")
+	}
+
+	p := NewReplayer(newArchive())
+	if err := Replay(p); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+	if Wrap(base) != p {
+		t.Error("replay should replace the transport")
+	}
+}
@@ -0,0 +1,128 @@
+package har
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Recorder 记录经过的请求与响应, 多个采集器可共用
+type Recorder struct {
+	mu      sync.Mutex
+	archive *Archive
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{archive: newArchive()}
+}
+
+// Wrap 返回记录请求后交给 base 的 http.RoundTripper
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	return &recordTransport{base: base, recorder: r}
+}
+
+// Len 已记录的请求数
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.archive.Log.Entries)
+}
+
+// Save 将已记录的请求写入 HAR 文件, 条目按请求开始的时间排序
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.archive.Save(path)
+}
+
+func (r *Recorder) add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := r.archive.Log.Entries
+	i := len(entries)
+	for i > 0 && entries[i-1].StartedDateTime.After(e.StartedDateTime) {
+		i--
+	}
+	entries = append(entries, Entry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = e
+	r.archive.Log.Entries = entries
+}
+
+type recordTransport struct {
+	base     http.RoundTripper
+	recorder *Recorder
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		// 网络错误没有可回放的响应, 不记录
+		return resp, err
+	}
+	body, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := float64(time.Since(started).Microseconds()) / 1000
+
+	e := Entry{
+		StartedDateTime: started,
+		Time:            elapsed,
+		Request: Request{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: proto(req.Proto),
+			Cookies:     []NameValue{},
+			Headers:     nameValues(req.Header),
+			QueryString: nameValues(http.Header(req.URL.Query())),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: Response{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: proto(resp.Proto),
+			Cookies:     []NameValue{},
+			Headers:     nameValues(resp.Header),
+			Content:     newContent(body, resp.Header.Get("Content-Type")),
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Timings: Timings{Wait: elapsed},
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		e.Request.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: string(reqBody)}
+	}
+	t.recorder.add(e)
+	return resp, nil
+}
+
+// readBody 读取全部内容并换成可重复读取的副本
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func proto(p string) string {
+	if p == "" {
+		return "HTTP/1.1"
+	}
+	return p
+}
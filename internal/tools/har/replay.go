@@ -0,0 +1,78 @@
+package har
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNotRecorded 回放时请求不在录制的文件中
+var ErrNotRecorded = errors.New("request not recorded")
+
+// Replayer 以录制的响应回答请求, 不访问网络. 请求按方法、地址与请求体匹配,
+// 同一请求录制了多次时按录制的顺序返回, 用完后一直返回最后一次的响应
+type Replayer struct {
+	mu      sync.Mutex
+	entries map[string][]*Entry
+	served  map[string]int
+}
+
+func NewReplayer(a *Archive) *Replayer {
+	p := &Replayer{entries: make(map[string][]*Entry), served: make(map[string]int)}
+	for i := range a.Log.Entries {
+		e := &a.Log.Entries[i]
+		var body string
+		if e.Request.PostData != nil {
+			body = e.Request.PostData.Text
+		}
+		key := replayKey(e.Request.Method, e.Request.URL, body)
+		p.entries[key] = append(p.entries[key], e)
+	}
+	return p
+}
+
+func replayKey(method, url, body string) string {
+	return method + " " + url + "\n" + body
+}
+
+func (p *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := replayKey(req.Method, req.URL.String(), string(reqBody))
+	p.mu.Lock()
+	entries := p.entries[key]
+	n := p.served[key]
+	if n < len(entries)-1 {
+		p.served[key]++
+	}
+	p.mu.Unlock()
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL)
+	}
+
+	e := entries[n]
+	body, err := e.Response.Content.Body()
+	if err != nil {
+		return nil, fmt.Errorf("har error decoding %s: %v", e.Request.URL, err)
+	}
+	h := header(e.Response.Headers)
+	// 录制的是解压后的响应体
+	h.Del("Content-Encoding")
+	h.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Response.Status, http.StatusText(e.Response.Status)),
+		StatusCode:    e.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
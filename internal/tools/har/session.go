@@ -0,0 +1,61 @@
+package har
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrSessionActive 已在录制或回放
+var ErrSessionActive = errors.New("a record or replay session is already active")
+
+// 进程内当前的录制或回放, 之后创建的采集器都经过它
+var (
+	sessionMu sync.Mutex
+	recorder  *Recorder
+	replayer  *Replayer
+)
+
+// Record 开始录制
+func Record(r *Recorder) error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if recorder != nil || replayer != nil {
+		return ErrSessionActive
+	}
+	recorder = r
+	return nil
+}
+
+// Replay 开始回放, 之后的请求都不再访问网络
+func Replay(p *Replayer) error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if recorder != nil || replayer != nil {
+		return ErrSessionActive
+	}
+	replayer = p
+	return nil
+}
+
+// Stop 结束录制或回放, 返回录制中的 Recorder, 没有录制时为 nil
+func Stop() *Recorder {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	r := recorder
+	recorder, replayer = nil, nil
+	return r
+}
+
+// Wrap 按当前会话包装采集器的 transport: 回放时替换为 Replayer, 录制时记录后交给 base
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	switch {
+	case replayer != nil:
+		return replayer
+	case recorder != nil:
+		return recorder.Wrap(base)
+	}
+	return base
+}
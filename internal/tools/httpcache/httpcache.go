@@ -0,0 +1,202 @@
+// Package httpcache 抓取页面的磁盘缓存, 以 http.RoundTripper 接入 colly.
+// 重新下载失败的书时已获取过的页面直接从缓存读取, 不再请求书源
+package httpcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	diskcacheTool "fy-novel/internal/tools/diskcache"
+)
+
+// Page 页面类型, 各类页面的缓存时间不同
+type Page string
+
+const (
+	PageSearch  Page = "search"
+	PageBook    Page = "book"
+	PageCatalog Page = "catalog"
+	PageChapter Page = "chapter"
+)
+
+// Forever 永不过期
+const Forever time.Duration = -1
+
+var (
+	sharedOnce sync.Once
+	shared     *diskcacheTool.Cache
+)
+
+// Dir 缓存目录
+func Dir() string {
+	return filepath.Join(config.DataDir(), "cache", "http")
+}
+
+// Shared 进程内共用的缓存, 大小上限取当前配置
+func Shared() *diskcacheTool.Cache {
+	sharedOnce.Do(func() {
+		shared = diskcacheTool.New(Dir(), config.GetConf().GetHTTPCacheBytes())
+	})
+	return shared
+}
+
+// TTL 页面类型的缓存时间, 0 表示不缓存
+func TTL(conf config.Info, page Page) time.Duration {
+	var minutes int
+	switch page {
+	case PageSearch:
+		minutes = conf.Cache.SearchTTL
+	case PageBook, PageCatalog:
+		minutes = conf.Cache.CatalogTTL
+	case PageChapter:
+		minutes = conf.Cache.ChapterTTL
+	}
+	if minutes < 0 {
+		return Forever
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Transport 先查缓存, 未命中或已过期时交给 Base 请求并缓存 200 响应.
+// 只缓存 GET 与 POST (搜索), 键包含请求方法、地址与请求体
+type Transport struct {
+	Base  http.RoundTripper
+	Cache *diskcacheTool.Cache
+	TTL   time.Duration
+	// 不读取缓存, 获取到的页面仍写入缓存
+	Bypass bool
+}
+
+// NewTransport 按配置与页面类型创建, 该类页面不缓存时直接返回 base
+func NewTransport(conf config.Info, page Page, base http.RoundTripper) http.RoundTripper {
+	ttl := TTL(conf, page)
+	if ttl == 0 {
+		return base
+	}
+	cache := Shared()
+	cache.SetMaxBytes(conf.GetHTTPCacheBytes())
+	return &Transport{Base: base, Cache: cache, TTL: ttl, Bypass: conf.Cache.Bypass}
+}
+
+// meta 缓存条目的首行, 其后为响应体
+type meta struct {
+	URL      string      `json:"url"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	StoredAt time.Time   `json:"storedAt"`
+	// 为空表示永不过期
+	Expires time.Time `json:"expires"`
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		return t.Base.RoundTrip(req)
+	}
+	key, err := cacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Bypass {
+		if resp, ok := t.lookup(key, req); ok {
+			return resp, nil
+		}
+	}
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store(key, req, resp, body)
+	return resp, nil
+}
+
+func (t *Transport) lookup(key string, req *http.Request) (*http.Response, bool) {
+	data, ok := t.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	m, body, err := decode(data)
+	if err != nil || (!m.Expires.IsZero() && time.Now().After(m.Expires)) {
+		return nil, false
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", m.Status, http.StatusText(m.Status)),
+		StatusCode:    m.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        m.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, true
+}
+
+func (t *Transport) store(key string, req *http.Request, resp *http.Response, body []byte) {
+	m := meta{URL: req.URL.String(), Status: resp.StatusCode, Header: resp.Header, StoredAt: time.Now()}
+	if t.TTL != Forever {
+		m.Expires = m.StoredAt.Add(t.TTL)
+	}
+	// 缓存失败不影响抓取
+	if data, err := encode(m, body); err == nil {
+		_ = t.Cache.Put(key, data)
+	}
+}
+
+func encode(m meta, body []byte) ([]byte, error) {
+	head, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(head, '\n'), body...), nil
+}
+
+func decode(data []byte) (meta, []byte, error) {
+	var m meta
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return m, nil, fmt.Errorf("invalid cache entry: no header")
+	}
+	if err := json.Unmarshal(data[:i], &m); err != nil {
+		return m, nil, fmt.Errorf("invalid cache entry: %v", err)
+	}
+	return m, data[i+1:], nil
+}
+
+// cacheKey 请求方法、地址与请求体, 读取后恢复请求体
+func cacheKey(req *http.Request) (string, error) {
+	key := req.Method + " " + req.URL.String()
+	if req.Body == nil || req.Body == http.NoBody {
+		return key, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return key + "\n" + string(body), nil
+}
+
+// Stats 缓存统计
+func Stats() model.CacheStats {
+	return Shared().Stats()
+}
+
+// Clear 删除全部缓存的页面
+func Clear() error {
+	return Shared().Clear()
+}
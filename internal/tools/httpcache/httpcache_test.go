@@ -0,0 +1,116 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fy-novel/internal/config"
+	diskcacheTool "fy-novel/internal/tools/diskcache"
+)
+
+func TestTransport(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/html; charset=gbk")
+		io.WriteString(w, r.Method+" "+r.URL.Path+" "+string(body)+" #"+string(rune('0'+n)))
+	}))
+	defer srv.Close()
+
+	cache := diskcacheTool.New(filepath.Join(t.TempDir(), "http"), 0)
+	tr := &Transport{Base: http.DefaultTransport, Cache: cache, TTL: Forever}
+	client := &http.Client{Transport: tr}
+	get := func(path string) (string, http.Header) {
+		t.Helper()
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), resp.Header
+	}
+	post := func(form string) string {
+		t.Helper()
+		resp, err := client.Post(srv.URL+"/search", "application/x-www-form-urlencoded", strings.NewReader(form))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	first, _ := get("/a")
+	second, header := get("/a")
+	if first != second || requests.Load() != 1 {
+		t.Errorf("second request should be cached: %q, %q, %d requests", first, second, requests.Load())
+	}
+	if header.Get("Content-Type") != "text/html; charset=gbk" {
+		t.Errorf("cached header = %v", header)
+	}
+
+	// 请求体不同的搜索分别缓存
+	if a, b := post("kw=a"), post("kw=b"); a == b || post("kw=a") != a {
+		t.Errorf("post bodies: %q, %q", a, b)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("requests after posts = %d, want 3", requests.Load())
+	}
+
+	// 错误页面不缓存
+	get("/missing")
+	get("/missing")
+	if requests.Load() != 5 {
+		t.Errorf("404 should not be cached, %d requests", requests.Load())
+	}
+
+	// 不读取缓存时重新获取并更新缓存
+	tr.Bypass = true
+	fresh, _ := get("/a")
+	tr.Bypass = false
+	if fresh == first {
+		t.Error("bypass should fetch again")
+	}
+	if cached, _ := get("/a"); cached != fresh {
+		t.Errorf("cache should hold the fresh page, got %q", cached)
+	}
+
+	// 过期的条目重新获取
+	tr.TTL = time.Millisecond
+	get("/b")
+	time.Sleep(5 * time.Millisecond)
+	before := requests.Load()
+	get("/b")
+	if requests.Load() != before+1 {
+		t.Error("expired entry should be fetched again")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var conf config.Info
+	conf.Cache.SearchTTL = 10
+	conf.Cache.CatalogTTL = 0
+	conf.Cache.ChapterTTL = -1
+	if got := TTL(conf, PageSearch); got != 10*time.Minute {
+		t.Errorf("search ttl = %v", got)
+	}
+	if got := TTL(conf, PageChapter); got != Forever {
+		t.Errorf("chapter ttl = %v", got)
+	}
+	// 不缓存时不包装
+	if tr := NewTransport(conf, PageBook, http.DefaultTransport); tr != http.DefaultTransport {
+		t.Errorf("book transport = %T", tr)
+	}
+}
@@ -0,0 +1,328 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"fy-novel/pkg/utils"
+)
+
+// 抓取日志: 每本书一个 JSON Lines 文件, 首行为书籍信息, 其后每行一章.
+// 章节内容为清洗、去重后的段落 HTML (未应用替换词典, 未转换导出格式),
+// 用于替换词典试运行、阅读与重新导出, 无需再次抓取.
+
+// Header 日志首行
+type Header struct {
+	Book      model.Book `json:"book"`
+	SourceID  int        `json:"sourceId"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Entry 日志中的一章, Seq 为章节在目录中的下标
+type Entry struct {
+	Seq       int    `json:"seq"`
+	ChapterNo int    `json:"chapterNo"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Content   string `json:"content"`
+	// 抓取失败, 只记录目录信息以便重新获取
+	Failed bool `json:"failed,omitempty"`
+}
+
+// Journal 读取出的一本书的日志
+type Journal struct {
+	Header
+	Path     string
+	Chapters []Entry
+	// 抓取失败且之后没有成功获取的章节, 按 Seq 排序
+	Failed []Entry
+}
+
+// Dir 日志目录
+func Dir() string {
+	return filepath.Join(config.DataDir(), "journal")
+}
+
+// Path 书籍对应的日志文件
+func Path(bookURL string) string {
+	return filepath.Join(Dir(), fmt.Sprintf("%x.jsonl", utils.StringToUniqueHash(bookURL)))
+}
+
+// ErrInvalidID 书籍 ID 不是书籍地址的哈希
+var ErrInvalidID = errors.New("journal: invalid book id")
+
+// PathByID 按书籍 ID (与书库 ID 相同) 返回日志文件
+func PathByID(id string) (string, error) {
+	if id == "" || strings.Trim(id, "0123456789abcdef") != "" {
+		return "", ErrInvalidID
+	}
+	return filepath.Join(Dir(), id+".jsonl"), nil
+}
+
+// ID 日志对应的书籍 ID
+func (j *Journal) ID() string {
+	return strings.TrimSuffix(filepath.Base(j.Path), ".jsonl")
+}
+
+// Writer 并发安全的日志写入, 章节按完成顺序追加
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// Create 为书籍创建日志. 已有同一书籍、同一书源的日志时继续追加, 部分下载或中断的下载不会丢掉之前的章节;
+// 换了书源时章节不再对应, 重新开始
+func Create(book *model.Book, sourceID int) (*Writer, error) {
+	if err := os.MkdirAll(Dir(), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("journal error creating directory: %v", err)
+	}
+	path := Path(book.URL)
+	if h, err := readHeader(path); err == nil && h.Book.URL == book.URL && h.SourceID == sourceID {
+		return Open(book.URL)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("journal error creating file: %v", err)
+	}
+	w := newWriter(file)
+	if err := w.enc.Encode(Header{Book: *book, SourceID: sourceID, CreatedAt: time.Now()}); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("journal error writing header: %v", err)
+	}
+	return w, nil
+}
+
+// Open 打开已有日志继续追加, 同一 Seq 的后写入者覆盖之前的内容.
+// 上次中断时末尾可能留下不完整的一行, 先截掉, 新内容从新的一行开始
+func Open(bookURL string) (*Writer, error) {
+	file, err := os.OpenFile(Path(bookURL), os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("journal error opening file: %v", err)
+	}
+	if err := trimPartialLine(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("journal error repairing file: %v", err)
+	}
+	return newWriter(file), nil
+}
+
+// trimPartialLine 截断到最后一个换行符之后
+func trimPartialLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 4096)
+	for end := info.Size(); end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		n, err := file.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			if size := start + int64(i) + 1; size < info.Size() {
+				return file.Truncate(size)
+			}
+			return nil
+		}
+		end = start
+	}
+	// 整个文件没有换行 (首行都不完整), 保持原样
+	return nil
+}
+
+func newWriter(file *os.File) *Writer {
+	buf := bufio.NewWriter(file)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	return &Writer{file: file, buf: buf, enc: enc}
+}
+
+// Append 追加一章
+func (w *Writer) Append(seq int, chapter *model.Chapter) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(Entry{
+		Seq:       seq,
+		ChapterNo: chapter.ChapterNo,
+		Title:     chapter.Title,
+		URL:       chapter.URL,
+		Content:   chapter.Content,
+	})
+}
+
+// AppendFailed 记录抓取失败的章节, 不会覆盖之前已获取的内容
+func (w *Writer) AppendFailed(seq int, chapter *model.Chapter) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(Entry{
+		Seq:       seq,
+		ChapterNo: chapter.ChapterNo,
+		Title:     chapter.Title,
+		URL:       chapter.URL,
+		Failed:    true,
+	})
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// Read 读取书籍的日志, 章节按 Seq 排序
+func Read(bookURL string) (*Journal, error) {
+	return ReadFile(Path(bookURL))
+}
+
+// ReadByID 按书籍 ID 读取日志
+func ReadByID(id string) (*Journal, error) {
+	path, err := PathByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return ReadFile(path)
+}
+
+// ReadFile 读取指定的日志文件
+func ReadFile(path string) (*Journal, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	j := &Journal{Path: path}
+	reader := bufio.NewReader(file)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if err := json.Unmarshal(line, &j.Header); err != nil {
+		return nil, fmt.Errorf("journal %s: invalid header: %v", path, err)
+	}
+	chapters := make(map[int]Entry)
+	failed := make(map[int]Entry)
+	for err != io.EOF {
+		if line, err = reader.ReadBytes('\n'); err != nil && err != io.EOF {
+			return nil, err
+		}
+		var e Entry
+		// 中断时留下的不完整行跳过即可, 之后追加的章节照常读取
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &e) != nil {
+			continue
+		}
+		if !e.Failed {
+			chapters[e.Seq] = e
+			delete(failed, e.Seq)
+		} else if _, ok := chapters[e.Seq]; !ok {
+			failed[e.Seq] = e
+		}
+	}
+	j.Chapters = sortedEntries(chapters)
+	j.Failed = sortedEntries(failed)
+	return j, nil
+}
+
+// Compact 重写日志, 每个 Seq 只保留最终的一条, 避免反复追加后无限增长.
+// book 不为空时以其更新首行的书籍信息与时间. 先写临时文件再替换, 中途失败不影响原日志
+func Compact(bookURL string, book *model.Book) error {
+	j, err := Read(bookURL)
+	if err != nil {
+		return err
+	}
+	if book != nil {
+		j.Book = *book
+		j.CreatedAt = time.Now()
+	}
+	tmp, err := os.CreateTemp(Dir(), "*.jsonl.tmp")
+	if err != nil {
+		return fmt.Errorf("journal error creating file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := newWriter(tmp)
+	err = w.enc.Encode(j.Header)
+	entries := append(j.Chapters, j.Failed...)
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Seq < entries[b].Seq
+	})
+	for _, e := range entries {
+		if err != nil {
+			break
+		}
+		err = w.enc.Encode(e)
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("journal error writing file: %v", err)
+	}
+	return os.Rename(tmp.Name(), j.Path)
+}
+
+func sortedEntries(m map[int]Entry) []Entry {
+	res := make([]Entry, 0, len(m))
+	for _, e := range m {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		return res[a].Seq < res[b].Seq
+	})
+	return res
+}
+
+// List 返回全部日志的首行信息, 按创建时间倒序
+func List() ([]*Journal, error) {
+	files, err := filepath.Glob(filepath.Join(Dir(), "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var res []*Journal
+	for _, path := range files {
+		header, err := readHeader(path)
+		if err != nil {
+			continue
+		}
+		res = append(res, &Journal{Header: *header, Path: path})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+func readHeader(path string) (*Header, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && strings.TrimSpace(line) == "" {
+		return nil, errors.New("empty journal")
+	}
+	var h Header
+	if err := json.Unmarshal([]byte(line), &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
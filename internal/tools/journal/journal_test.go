@@ -0,0 +1,239 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"fy-novel/internal/model"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/book/1", BookName: "书"}
+	w, err := Create(book, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按完成顺序写入
+	for _, seq := range []int{2, 0, 1} {
+		if err := w.Append(seq, &model.Chapter{Title: string(rune('A' + seq)), Content: "old"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新抓取的章节覆盖之前的内容
+	w, err = Open(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Append(1, &model.Chapter{Title: "B", Content: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟中断时不完整的最后一行
+	f, _ := os.OpenFile(Path(book.URL), os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"seq":3,"title":"D`)
+	f.Close()
+
+	j, err := Read(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Book.BookName != "书" || j.SourceID != 3 {
+		t.Errorf("header = %+v", j.Header)
+	}
+	if len(j.Chapters) != 3 {
+		t.Fatalf("chapters = %d, want 3", len(j.Chapters))
+	}
+	for i, e := range j.Chapters {
+		if e.Seq != i {
+			t.Errorf("chapter %d has seq %d", i, e.Seq)
+		}
+	}
+	if j.Chapters[1].Content != "new" {
+		t.Errorf("last entry should win, got %q", j.Chapters[1].Content)
+	}
+
+	list, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Book.URL != book.URL || list[0].Chapters != nil {
+		t.Errorf("list = %+v", list)
+	}
+
+	byID, err := ReadByID(list[0].ID())
+	if err != nil || len(byID.Chapters) != 3 {
+		t.Errorf("read by id = %+v, %v", byID, err)
+	}
+	if _, err := ReadByID("../config"); err != ErrInvalidID {
+		t.Errorf("invalid id error = %v", err)
+	}
+}
+
+func TestCreateAppends(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/book/2", BookName: "书"}
+	write := func(sourceID int, seqs ...int) {
+		t.Helper()
+		w, err := Create(book, sourceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, seq := range seqs {
+			if err := w.Append(seq, &model.Chapter{Content: string(rune('0' + sourceID))}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seqs := func() []int {
+		t.Helper()
+		j, err := Read(book.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res []int
+		for _, e := range j.Chapters {
+			res = append(res, e.Seq)
+		}
+		return res
+	}
+
+	write(1, 0, 1, 2, 3)
+	// 同一书源的部分下载保留之前的章节
+	write(1, 2)
+	if got := seqs(); !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("same source seqs = %v", got)
+	}
+	// 换了书源重新开始
+	write(2, 1)
+	if got := seqs(); !slices.Equal(got, []int{1}) {
+		t.Errorf("new source seqs = %v", got)
+	}
+	if j, _ := Read(book.URL); j.SourceID != 2 {
+		t.Errorf("source = %d, want 2", j.SourceID)
+	}
+}
+
+func TestFailedEntries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/book/3", BookName: "书"}
+	w, err := Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Append(0, &model.Chapter{Content: "ok"})
+	w.AppendFailed(1, &model.Chapter{ChapterNo: 2, URL: "b"})
+	w.AppendFailed(2, &model.Chapter{ChapterNo: 3, URL: "c"})
+	// 失败不覆盖已获取的内容, 之后获取成功则不再算作失败
+	w.AppendFailed(0, &model.Chapter{ChapterNo: 1, URL: "a"})
+	w.Append(2, &model.Chapter{Content: "ok"})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := Read(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Chapters) != 2 || j.Chapters[0].Content != "ok" || j.Chapters[1].Seq != 2 {
+		t.Errorf("chapters = %+v", j.Chapters)
+	}
+	if len(j.Failed) != 1 || j.Failed[0].Seq != 1 || j.Failed[0].URL != "b" {
+		t.Errorf("failed = %+v", j.Failed)
+	}
+}
+
+func TestAppendAfterTruncatedLine(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/book/4", BookName: "书"}
+	w, err := Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Append(0, &model.Chapter{Content: "a"})
+	w.Close()
+	// 中断的抓取留下不完整的一行, 之后继续追加
+	f, _ := os.OpenFile(Path(book.URL), os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"seq":1,"title":"B`)
+	f.Close()
+	w, err = Open(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Append(1, &model.Chapter{Content: "b"})
+	w.Append(2, &model.Chapter{Content: "c"})
+	w.Close()
+
+	j, err := Read(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Chapters) != 3 || j.Chapters[1].Content != "b" || j.Chapters[2].Content != "c" {
+		t.Errorf("chapters = %+v", j.Chapters)
+	}
+
+	// 没有经过 Open 修复时, 损坏的行也只跳过这一行
+	f, _ = os.OpenFile(Path(book.URL), os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"seq":3,"tit` + "\n" + `{"seq":4,"content":"e"}` + "\n")
+	f.Close()
+	if j, err = Read(book.URL); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Chapters) != 4 || j.Chapters[3].Seq != 4 {
+		t.Errorf("chapters = %+v", j.Chapters)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/book/5", BookName: "书"}
+	w, err := Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		w.Append(0, &model.Chapter{Content: string(rune('a' + i))})
+	}
+	w.AppendFailed(1, &model.Chapter{Title: "B"})
+	w.Close()
+	before, _ := os.Stat(Path(book.URL))
+
+	updated := *book
+	updated.LatestChapter = "第二章"
+	if err := Compact(book.URL, &updated); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(Path(book.URL))
+	if after.Size() >= before.Size() {
+		t.Errorf("size %d, want less than %d", after.Size(), before.Size())
+	}
+	j, err := Read(book.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Book.LatestChapter != "第二章" || j.SourceID != 1 {
+		t.Errorf("header = %+v", j.Header)
+	}
+	if len(j.Chapters) != 1 || j.Chapters[0].Content != "c" || len(j.Failed) != 1 || j.Failed[0].Seq != 1 {
+		t.Errorf("chapters = %+v, failed = %+v", j.Chapters, j.Failed)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(Dir(), "*.tmp")); len(tmp) != 0 {
+		t.Errorf("temp files left: %v", tmp)
+	}
+}
@@ -0,0 +1,266 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	fulltextTool "fy-novel/internal/tools/fulltext"
+	journalTool "fy-novel/internal/tools/journal"
+	"fy-novel/pkg/utils"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// 本地书库保存在 $HOME/.fynovel/library.db (bbolt).
+// 每次操作单独打开数据库, 不长期占用文件锁, 命令行与界面可以同时使用.
+
+const fileName = "library.db"
+
+var (
+	booksBucket         = []byte("books")
+	catalogsBucket      = []byte("catalogs")
+	subscriptionsBucket = []byte("subscriptions")
+	readingBucket       = []byte("reading")
+
+	ErrNotFound = errors.New("library entry not found")
+)
+
+// Path 书库文件路径
+func Path() string {
+	return filepath.Join(config.DataDir(), fileName)
+}
+
+// ID 书籍在书库中的 ID, 与抓取日志一样由书籍地址计算
+func ID(bookURL string) string {
+	return fmt.Sprintf("%x", utils.StringToUniqueHash(bookURL))
+}
+
+func open() (*bolt.DB, error) {
+	if err := os.MkdirAll(config.DataDir(), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("library error creating directory: %v", err)
+	}
+	db, err := bolt.Open(Path(), 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("library error opening database: %v", err)
+	}
+	return db, nil
+}
+
+func update(fn func(tx *bolt.Tx) error) error {
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{booksBucket, catalogsBucket, subscriptionsBucket, readingBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+func view(fn func(tx *bolt.Tx) error) error {
+	// 还没有下载过任何书时不创建空文件
+	if _, err := os.Stat(Path()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+// Record 记录一次导出, 同一本书只保留一条, 同一格式只保留最近的文件
+func Record(
+	book *model.Book,
+	sourceID int,
+	catalog []*model.Chapter,
+	output model.LibraryOutput,
+	chapterCount int,
+) (model.LibraryEntry, error) {
+	id := ID(book.URL)
+	now := time.Now()
+	if output.CreatedAt.IsZero() {
+		output.CreatedAt = now
+	}
+	var entry model.LibraryEntry
+	err := update(func(tx *bolt.Tx) error {
+		books := tx.Bucket(booksBucket)
+		if data := books.Get([]byte(id)); data != nil {
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+		}
+		entry.ID = id
+		entry.Book = *book
+		entry.SourceID = sourceID
+		entry.ChapterCount = chapterCount
+		entry.DownloadedAt = now
+		outputs := []model.LibraryOutput{output}
+		for _, o := range entry.Outputs {
+			if o.Format != output.Format {
+				outputs = append(outputs, o)
+			}
+		}
+		entry.Outputs = outputs
+
+		// 重新生成 (例如重新获取可疑章节) 时没有目录, 保留之前的快照
+		if catalog != nil {
+			items := make([]model.CatalogItem, len(catalog))
+			for i, c := range catalog {
+				items[i] = model.CatalogItem{ChapterNo: c.ChapterNo, Title: c.Title, URL: c.URL}
+			}
+			entry.CatalogCount = len(items)
+			data, err := json.Marshal(items)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(catalogsBucket).Put([]byte(id), data); err != nil {
+				return err
+			}
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return books.Put([]byte(id), data)
+	})
+	if err != nil {
+		return entry, fmt.Errorf("library error recording %s: %v", book.BookName, err)
+	}
+	return entry, nil
+}
+
+// List 返回全部书籍, 最近下载的在前
+func List() ([]model.LibraryEntry, error) {
+	return Search("")
+}
+
+// Search 按书名、作者或分类搜索, 关键字为空时返回全部
+func Search(keyword string) ([]model.LibraryEntry, error) {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	var res []model.LibraryEntry
+	err := view(func(tx *bolt.Tx) error {
+		books := tx.Bucket(booksBucket)
+		if books == nil {
+			return nil
+		}
+		return books.ForEach(func(_, data []byte) error {
+			var entry model.LibraryEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			if keyword == "" || matches(entry, keyword) {
+				res = append(res, entry)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].DownloadedAt.After(res[j].DownloadedAt)
+	})
+	return res, nil
+}
+
+func matches(entry model.LibraryEntry, keyword string) bool {
+	for _, s := range []string{entry.Book.BookName, entry.Book.Author, entry.Book.Category} {
+		if strings.Contains(strings.ToLower(s), keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get 按 ID 读取
+func Get(id string) (model.LibraryEntry, error) {
+	var entry model.LibraryEntry
+	found := false
+	err := view(func(tx *bolt.Tx) error {
+		books := tx.Bucket(booksBucket)
+		if books == nil {
+			return nil
+		}
+		data := books.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &entry)
+	})
+	if err != nil {
+		return entry, err
+	}
+	if !found {
+		return entry, ErrNotFound
+	}
+	return entry, nil
+}
+
+// Catalog 下载时的目录快照
+func Catalog(id string) ([]model.CatalogItem, error) {
+	var items []model.CatalogItem
+	err := view(func(tx *bolt.Tx) error {
+		catalogs := tx.Bucket(catalogsBucket)
+		if catalogs == nil {
+			return nil
+		}
+		data := catalogs.Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &items)
+	})
+	return items, err
+}
+
+// Delete 删除书库记录、订阅与阅读进度, removeFiles 为 true 时同时删除导出的文件、抓取日志与全文索引
+func Delete(id string, removeFiles bool) error {
+	entry, err := Get(id)
+	if err != nil {
+		return err
+	}
+	if removeFiles {
+		for _, o := range entry.Outputs {
+			// 分章导出的 html/md 记录的是目录页, 删除整个目录
+			path := o.Path
+			if filepath.Base(path) == "index.html" || filepath.Base(path) == "index.md" {
+				path = filepath.Dir(path)
+			}
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("library error removing %s: %v", path, err)
+			}
+		}
+		journal := journalTool.Path(entry.Book.URL)
+		if err := os.Remove(journal); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("library error removing %s: %v", journal, err)
+		}
+		if err := fulltextTool.Remove(entry.Book.URL); err != nil {
+			return fmt.Errorf("library error removing full-text index: %v", err)
+		}
+	}
+	return update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{booksBucket, catalogsBucket, subscriptionsBucket, readingBucket} {
+			if err := tx.Bucket(name).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,179 @@
+package library
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fy-novel/internal/model"
+	fulltextTool "fy-novel/internal/tools/fulltext"
+	journalTool "fy-novel/internal/tools/journal"
+)
+
+func TestLibrary(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if entries, err := List(); err != nil || len(entries) != 0 {
+		t.Fatalf("empty library: %v, %v", entries, err)
+	}
+
+	book := &model.Book{URL: "https://example.com/1", BookName: "剑来", Author: "烽火戏诸侯", Category: "仙侠"}
+	catalog := []*model.Chapter{{ChapterNo: 1, Title: "第一章", URL: "https://example.com/1/1"}}
+	txt := filepath.Join(home, "剑来.txt")
+	os.WriteFile(txt, []byte("x"), 0644)
+	if _, err := Record(book, 1, catalog, model.LibraryOutput{Format: "txt", Path: txt}, 1); err != nil {
+		t.Fatal(err)
+	}
+	// 同一本书的另一种格式, 重新生成时不带目录
+	site := filepath.Join(home, "剑来（烽火戏诸侯）", "index.html")
+	os.MkdirAll(filepath.Dir(site), 0755)
+	os.WriteFile(site, []byte("x"), 0644)
+	entry, err := Record(book, 1, nil, model.LibraryOutput{Format: "html", Path: site}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entry.Outputs) != 2 || entry.Outputs[0].Format != "html" || entry.CatalogCount != 1 {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	time.Sleep(time.Millisecond)
+	other := &model.Book{URL: "https://example.com/2", BookName: "雪中悍刀行", Author: "烽火戏诸侯"}
+	if _, err := Record(other, 2, nil, model.LibraryOutput{Format: "epub", Path: "x.epub"}, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := List()
+	if len(entries) != 2 || entries[0].Book.BookName != "雪中悍刀行" {
+		t.Errorf("list = %+v", entries)
+	}
+	if res, _ := Search("仙侠"); len(res) != 1 || res[0].ID != ID(book.URL) {
+		t.Errorf("search category = %+v", res)
+	}
+	if res, _ := Search("烽火"); len(res) != 2 {
+		t.Errorf("search author = %d entries", len(res))
+	}
+	items, err := Catalog(ID(book.URL))
+	if err != nil || len(items) != 1 || items[0].Title != "第一章" {
+		t.Errorf("catalog = %+v, %v", items, err)
+	}
+
+	// 订阅、抓取日志与全文索引随书一起删除
+	if _, err := SaveSubscription(model.Subscription{Book: *book}); err != nil {
+		t.Fatal(err)
+	}
+	w, err := journalTool.Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Append(0, &model.Chapter{Title: "第一章", Content: "<p>陈平安在泥瓶巷</p>"})
+	w.Close()
+	if err := fulltextTool.IndexBook(book.URL); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _, _ := fulltextTool.Search("泥瓶巷", 10); len(hits) != 1 {
+		t.Fatalf("full-text hits before delete = %+v", hits)
+	}
+
+	if err := Delete(ID(book.URL), true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSubscription(ID(book.URL)); !errors.Is(err, ErrNotSubscribed) {
+		t.Errorf("subscription should be removed: %v", err)
+	}
+	if _, err := os.Stat(journalTool.Path(book.URL)); !os.IsNotExist(err) {
+		t.Error("journal should be removed")
+	}
+	if hits, _, err := fulltextTool.Search("泥瓶巷", 10); err != nil || len(hits) != 0 {
+		t.Errorf("full-text hits = %+v, %v", hits, err)
+	}
+	if _, err := os.Stat(txt); !os.IsNotExist(err) {
+		t.Error("txt output should be removed")
+	}
+	if _, err := os.Stat(filepath.Dir(site)); !os.IsNotExist(err) {
+		t.Error("site directory should be removed")
+	}
+	if _, err := Get(ID(book.URL)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get deleted entry: %v", err)
+	}
+	if err := Delete(ID(book.URL), false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete twice: %v", err)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := GetSubscription("missing"); !errors.Is(err, ErrNotSubscribed) {
+		t.Errorf("get missing subscription: %v", err)
+	}
+	a, err := SaveSubscription(model.Subscription{Book: model.Book{URL: "https://example.com/a", BookName: "甲"}})
+	if err != nil || a.ID != ID("https://example.com/a") {
+		t.Fatalf("save = %+v, %v", a, err)
+	}
+	SaveSubscription(model.Subscription{Book: model.Book{URL: "https://example.com/b", BookName: "乙"}, NewChapters: 2})
+
+	subs, err := Subscriptions()
+	if err != nil || len(subs) != 2 || subs[0].Book.BookName != "乙" {
+		t.Errorf("subscriptions with new chapters first: %+v, %v", subs, err)
+	}
+
+	a.AutoDownload = true
+	SaveSubscription(a)
+	if got, _ := GetSubscription(a.ID); !got.AutoDownload {
+		t.Error("update not saved")
+	}
+	if err := Unsubscribe(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unsubscribe(a.ID); !errors.Is(err, ErrNotSubscribed) {
+		t.Errorf("unsubscribe twice: %v", err)
+	}
+}
+
+func TestReadingState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	book := &model.Book{URL: "https://example.com/r", BookName: "书"}
+	id := ID(book.URL)
+	if state, err := ReadingState(id); err != nil || state.BookID != id || state.Seq != 0 || state.Bookmarks != nil {
+		t.Fatalf("unread state = %+v, %v", state, err)
+	}
+	if _, err := SavePosition(id, -1, 0); err == nil {
+		t.Error("negative chapter should be rejected")
+	}
+	state, err := SavePosition(id, 3, 1.5)
+	if err != nil || state.Seq != 3 || state.Offset != 1 || state.UpdatedAt.IsZero() {
+		t.Errorf("save position = %+v, %v", state, err)
+	}
+
+	late, _ := AddBookmark(id, model.Bookmark{Seq: 5, Title: "第六章"})
+	early, err := AddBookmark(id, model.Bookmark{Seq: 1, Offset: 0.2, Note: "伏笔"})
+	if err != nil || early.ID == "" || early.ID == late.ID {
+		t.Fatalf("add bookmark = %+v, %v", early, err)
+	}
+	state, _ = ReadingState(id)
+	if state.Seq != 3 || len(state.Bookmarks) != 2 || state.Bookmarks[0].ID != early.ID {
+		t.Errorf("bookmarks sorted by chapter: %+v", state)
+	}
+	if err := DeleteBookmark(id, late.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteBookmark(id, late.ID); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("delete bookmark twice: %v", err)
+	}
+	if states, _ := ReadingStates(); len(states[id].Bookmarks) != 1 {
+		t.Errorf("states = %+v", states)
+	}
+
+	// 删除书库记录时一并删除阅读进度
+	Record(book, 1, nil, model.LibraryOutput{Format: "txt", Path: "x.txt"}, 1)
+	if err := Delete(id, false); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := ReadingState(id); state.Seq != 0 || state.Bookmarks != nil {
+		t.Errorf("state after delete = %+v", state)
+	}
+}
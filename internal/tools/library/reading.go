@@ -0,0 +1,134 @@
+package library
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"fy-novel/internal/model"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrBookmarkNotFound 书签不存在
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// ReadingState 返回阅读进度, 没有读过的书返回空进度
+func ReadingState(id string) (model.ReadingState, error) {
+	state := model.ReadingState{BookID: id}
+	err := view(func(tx *bolt.Tx) error {
+		reading := tx.Bucket(readingBucket)
+		if reading == nil {
+			return nil
+		}
+		if data := reading.Get([]byte(id)); data != nil {
+			return json.Unmarshal(data, &state)
+		}
+		return nil
+	})
+	return state, err
+}
+
+// ReadingStates 返回全部阅读进度, 以书籍 ID 为键
+func ReadingStates() (map[string]model.ReadingState, error) {
+	states := make(map[string]model.ReadingState)
+	err := view(func(tx *bolt.Tx) error {
+		reading := tx.Bucket(readingBucket)
+		if reading == nil {
+			return nil
+		}
+		return reading.ForEach(func(k, data []byte) error {
+			var state model.ReadingState
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+			states[string(k)] = state
+			return nil
+		})
+	})
+	return states, err
+}
+
+// SavePosition 保存阅读位置, offset 超出 0-1 时取边界值
+func SavePosition(id string, seq int, offset float64) (model.ReadingState, error) {
+	if seq < 0 {
+		return model.ReadingState{}, fmt.Errorf("invalid chapter %d", seq)
+	}
+	return updateReading(id, func(state *model.ReadingState) error {
+		state.Seq = seq
+		state.Offset = clampOffset(offset)
+		state.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// AddBookmark 添加书签, 返回生成了 ID 的书签. 书签按章节与位置排序
+func AddBookmark(id string, bookmark model.Bookmark) (model.Bookmark, error) {
+	if bookmark.Seq < 0 {
+		return bookmark, fmt.Errorf("invalid chapter %d", bookmark.Seq)
+	}
+	bookmark.ID = newBookmarkID()
+	bookmark.Offset = clampOffset(bookmark.Offset)
+	bookmark.CreatedAt = time.Now()
+	_, err := updateReading(id, func(state *model.ReadingState) error {
+		state.Bookmarks = append(state.Bookmarks, bookmark)
+		sort.SliceStable(state.Bookmarks, func(i, j int) bool {
+			a, b := state.Bookmarks[i], state.Bookmarks[j]
+			if a.Seq != b.Seq {
+				return a.Seq < b.Seq
+			}
+			return a.Offset < b.Offset
+		})
+		return nil
+	})
+	return bookmark, err
+}
+
+// DeleteBookmark 删除书签
+func DeleteBookmark(id, bookmarkID string) error {
+	_, err := updateReading(id, func(state *model.ReadingState) error {
+		for i, b := range state.Bookmarks {
+			if b.ID == bookmarkID {
+				state.Bookmarks = append(state.Bookmarks[:i], state.Bookmarks[i+1:]...)
+				return nil
+			}
+		}
+		return ErrBookmarkNotFound
+	})
+	return err
+}
+
+func updateReading(id string, fn func(state *model.ReadingState) error) (model.ReadingState, error) {
+	state := model.ReadingState{BookID: id}
+	err := update(func(tx *bolt.Tx) error {
+		reading := tx.Bucket(readingBucket)
+		if data := reading.Get([]byte(id)); data != nil {
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+		}
+		if err := fn(&state); err != nil {
+			return err
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return reading.Put([]byte(id), data)
+	})
+	return state, err
+}
+
+func clampOffset(offset float64) float64 {
+	return min(max(offset, 0), 1)
+}
+
+func newBookmarkID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
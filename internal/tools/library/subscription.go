@@ -0,0 +1,97 @@
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"fy-novel/internal/model"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotSubscribed 书籍未订阅
+var ErrNotSubscribed = errors.New("subscription not found")
+
+// SaveSubscription 新增或更新订阅, ID 为空时按书籍地址生成
+func SaveSubscription(sub model.Subscription) (model.Subscription, error) {
+	if sub.ID == "" {
+		sub.ID = ID(sub.Book.URL)
+	}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return sub, err
+	}
+	err = update(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).Put([]byte(sub.ID), data)
+	})
+	if err != nil {
+		return sub, fmt.Errorf("library error saving subscription %s: %v", sub.Book.BookName, err)
+	}
+	return sub, nil
+}
+
+// Subscriptions 返回全部订阅, 有新章节的在前, 其余按书名排序
+func Subscriptions() ([]model.Subscription, error) {
+	var res []model.Subscription
+	err := view(func(tx *bolt.Tx) error {
+		subs := tx.Bucket(subscriptionsBucket)
+		if subs == nil {
+			return nil
+		}
+		return subs.ForEach(func(_, data []byte) error {
+			var sub model.Subscription
+			if err := json.Unmarshal(data, &sub); err != nil {
+				return err
+			}
+			res = append(res, sub)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if (res[i].NewChapters > 0) != (res[j].NewChapters > 0) {
+			return res[i].NewChapters > 0
+		}
+		return res[i].Book.BookName < res[j].Book.BookName
+	})
+	return res, nil
+}
+
+// GetSubscription 按 ID 读取订阅
+func GetSubscription(id string) (model.Subscription, error) {
+	var sub model.Subscription
+	found := false
+	err := view(func(tx *bolt.Tx) error {
+		subs := tx.Bucket(subscriptionsBucket)
+		if subs == nil {
+			return nil
+		}
+		data := subs.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &sub)
+	})
+	if err != nil {
+		return sub, err
+	}
+	if !found {
+		return sub, ErrNotSubscribed
+	}
+	return sub, nil
+}
+
+// Unsubscribe 取消订阅, 不影响书库中的记录
+func Unsubscribe(id string) error {
+	if _, err := GetSubscription(id); err != nil {
+		return err
+	}
+	return update(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).Delete([]byte(id))
+	})
+}
@@ -4,79 +4,114 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"fy-novel/internal/config"
 	"fy-novel/internal/model"
+	epubTool "fy-novel/internal/tools/epub"
 	"fy-novel/pkg/utils"
-	"github.com/go-resty/resty/v2"
-	"github.com/go-shiori/go-epub"
 )
 
-func epubMergeHandler(book *model.Book, dirPath string) (string, error) {
-	var (
-		err       error
-		filePaths []string
-		attempts  = 7
-	)
-	utils.SpinWaitMaxRetryAttempts(func() bool {
-		filePaths, err = utils.GetSortedFilePaths(dirPath)
-		if len(filePaths) == 0 || err != nil {
-			return false
-		}
-		return true
-	}, attempts)
-	if len(filePaths) == 0 {
-		return "", fmt.Errorf("epubMergeHandler error getting sorted file paths: %v", err)
-	}
+type epubWriter struct {
+	path string
+	file *os.File
+	epub *epubTool.Writer
+}
 
-	// 等待文件系统更新索引
-	epubIns, err := epub.NewEpub(book.BookName)
+func newEpubWriter(book *model.Book, outputDir string, conf config.Info) (Writer, error) {
+	style, err := loadEpubStyle(conf)
 	if err != nil {
-		return "", fmt.Errorf("epubMergeHandler error creating epub instance: %v", err)
+		return nil, fmt.Errorf("epubWriter error loading style: %v", err)
 	}
-	epubIns.SetAuthor(book.Author)
-	epubIns.SetDescription(book.Intro)
-	epubIns.SetLang("zh")
 
-	for _, filePath := range filePaths {
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			return "", fmt.Errorf("epubMergeHandler error reading file: %v", err)
-		}
-		// 获取文件名
-		fileName := filepath.Base(filePath)
+	savePath := filepath.Join(outputDir, book.BookName+".epub")
+	file, err := os.Create(savePath)
+	if err != nil {
+		return nil, fmt.Errorf("epubWriter error creating EPUB file: %v", err)
+	}
 
-		// 从文件名中提取标题
-		title := strings.SplitN(fileName, "_", 2)[1]
-		title = strings.TrimSuffix(title, filepath.Ext(title))
-		_, err = epubIns.AddSection(string(content), title, "", "")
-		if err != nil {
-			return "", fmt.Errorf("epubMergeHandler error adding section: %v", err)
-		}
+	epubIns, err := epubTool.NewWriter(file, epubTool.Metadata{
+		Identifier:  fmt.Sprintf("urn:fy-novel:%x", utils.StringToUniqueHash(book.URL+book.BookName)),
+		Title:       book.BookName,
+		Author:      book.Author,
+		Description: book.Intro,
+		Category:    book.Category,
+		Lang:        "zh-CN",
+	}, style)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("epubWriter error creating epub instance: %v", err)
 	}
 
-	// 下载封面
-	if false && len(book.CoverURL) != 0 {
-		client := resty.New()
-		resp, err := client.R().Get(book.CoverURL)
-		if err == nil {
-			coverPath := filepath.Join(dirPath, "cover.jpg")
-			if err := os.WriteFile(coverPath, resp.Body(), 0644); err == nil {
-				epubIns.AddImage(coverPath, "cover.jpg")
-				epubIns.SetCover("cover.jpg", "")
+	// 下载封面, 下载失败或格式不支持时不影响导出
+	if len(book.CoverURL) != 0 {
+		if data, contentType, err := fetchCover(book.CoverURL); err == nil {
+			if name := epubCoverName(contentType); name != "" {
+				if err := epubIns.SetCover(data, name); err != nil {
+					file.Close()
+					return nil, fmt.Errorf("epubWriter error adding cover: %v", err)
+				}
 			}
 		}
 	}
-	// 保存 EPUB 文件
-	savePath := filepath.Join(filepath.Dir(dirPath), book.BookName+".epub")
-	err = epubIns.Write(savePath)
-	if err != nil {
-		return "", fmt.Errorf("epubMergeHandler error writing EPUB file: %v", err)
+	// 书名页 (书名、作者、简介)
+	if err := epubIns.AddTitlePage(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("epubWriter error adding title page: %v", err)
 	}
-	// 处理 EPUB 格式的临时 HTML , 删除文件
-	err = os.RemoveAll(dirPath)
-	if err != nil {
-		return "", fmt.Errorf("epubMergeHandler Error removing temporary HTML files: %v", err)
+	return &epubWriter{path: savePath, file: file, epub: epubIns}, nil
+}
+
+func (w *epubWriter) WriteChapter(chapter *model.Chapter) error {
+	if err := w.epub.AddChapter(chapter.Title, chapter.Content); err != nil {
+		return fmt.Errorf("epubWriter error adding section: %v", err)
+	}
+	return nil
+}
+
+func (w *epubWriter) Close() (string, error) {
+	// 写出目录与 OPF, 完成 EPUB 文件
+	if err := w.epub.Close(); err != nil {
+		w.file.Close()
+		return "", fmt.Errorf("epubWriter error writing EPUB file: %v", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return "", fmt.Errorf("epubWriter error closing EPUB file: %v", err)
+	}
+	return w.path, nil
+}
+
+// epubCoverName 按图片类型命名封面, EPUB 不支持的类型返回空
+func epubCoverName(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return "cover.jpg"
+	case "image/png":
+		return "cover.png"
+	case "image/gif":
+		return "cover.gif"
+	case "image/webp":
+		return "cover.webp"
+	}
+	return ""
+}
+
+// loadEpubStyle 读取配置中的自定义样式表与嵌入字体
+func loadEpubStyle(conf config.Info) (epubTool.Style, error) {
+	style := epubTool.Style{Vertical: conf.Epub.Vertical}
+	if conf.Epub.CssPath != "" {
+		css, err := os.ReadFile(conf.Epub.CssPath)
+		if err != nil {
+			return style, err
+		}
+		style.UserCSS = css
+	}
+	if conf.Epub.FontPath != "" {
+		font, err := os.ReadFile(conf.Epub.FontPath)
+		if err != nil {
+			return style, err
+		}
+		style.Font = font
+		style.FontName = filepath.Base(conf.Epub.FontPath)
 	}
-	return savePath, nil
+	return style, nil
 }
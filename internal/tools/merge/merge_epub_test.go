@@ -1,22 +1,78 @@
 package merge
 
 import (
-	"encoding/json"
+	"archive/zip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"fy-novel/internal/config"
 	"fy-novel/internal/model"
 )
 
-var (
-	bookJsonStr = ``
-	dirPath     = ``
-)
-
-func TestEpubMergeHandler(t *testing.T) {
-	book := model.Book{}
-	json.Unmarshal([]byte(bookJsonStr), &book)
-	_, err := epubMergeHandler(&book, dirPath)
-	if err == nil {
+func TestEpubWriter(t *testing.T) {
+	book := &model.Book{BookName: "书", Author: "作者"}
+	conf := config.Info{}
+	conf.Epub.CssPath = filepath.Join(t.TempDir(), "missing.css")
+	if _, err := newEpubWriter(book, t.TempDir(), conf); err == nil {
 		t.Fatal("expected an error, but got nil")
 	}
+
+	w, err := newEpubWriter(book, t.TempDir(), config.Info{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteChapter(&model.Chapter{Title: "第一章", Content: "<p>一</p>"}); err != nil {
+		t.Fatal(err)
+	}
+	outputPath, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(outputPath); err != nil || info.Size() == 0 {
+		t.Errorf("epub not written: %v", err)
+	}
+}
+
+func TestEpubWriterCover(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n fake cover")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cover" {
+			w.Write(png)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	files := func(coverURL string) map[string]bool {
+		t.Helper()
+		w, err := newEpubWriter(&model.Book{BookName: "书", CoverURL: coverURL}, t.TempDir(), config.Info{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputPath, err := w.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.OpenReader(outputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		res := make(map[string]bool)
+		for _, f := range zr.File {
+			res[f.Name] = true
+		}
+		return res
+	}
+	if got := files(srv.URL + "/cover"); !got["OEBPS/images/cover.png"] || !got["OEBPS/text/cover.xhtml"] {
+		t.Errorf("cover missing: %v", got)
+	}
+	// 封面下载失败时照常导出
+	if got := files(srv.URL + "/missing"); got["OEBPS/images/cover.png"] {
+		t.Errorf("unexpected cover: %v", got)
+	}
 }
@@ -0,0 +1,270 @@
+package merge
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fy-novel/internal/model"
+	"fy-novel/internal/version"
+	"fy-novel/pkg/utils"
+)
+
+const (
+	fb2Namespace    = "http://www.gribuser.ru/xml/fictionbook/2.0"
+	xlinkNamespace  = "http://www.w3.org/1999/xlink"
+	fb2CoverID      = "cover"
+	fb2DefaultGenre = "prose_contemporary"
+)
+
+// 分类到 FictionBook 体裁的映射, 未匹配的使用 fb2DefaultGenre
+var fb2Genres = []struct {
+	keyword string
+	genre   string
+}{
+	{"玄幻", "sf_fantasy"},
+	{"奇幻", "sf_fantasy"},
+	{"仙侠", "sf_fantasy"},
+	{"修真", "sf_fantasy"},
+	{"武侠", "adventure"},
+	{"科幻", "sf"},
+	{"游戏", "sf"},
+	{"灵异", "sf_horror"},
+	{"恐怖", "sf_horror"},
+	{"悬疑", "thriller"},
+	{"推理", "detective"},
+	{"历史", "prose_history"},
+	{"军事", "prose_military"},
+	{"言情", "love_contemporary"},
+	{"都市", "prose_contemporary"},
+}
+
+type fb2Author struct {
+	Nickname string `xml:"nickname"`
+}
+
+type fb2Annotation struct {
+	Paragraphs []string `xml:"p"`
+}
+
+type fb2Image struct {
+	Href string `xml:"l:href,attr"`
+}
+
+type fb2Coverpage struct {
+	Image fb2Image `xml:"image"`
+}
+
+type fb2TitleInfo struct {
+	Genres     []string       `xml:"genre"`
+	Author     fb2Author      `xml:"author"`
+	BookTitle  string         `xml:"book-title"`
+	Annotation *fb2Annotation `xml:"annotation,omitempty"`
+	Coverpage  *fb2Coverpage  `xml:"coverpage,omitempty"`
+	Lang       string         `xml:"lang"`
+}
+
+type fb2Date struct {
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type fb2DocumentInfo struct {
+	Author      fb2Author `xml:"author"`
+	ProgramUsed string    `xml:"program-used"`
+	Date        fb2Date   `xml:"date"`
+	SrcURL      string    `xml:"src-url,omitempty"`
+	ID          string    `xml:"id"`
+	Version     string    `xml:"version"`
+}
+
+type fb2Description struct {
+	XMLName      xml.Name        `xml:"description"`
+	TitleInfo    fb2TitleInfo    `xml:"title-info"`
+	DocumentInfo fb2DocumentInfo `xml:"document-info"`
+}
+
+type fb2Binary struct {
+	XMLName     xml.Name `xml:"binary"`
+	ID          string   `xml:"id,attr"`
+	ContentType string   `xml:"content-type,attr"`
+	Data        string   `xml:",chardata"`
+}
+
+type fb2Writer struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+	enc  *fb2Encoder
+}
+
+func newFB2Writer(book *model.Book, outputDir string) (Writer, error) {
+	var cover *fb2Binary
+	if len(book.CoverURL) != 0 {
+		// 封面下载失败不影响导出
+		if data, contentType, err := fetchCover(book.CoverURL); err == nil {
+			cover = &fb2Binary{
+				ID:          fb2CoverID,
+				ContentType: contentType,
+				Data:        base64.StdEncoding.EncodeToString(data),
+			}
+		}
+	}
+
+	outputPath := filepath.Join(
+		outputDir,
+		fmt.Sprintf("%s（%s）.fb2", book.BookName, book.Author),
+	)
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("fb2Writer error creating output file: %v", err)
+	}
+	buf := bufio.NewWriter(file)
+	enc, err := newFB2Encoder(buf, book, cover)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("fb2Writer error writing FB2: %v", err)
+	}
+	return &fb2Writer{path: outputPath, file: file, buf: buf, enc: enc}, nil
+}
+
+func (w *fb2Writer) WriteChapter(chapter *model.Chapter) error {
+	if err := w.enc.WriteChapter(chapter); err != nil {
+		return fmt.Errorf("fb2Writer error writing %s: %v", chapter.Title, err)
+	}
+	return nil
+}
+
+func (w *fb2Writer) Close() (string, error) {
+	err := w.enc.Close()
+	if err == nil {
+		err = w.buf.Flush()
+	}
+	if err != nil {
+		w.file.Close()
+		return "", fmt.Errorf("fb2Writer error writing FB2: %v", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return "", fmt.Errorf("fb2Writer error closing output file: %v", err)
+	}
+	return w.path, nil
+}
+
+// fb2Encoder 依次输出 FictionBook 2 文档的描述、正文与封面,
+// 章节片段已由 ConvertChapter 转换为 <section>, 同一卷的章节嵌套在卷的 <section> 中
+type fb2Encoder struct {
+	w      io.Writer
+	enc    *xml.Encoder
+	cover  *fb2Binary
+	volume string
+}
+
+func newFB2Encoder(w io.Writer, book *model.Book, cover *fb2Binary) (*fb2Encoder, error) {
+	now := time.Now()
+	desc := fb2Description{
+		TitleInfo: fb2TitleInfo{
+			Genres:    []string{fb2Genre(book.Category)},
+			Author:    fb2Author{Nickname: book.Author},
+			BookTitle: book.BookName,
+			Lang:      "zh",
+		},
+		DocumentInfo: fb2DocumentInfo{
+			Author:      fb2Author{Nickname: "fy-novel"},
+			ProgramUsed: "fy-novel " + version.Version,
+			Date:        fb2Date{Value: now.Format("2006-01-02"), Text: now.Format("2006-01-02")},
+			SrcURL:      book.URL,
+			ID:          fmt.Sprintf("fy-novel-%x", utils.StringToUniqueHash(book.URL+book.BookName)),
+			Version:     "1.0",
+		},
+	}
+	if intro := strings.TrimSpace(book.Intro); intro != "" {
+		desc.TitleInfo.Annotation = &fb2Annotation{}
+		for _, line := range strings.Split(intro, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				desc.TitleInfo.Annotation.Paragraphs = append(desc.TitleInfo.Annotation.Paragraphs, line)
+			}
+		}
+	}
+	if cover != nil {
+		desc.TitleInfo.Coverpage = &fb2Coverpage{Image: fb2Image{Href: "#" + cover.ID}}
+	}
+
+	e := &fb2Encoder{w: w, enc: xml.NewEncoder(w), cover: cover}
+	e.enc.Indent("", "  ")
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, `<FictionBook xmlns="%s" xmlns:l="%s">`+"\n", fb2Namespace, xlinkNamespace); err != nil {
+		return nil, err
+	}
+	if err := e.enc.Encode(desc); err != nil {
+		return nil, err
+	}
+	if err := e.enc.Flush(); err != nil {
+		return nil, err
+	}
+	_, err := io.WriteString(w, "\n<body>\n<title><p>"+fb2Text(book.BookName)+
+		"</p><p>"+fb2Text(book.Author)+"</p></title>\n")
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *fb2Encoder) WriteChapter(chapter *model.Chapter) error {
+	var sb strings.Builder
+	if v := chapterVolume(chapter.Title); v != e.volume {
+		if e.volume != "" {
+			sb.WriteString("</section>\n")
+		}
+		if v != "" {
+			sb.WriteString("<section><title><p>" + fb2Text(v) + "</p></title>\n")
+		}
+		e.volume = v
+	}
+	sb.WriteString(chapter.Content)
+	sb.WriteString("\n")
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
+
+func (e *fb2Encoder) Close() error {
+	tail := "</body>\n"
+	if e.volume != "" {
+		tail = "</section>\n" + tail
+	}
+	if _, err := io.WriteString(e.w, tail); err != nil {
+		return err
+	}
+	if e.cover != nil {
+		if err := e.enc.Encode(e.cover); err != nil {
+			return err
+		}
+		if err := e.enc.Flush(); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(e.w, "\n</FictionBook>\n")
+	return err
+}
+
+func fb2Genre(category string) string {
+	for _, g := range fb2Genres {
+		if strings.Contains(category, g.keyword) {
+			return g.genre
+		}
+	}
+	return fb2DefaultGenre
+}
+
+func fb2Text(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
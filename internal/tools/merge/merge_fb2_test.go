@@ -0,0 +1,270 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"fy-novel/internal/model"
+)
+
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Children []*xmlNode
+	Text     string
+}
+
+func (n *xmlNode) names() []string {
+	var names []string
+	for _, c := range n.Children {
+		names = append(names, c.Name.Local)
+	}
+	return names
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name.Local == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) attr(space, local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parseXMLTree(t *testing.T, data []byte) *xmlNode {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = true
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{Name: tok.Name, Attrs: tok.Attr}
+			if len(stack) == 0 {
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(tok)
+			}
+		}
+	}
+	return root
+}
+
+type occurs struct {
+	name     string
+	min, max int // max < 0 表示不限
+}
+
+// checkSequence 按 FictionBook XSD 中 <xs:sequence> 的顺序与次数校验子元素
+func checkSequence(t *testing.T, where string, names []string, schema []occurs) {
+	t.Helper()
+	i := 0
+	for _, o := range schema {
+		count := 0
+		for i < len(names) && names[i] == o.name {
+			count++
+			i++
+		}
+		if count < o.min || (o.max >= 0 && count > o.max) {
+			t.Errorf("%s: <%s> occurs %d times, want [%d, %d]; children %v", where, o.name, count, o.min, o.max, names)
+		}
+	}
+	if i != len(names) {
+		t.Errorf("%s: unexpected element <%s>; children %v", where, names[i], names)
+	}
+}
+
+func checkSection(t *testing.T, section *xmlNode) {
+	t.Helper()
+	names := section.names()
+	if len(names) == 0 || names[0] != "title" {
+		t.Fatalf("section without title: %v", names)
+	}
+	hasSection, hasParagraph := false, false
+	for _, n := range names[1:] {
+		switch n {
+		case "section":
+			hasSection = true
+		case "p", "empty-line", "subtitle", "poem", "cite", "table":
+			hasParagraph = true
+		default:
+			t.Errorf("unexpected section child <%s>", n)
+		}
+	}
+	if hasSection == hasParagraph {
+		t.Errorf("section must contain either nested sections or paragraphs: %v", names)
+	}
+	for _, c := range section.Children {
+		if c.Name.Local == "section" {
+			checkSection(t, c)
+		}
+	}
+}
+
+func encodeFB2(t *testing.T, book *model.Book, chapters []*model.Chapter, cover *fb2Binary) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc, err := newFB2Encoder(&buf, book, cover)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chapter := range chapters {
+		if err := enc.WriteChapter(chapter); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteFB2(t *testing.T) {
+	book := &model.Book{
+		URL:      "http://example.com/book/1/",
+		BookName: "测试<书>",
+		Author:   "作者",
+		Intro:    "第一行\n第二行",
+		Category: "玄幻魔法",
+	}
+	chapters := []*model.Chapter{
+		{Title: "序章", Content: "<section><title><p>序章</p></title><p>开篇</p></section>"},
+		{Title: "第一卷 风起 第一章 开始", Content: "<section><title><p>第一卷 风起 第一章 开始</p></title><p>一</p></section>"},
+		{Title: "第一卷 风起 第二章 继续", Content: "<section><title><p>第一卷 风起 第二章 继续</p></title><empty-line/></section>"},
+		{Title: "第二卷 云涌 第三章 转折", Content: "<section><title><p>第二卷 云涌 第三章 转折</p></title><p>三</p></section>"},
+	}
+	coverData := []byte("\x89PNG fake cover")
+	cover := &fb2Binary{
+		ID:          fb2CoverID,
+		ContentType: "image/png",
+		Data:        base64.StdEncoding.EncodeToString(coverData),
+	}
+
+	root := parseXMLTree(t, encodeFB2(t, book, chapters, cover))
+
+	if root.Name.Space != fb2Namespace || root.Name.Local != "FictionBook" {
+		t.Fatalf("root = %v", root.Name)
+	}
+	checkSequence(t, "FictionBook", root.names(), []occurs{
+		{"stylesheet", 0, -1},
+		{"description", 1, 1},
+		{"body", 1, -1},
+		{"binary", 0, -1},
+	})
+
+	desc := root.child("description")
+	checkSequence(t, "description", desc.names(), []occurs{
+		{"title-info", 1, 1},
+		{"src-title-info", 0, 1},
+		{"document-info", 1, 1},
+		{"publish-info", 0, 1},
+		{"custom-info", 0, -1},
+	})
+	titleInfo := desc.child("title-info")
+	checkSequence(t, "title-info", titleInfo.names(), []occurs{
+		{"genre", 1, -1},
+		{"author", 1, -1},
+		{"book-title", 1, 1},
+		{"annotation", 0, 1},
+		{"keywords", 0, 1},
+		{"date", 0, 1},
+		{"coverpage", 0, 1},
+		{"lang", 1, 1},
+		{"src-lang", 0, 1},
+		{"translator", 0, -1},
+		{"sequence", 0, -1},
+	})
+	if got := titleInfo.child("genre").Text; got != "sf_fantasy" {
+		t.Errorf("genre = %s", got)
+	}
+	if got := titleInfo.child("book-title").Text; got != book.BookName {
+		t.Errorf("book-title = %s", got)
+	}
+	if got := titleInfo.child("annotation").names(); len(got) != 2 {
+		t.Errorf("annotation paragraphs = %v", got)
+	}
+	for _, author := range []*xmlNode{titleInfo.child("author"), desc.child("document-info").child("author")} {
+		checkSequence(t, "author", author.names(), []occurs{{"nickname", 1, 1}})
+	}
+	checkSequence(t, "document-info", desc.child("document-info").names(), []occurs{
+		{"author", 1, -1},
+		{"program-used", 0, 1},
+		{"date", 1, 1},
+		{"src-url", 0, -1},
+		{"src-ocr", 0, 1},
+		{"id", 1, 1},
+		{"version", 1, 1},
+		{"history", 0, 1},
+		{"publisher", 0, -1},
+	})
+
+	image := titleInfo.child("coverpage").child("image")
+	binary := root.child("binary")
+	if image.attr(xlinkNamespace, "href") != "#"+binary.attr("", "id") {
+		t.Errorf("cover image href %q does not reference binary %q",
+			image.attr(xlinkNamespace, "href"), binary.attr("", "id"))
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(binary.Text); err != nil ||
+		!bytes.Equal(decoded, coverData) {
+		t.Errorf("cover binary does not round-trip: %v", err)
+	}
+
+	body := root.child("body")
+	checkSequence(t, "body", body.names(), []occurs{
+		{"image", 0, 1},
+		{"title", 0, 1},
+		{"epigraph", 0, -1},
+		{"section", 1, -1},
+	})
+	sections := body.Children[1:]
+	if len(sections) != 3 {
+		t.Fatalf("body sections = %d, want prologue + 2 volumes", len(sections))
+	}
+	if got := len(sections[1].Children) - 1; got != 2 {
+		t.Errorf("first volume holds %d chapters, want 2", got)
+	}
+	for _, s := range sections {
+		checkSection(t, s)
+	}
+}
+
+func TestWriteFB2WithoutCover(t *testing.T) {
+	book := &model.Book{BookName: "书", Author: "作者"}
+	chapters := []*model.Chapter{{Title: "第一章", Content: "<section><title><p>第一章</p></title><p>正文</p></section>"}}
+	root := parseXMLTree(t, encodeFB2(t, book, chapters, nil))
+	titleInfo := root.child("description").child("title-info")
+	if titleInfo.child("coverpage") != nil || titleInfo.child("annotation") != nil || root.child("binary") != nil {
+		t.Errorf("unexpected optional elements: %v", titleInfo.names())
+	}
+	if got := titleInfo.child("genre").Text; got != fb2DefaultGenre {
+		t.Errorf("genre = %s", got)
+	}
+}
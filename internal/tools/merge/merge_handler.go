@@ -2,20 +2,74 @@ package merge
 
 import (
 	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"fy-novel/internal/config"
 	"fy-novel/internal/definition"
 	"fy-novel/internal/model"
+	"github.com/go-resty/resty/v2"
 )
 
-func MergeSaveHandler(book *model.Book, dirPath string) (string, error) {
-	conf := config.GetConf()
+// Writer 按章节顺序流式写出整本书, 章节内容已由 ConvertChapter 转换为对应格式
+type Writer interface {
+	// WriteChapter 写入下一章, 调用方保证按章节顺序调用
+	WriteChapter(chapter *model.Chapter) error
+	// Close 完成写出并返回输出路径 (多文件布局时为目录页)
+	Close() (string, error)
+}
+
+// NewWriter 根据配置的导出格式创建 Writer, 输出文件保存在 outputDir 中
+func NewWriter(book *model.Book, outputDir string, conf config.Info) (Writer, error) {
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("error creating output directory: %v", err)
+	}
 	switch conf.Base.Extname {
 	case definition.NovelExtname_TXT:
-		return txtMergeHandler(book, dirPath)
+		return newTxtWriter(book, outputDir)
 	case definition.NovelExtname_EPUB:
-		return epubMergeHandler(book, dirPath)
+		return newEpubWriter(book, outputDir, conf)
+	case definition.NovelExtname_HTML:
+		return newHTMLWriter(book, outputDir, conf.Base.Layout)
+	case definition.NovelExtname_MD:
+		return newMdWriter(book, outputDir, conf.Base.Layout)
+	case definition.NovelExtname_FB2:
+		return newFB2Writer(book, outputDir)
+	case definition.NovelExtname_PDF:
+		return newPdfWriter(book, outputDir, conf)
 	default:
-		return "", fmt.Errorf("unsupported extension: %s", conf.Base.Extname)
+		return nil, fmt.Errorf("unsupported extension: %s", conf.Base.Extname)
+	}
+}
+
+// 章节标题中的卷名, 例如 "第一卷 风起 第一章 开始"
+var volumePattern = regexp.MustCompile(
+	`^(第[0-9零〇一二两三四五六七八九十百千]+[卷部](?:[ \t　]+[^第\s　]\S*)?)[ \t　]+\S`,
+)
+
+// chapterVolume 从章节标题中提取卷名, 没有卷名时返回空串
+func chapterVolume(title string) string {
+	if m := volumePattern.FindStringSubmatch(title); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// fetchCover 下载封面图片, 返回图片内容与识别出的 content-type
+func fetchCover(coverURL string) ([]byte, string, error) {
+	resp, err := resty.New().SetTimeout(15 * time.Second).R().Get(coverURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.IsError() {
+		return nil, "", fmt.Errorf("error fetching cover: %s", resp.Status())
+	}
+	contentType := http.DetectContentType(resp.Body())
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("cover is not an image: %s", contentType)
 	}
+	return resp.Body(), contentType, nil
 }
@@ -0,0 +1,203 @@
+package merge
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+
+	"fy-novel/internal/definition"
+	"fy-novel/internal/model"
+)
+
+type htmlChapter struct {
+	ID      string
+	Href    string
+	Title   string
+	Content template.HTML
+}
+
+var (
+	htmlIndexTemp   = template.Must(template.New("index").Parse(definition.NovelTemp_HTML_Index))
+	htmlPageTemp    = template.Must(template.New("page").Parse(definition.NovelTemp_HTML_Page))
+	htmlHeadTemp    = template.Must(template.New("head").Parse(definition.NovelTemp_HTML_SingleHead))
+	htmlSectionTemp = template.Must(template.New("section").Parse(definition.NovelTemp_HTML_SingleSection))
+)
+
+func newHTMLWriter(book *model.Book, outputDir, layout string) (Writer, error) {
+	if layout == definition.NovelLayout_MULTI {
+		return newHTMLSiteWriter(book, outputDir)
+	}
+	return newHTMLSingleWriter(book, outputDir)
+}
+
+// htmlSingleWriter 生成单个自包含的网页, 样式内联, 目录使用页内锚点.
+// 目录位于正文之前, 章节先写入一个暂存文件, Close 时再拼接到目录之后.
+type htmlSingleWriter struct {
+	book     *model.Book
+	path     string
+	spool    *os.File
+	buf      *bufio.Writer
+	chapters []htmlChapter
+}
+
+func newHTMLSingleWriter(book *model.Book, outputDir string) (Writer, error) {
+	spool, err := os.CreateTemp("", "fy-novel-*.html")
+	if err != nil {
+		return nil, fmt.Errorf("htmlWriter error creating spool file: %v", err)
+	}
+	return &htmlSingleWriter{
+		book: book,
+		path: filepath.Join(
+			outputDir,
+			fmt.Sprintf("%s（%s）.html", book.BookName, book.Author),
+		),
+		spool: spool,
+		buf:   bufio.NewWriter(spool),
+	}, nil
+}
+
+func (w *htmlSingleWriter) WriteChapter(chapter *model.Chapter) error {
+	c := htmlChapter{
+		ID:      fmt.Sprintf("chapter-%d", len(w.chapters)+1),
+		Title:   chapter.Title,
+		Content: template.HTML(chapter.Content),
+	}
+	if err := htmlSectionTemp.Execute(w.buf, c); err != nil {
+		return fmt.Errorf("htmlWriter error rendering %s: %v", chapter.Title, err)
+	}
+	// 目录只需要标题
+	c.Content = ""
+	w.chapters = append(w.chapters, c)
+	return nil
+}
+
+func (w *htmlSingleWriter) Close() (string, error) {
+	defer os.Remove(w.spool.Name())
+	defer w.spool.Close()
+	if err := w.buf.Flush(); err != nil {
+		return "", fmt.Errorf("htmlWriter error flushing spool file: %v", err)
+	}
+	if _, err := w.spool.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("htmlWriter error rewinding spool file: %v", err)
+	}
+
+	file, err := os.Create(w.path)
+	if err != nil {
+		return "", fmt.Errorf("htmlWriter error creating output file: %v", err)
+	}
+	defer file.Close()
+	out := bufio.NewWriter(file)
+	err = htmlHeadTemp.Execute(out, struct {
+		Book     *model.Book
+		Style    template.CSS
+		Chapters []htmlChapter
+	}{w.book, template.CSS(definition.NovelStyle_HTML), w.chapters})
+	if err != nil {
+		return "", fmt.Errorf("htmlWriter error rendering single page: %v", err)
+	}
+	if _, err := io.Copy(out, w.spool); err != nil {
+		return "", fmt.Errorf("htmlWriter error copying chapters: %v", err)
+	}
+	if _, err := out.WriteString(definition.NovelTemp_HTML_SingleFoot); err != nil {
+		return "", fmt.Errorf("htmlWriter error writing output file: %v", err)
+	}
+	if err := out.Flush(); err != nil {
+		return "", fmt.Errorf("htmlWriter error writing output file: %v", err)
+	}
+	return w.path, nil
+}
+
+// htmlSiteWriter 生成静态网站目录: index.html 目录页、每章一页及共享样式表.
+// 章节页的 "下一章" 需要知道是否还有后续章节, 因此始终滞后一章写出.
+type htmlSiteWriter struct {
+	book     *model.Book
+	siteDir  string
+	chapters []htmlChapter
+	pending  *htmlChapter
+}
+
+func newHTMLSiteWriter(book *model.Book, outputDir string) (Writer, error) {
+	siteDir := filepath.Join(
+		outputDir,
+		fmt.Sprintf("%s（%s）", book.BookName, book.Author),
+	)
+	// 重新下载时清理旧的站点, 避免残留多余的章节页
+	if err := os.RemoveAll(siteDir); err != nil {
+		return nil, fmt.Errorf("htmlWriter error cleaning site directory: %v", err)
+	}
+	if err := os.MkdirAll(siteDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("htmlWriter error creating site directory: %v", err)
+	}
+	err := os.WriteFile(filepath.Join(siteDir, "style.css"), []byte(definition.NovelStyle_HTML), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("htmlWriter error writing style: %v", err)
+	}
+	return &htmlSiteWriter{book: book, siteDir: siteDir}, nil
+}
+
+func (w *htmlSiteWriter) WriteChapter(chapter *model.Chapter) error {
+	n := len(w.chapters) + 1
+	c := htmlChapter{
+		ID:      fmt.Sprintf("chapter-%d", n),
+		Href:    fmt.Sprintf("%d.html", n),
+		Title:   chapter.Title,
+		Content: template.HTML(chapter.Content),
+	}
+	if err := w.flush(c.Href); err != nil {
+		return err
+	}
+	w.chapters = append(w.chapters, htmlChapter{ID: c.ID, Href: c.Href, Title: c.Title})
+	w.pending = &c
+	return nil
+}
+
+// flush 写出滞后的一章, next 为空表示最后一章
+func (w *htmlSiteWriter) flush(next string) error {
+	if w.pending == nil {
+		return nil
+	}
+	chapter := w.pending
+	w.pending = nil
+	var prev string
+	if i := len(w.chapters) - 2; i >= 0 {
+		prev = w.chapters[i].Href
+	}
+	err := renderHTMLFile(filepath.Join(w.siteDir, chapter.Href), htmlPageTemp, struct {
+		BookName string
+		Title    string
+		Content  template.HTML
+		Prev     string
+		Next     string
+	}{w.book.BookName, chapter.Title, chapter.Content, prev, next})
+	if err != nil {
+		return fmt.Errorf("htmlWriter error rendering %s: %v", chapter.Href, err)
+	}
+	return nil
+}
+
+func (w *htmlSiteWriter) Close() (string, error) {
+	if err := w.flush(""); err != nil {
+		return "", err
+	}
+	indexPath := filepath.Join(w.siteDir, "index.html")
+	err := renderHTMLFile(indexPath, htmlIndexTemp, struct {
+		Book     *model.Book
+		Chapters []htmlChapter
+	}{w.book, w.chapters})
+	if err != nil {
+		return "", fmt.Errorf("htmlWriter error rendering index: %v", err)
+	}
+	return indexPath, nil
+}
+
+func renderHTMLFile(path string, tmpl *template.Template, data any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return tmpl.Execute(file, data)
+}
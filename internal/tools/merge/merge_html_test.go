@@ -0,0 +1,86 @@
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/definition"
+	"fy-novel/internal/model"
+)
+
+func writeHTMLBook(t *testing.T, layout string) string {
+	t.Helper()
+	book := &model.Book{BookName: "书", Author: "作者", Intro: "简介"}
+	w, err := newHTMLWriter(book, t.TempDir(), layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chapters := []*model.Chapter{
+		{Title: "第一章", Content: "<h1>第一章</h1><div class=\"content\"><p>一</p></div>"},
+		{Title: "第二章", Content: "<h1>第二章</h1><div class=\"content\"><p>二</p></div>"},
+		{Title: "第十章", Content: "<h1>第十章</h1><div class=\"content\"><p>十</p></div>"},
+	}
+	for _, chapter := range chapters {
+		if err := w.WriteChapter(chapter); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outputPath, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return outputPath
+}
+
+func TestHTMLSiteWriter(t *testing.T) {
+	indexPath := writeHTMLBook(t, definition.NovelLayout_MULTI)
+	siteDir := filepath.Dir(indexPath)
+	index, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<a href="3.html">第十章</a>`) {
+		t.Errorf("index missing ordered toc entry: %s", index)
+	}
+
+	first, _ := os.ReadFile(filepath.Join(siteDir, "1.html"))
+	if !strings.Contains(string(first), `href="2.html"`) ||
+		!strings.Contains(string(first), `id="btn-pre" class="disabled"`) {
+		t.Errorf("unexpected navigation on first page: %s", first)
+	}
+	last, _ := os.ReadFile(filepath.Join(siteDir, "3.html"))
+	if !strings.Contains(string(last), `href="2.html"`) ||
+		!strings.Contains(string(last), `id="btn-next" class="disabled"`) {
+		t.Errorf("unexpected navigation on last page: %s", last)
+	}
+	for _, page := range []string{string(index), string(first)} {
+		if strings.Contains(page, "cdn.") || !strings.Contains(page, `href="style.css"`) {
+			t.Errorf("page should only reference local assets: %s", page)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(siteDir, "style.css")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHTMLSingleWriter(t *testing.T) {
+	outputPath := writeHTMLBook(t, definition.NovelLayout_SINGLE)
+	page, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<a href="#chapter-2">第二章</a>`,
+		`<section class="chapter" id="chapter-3">`,
+		"text-indent: 2em",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("single page missing %s", want)
+		}
+	}
+	if strings.Contains(string(page), "<link") {
+		t.Error("single page should be self-contained")
+	}
+}
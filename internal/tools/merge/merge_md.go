@@ -0,0 +1,133 @@
+package merge
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fy-novel/internal/definition"
+	"fy-novel/internal/model"
+)
+
+func newMdWriter(book *model.Book, outputDir, layout string) (Writer, error) {
+	if layout == definition.NovelLayout_MULTI {
+		return newMdDirWriter(book, outputDir)
+	}
+	return newMdSingleWriter(book, outputDir)
+}
+
+// mdSingleWriter 所有章节合并为一个 Markdown 文件, 书籍信息写入 front matter
+type mdSingleWriter struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+}
+
+func newMdSingleWriter(book *model.Book, outputDir string) (Writer, error) {
+	outputPath := filepath.Join(
+		outputDir,
+		fmt.Sprintf("%s（%s）.md", book.BookName, book.Author),
+	)
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("mdWriter error creating output file: %v", err)
+	}
+	w := &mdSingleWriter{path: outputPath, file: file, buf: bufio.NewWriter(file)}
+	w.buf.WriteString(bookFrontMatter(book))
+	w.buf.WriteString("# " + book.BookName + "\n\n")
+	return w, nil
+}
+
+func (w *mdSingleWriter) WriteChapter(chapter *model.Chapter) error {
+	if _, err := w.buf.WriteString(chapter.Content); err != nil {
+		return fmt.Errorf("mdWriter error writing %s: %v", chapter.Title, err)
+	}
+	return nil
+}
+
+func (w *mdSingleWriter) Close() (string, error) {
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return "", fmt.Errorf("mdWriter error writing output file: %v", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return "", fmt.Errorf("mdWriter error closing output file: %v", err)
+	}
+	return w.path, nil
+}
+
+// mdDirWriter 每章一个 Markdown 文件, index.md 保存书籍信息与目录
+type mdDirWriter struct {
+	book      *model.Book
+	outputDir string
+	index     strings.Builder
+	count     int
+}
+
+func newMdDirWriter(book *model.Book, outputDir string) (Writer, error) {
+	dir := filepath.Join(
+		outputDir,
+		fmt.Sprintf("%s（%s）", book.BookName, book.Author),
+	)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("mdWriter error cleaning output directory: %v", err)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("mdWriter error creating output directory: %v", err)
+	}
+	w := &mdDirWriter{book: book, outputDir: dir}
+	w.index.WriteString(bookFrontMatter(book))
+	w.index.WriteString("# " + book.BookName + "\n\n")
+	return w, nil
+}
+
+func (w *mdDirWriter) WriteChapter(chapter *model.Chapter) error {
+	w.count++
+	name := fmt.Sprintf("%04d.md", w.count)
+	title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(chapter.Title)
+	w.index.WriteString(fmt.Sprintf("%d. [%s](%s)\n", w.count, title, name))
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("title: " + yamlQuote(chapter.Title) + "\n")
+	sb.WriteString("book: " + yamlQuote(w.book.BookName) + "\n")
+	sb.WriteString("author: " + yamlQuote(w.book.Author) + "\n")
+	sb.WriteString(fmt.Sprintf("weight: %d\n", w.count))
+	sb.WriteString("---\n\n")
+	// 单章文件中章节标题作为一级标题
+	sb.WriteString(strings.TrimPrefix(chapter.Content, "#"))
+	if err := os.WriteFile(filepath.Join(w.outputDir, name), []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("mdWriter error writing %s: %v", name, err)
+	}
+	return nil
+}
+
+func (w *mdDirWriter) Close() (string, error) {
+	indexPath := filepath.Join(w.outputDir, "index.md")
+	if err := os.WriteFile(indexPath, []byte(w.index.String()), 0644); err != nil {
+		return "", fmt.Errorf("mdWriter error writing index: %v", err)
+	}
+	return indexPath, nil
+}
+
+func bookFrontMatter(book *model.Book) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("title: " + yamlQuote(book.BookName) + "\n")
+	sb.WriteString("author: " + yamlQuote(book.Author) + "\n")
+	if book.Category != "" {
+		sb.WriteString("category: " + yamlQuote(book.Category) + "\n")
+	}
+	sb.WriteString("intro: " + yamlQuote(book.Intro) + "\n")
+	sb.WriteString("source: " + yamlQuote(book.URL) + "\n")
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// yamlQuote 生成 YAML 双引号字符串
+func yamlQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "", "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
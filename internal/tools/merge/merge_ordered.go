@@ -0,0 +1,115 @@
+package merge
+
+import (
+	"sync"
+
+	"fy-novel/internal/model"
+)
+
+// OrderedWriter 接收并发下载中乱序完成的章节, 按序号连续地交给 Writer.
+// 序号从 0 开始, 每个序号都必须调用一次 Put (获取失败时传入 nil 跳过该章).
+type OrderedWriter struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	w       Writer
+	window  int
+	next    int
+	pending map[int]*model.Chapter
+	hook    Hook
+	err     error
+}
+
+// Hook 章节按序写出前调用 (获取失败的章节传入 nil), 返回的章节交给 Writer, 返回 nil 跳过该章.
+// 调用时持有锁, 因此可以安全地维护跨章节的状态.
+type Hook func(seq int, chapter *model.Chapter) (*model.Chapter, error)
+
+// NewOrderedWriter window 限制最多缓存多少个尚未轮到写出的章节
+func NewOrderedWriter(w Writer, window int) *OrderedWriter {
+	if window < 1 {
+		window = 1
+	}
+	o := &OrderedWriter{
+		w:       w,
+		window:  window,
+		pending: make(map[int]*model.Chapter),
+	}
+	o.cond = sync.NewCond(&o.mu)
+	return o
+}
+
+// SetHook 设置写出前的处理, 需在第一次 Put 之前调用
+func (o *OrderedWriter) SetHook(hook Hook) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.hook = hook
+}
+
+// Reserve 阻塞直到 seq 进入缓冲窗口, 避免前面的慢章节导致缓存无限增长
+func (o *OrderedWriter) Reserve(seq int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for seq >= o.next+o.window {
+		o.cond.Wait()
+	}
+}
+
+// Put 提交第 seq 章, 并写出从当前位置开始所有已就绪的章节
+func (o *OrderedWriter) Put(seq int, chapter *model.Chapter) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if seq < o.next {
+		return o.err
+	}
+	o.pending[seq] = chapter
+	o.flush()
+	return o.err
+}
+
+func (o *OrderedWriter) flush() {
+	advanced := false
+	for {
+		chapter, ok := o.pending[o.next]
+		if !ok {
+			break
+		}
+		seq := o.next
+		delete(o.pending, seq)
+		o.next++
+		advanced = true
+		// 写出出错后继续推进序号, 保证 Reserve 不会永久阻塞
+		if o.err != nil {
+			continue
+		}
+		if o.hook != nil {
+			chapter, o.err = o.hook(seq, chapter)
+		}
+		if chapter != nil && o.err == nil {
+			o.err = o.w.WriteChapter(chapter)
+		}
+	}
+	if advanced {
+		o.cond.Broadcast()
+	}
+}
+
+// Close 按顺序写出剩余章节 (跳过未提交的序号) 并关闭 Writer
+func (o *OrderedWriter) Close() (string, error) {
+	o.mu.Lock()
+	for len(o.pending) > 0 {
+		for {
+			if _, ok := o.pending[o.next]; ok {
+				break
+			}
+			o.next++
+		}
+		o.flush()
+	}
+	err := o.err
+	o.mu.Unlock()
+
+	outputPath, closeErr := o.w.Close()
+	if err != nil {
+		return "", err
+	}
+	return outputPath, closeErr
+}
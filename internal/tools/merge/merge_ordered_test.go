@@ -0,0 +1,107 @@
+package merge
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"fy-novel/internal/model"
+)
+
+type recordWriter struct {
+	titles []string
+}
+
+func (w *recordWriter) WriteChapter(chapter *model.Chapter) error {
+	w.titles = append(w.titles, chapter.Title)
+	return nil
+}
+
+func (w *recordWriter) Close() (string, error) {
+	return "done", nil
+}
+
+func TestOrderedWriter(t *testing.T) {
+	const total = 50
+	rec := &recordWriter{}
+	o := NewOrderedWriter(rec, 4)
+
+	var wg sync.WaitGroup
+	var want []string
+	for i := 0; i < total; i++ {
+		title := string(rune('A' + i))
+		// 每 7 章模拟一次获取失败
+		failed := i%7 == 3
+		if !failed {
+			want = append(want, title)
+		}
+		o.Reserve(i)
+		wg.Add(1)
+		go func(seq int) {
+			defer wg.Done()
+			if failed {
+				o.Put(seq, nil)
+				return
+			}
+			// 打乱完成顺序
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+			o.Put(seq, &model.Chapter{Title: title})
+		}(i)
+	}
+	wg.Wait()
+
+	outputPath, err := o.Close()
+	if err != nil || outputPath != "done" {
+		t.Fatalf("Close() = %s, %v", outputPath, err)
+	}
+	if !reflect.DeepEqual(rec.titles, want) {
+		t.Errorf("chapters written out of order:\n got %v\nwant %v", rec.titles, want)
+	}
+}
+
+func TestOrderedWriterOutOfOrder(t *testing.T) {
+	rec := &recordWriter{}
+	o := NewOrderedWriter(rec, 10)
+	for _, seq := range []int{2, 0, 3, 1} {
+		o.Put(seq, &model.Chapter{Title: string(rune('a' + seq))})
+		if seq == 0 && !reflect.DeepEqual(rec.titles, []string{"a"}) {
+			t.Errorf("after 0: %v", rec.titles)
+		}
+	}
+	if !reflect.DeepEqual(rec.titles, []string{"a", "b", "c", "d"}) {
+		t.Errorf("titles = %v", rec.titles)
+	}
+	// 未提交的序号在 Close 时跳过
+	o.Put(5, &model.Chapter{Title: "f"})
+	if _, err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rec.titles, []string{"a", "b", "c", "d", "f"}) {
+		t.Errorf("titles = %v", rec.titles)
+	}
+}
+
+func TestOrderedWriterHook(t *testing.T) {
+	rec := &recordWriter{}
+	o := NewOrderedWriter(rec, 4)
+	var seqs []int
+	o.SetHook(func(seq int, chapter *model.Chapter) (*model.Chapter, error) {
+		seqs = append(seqs, seq)
+		if chapter == nil || chapter.Title == "skip" {
+			return nil, nil
+		}
+		chapter.Title += "!"
+		return chapter, nil
+	})
+	o.Put(2, &model.Chapter{Title: "C"})
+	o.Put(1, nil)
+	o.Put(0, &model.Chapter{Title: "skip"})
+	if _, err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seqs, []int{0, 1, 2}) || !reflect.DeepEqual(rec.titles, []string{"C!"}) {
+		t.Errorf("seqs = %v, titles = %v", seqs, rec.titles)
+	}
+}
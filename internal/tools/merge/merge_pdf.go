@@ -0,0 +1,298 @@
+package merge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+	"github.com/go-pdf/fpdf"
+)
+
+const pdfFontFamily = "cjk"
+
+// 备用中文字体 (GNU Unifont 子集, 见 font/README.md), 没有配置也找不到系统字体时使用.
+// 不编译进程序: 发布包放在程序旁的 fonts 目录, 也可放在数据目录的 fonts 目录;
+// 以 -tags embedfont 编译时嵌入程序, 见 merge_pdf_font.go
+const pdfFallbackFontName = "fallback.ttf"
+
+// pdfEmbeddedFont 嵌入程序的备用字体, 默认为空
+var pdfEmbeddedFont []byte
+
+// 常见的系统中文字体 (fpdf 只能解析 ttf, ttc 合集不可用)
+var pdfFontCandidates = map[string][]string{
+	"windows": {
+		"C:/Windows/Fonts/simhei.ttf",
+		"C:/Windows/Fonts/simkai.ttf",
+		"C:/Windows/Fonts/simfang.ttf",
+		"C:/Windows/Fonts/STKAITI.TTF",
+		"C:/Windows/Fonts/Deng.ttf",
+	},
+	"darwin": {
+		"/Library/Fonts/Arial Unicode.ttf",
+		"/System/Library/Fonts/Supplemental/Arial Unicode.ttf",
+	},
+	"linux": {
+		"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf",
+		"/usr/share/fonts/truetype/noto/NotoSansSC-Regular.ttf",
+		"/usr/share/fonts/noto-cjk/NotoSansSC-Regular.ttf",
+		"/usr/share/fonts/truetype/arphic-gkai00mp/gkai00mp.ttf",
+		"/usr/share/fonts/google-droid/DroidSansFallbackFull.ttf",
+	},
+}
+
+// pdfLayout PDF 排版参数, 单位为毫米 (字号为磅)
+type pdfLayout struct {
+	Size     fpdf.SizeType
+	SizeStr  string
+	Margin   float64
+	FontSize float64
+	Font     []byte
+}
+
+// pdfWriter 逐章排版, fpdf 在内存中保存整个文档, Close 时写出文件
+type pdfWriter struct {
+	path       string
+	pdf        *fpdf.Fpdf
+	layout     pdfLayout
+	lineHeight float64
+	volume     string
+}
+
+func newPdfWriter(book *model.Book, outputDir string, conf config.Info) (Writer, error) {
+	layout, err := loadPdfLayout(conf)
+	if err != nil {
+		return nil, fmt.Errorf("pdfWriter %v", err)
+	}
+
+	var cover []byte
+	var coverType string
+	if len(book.CoverURL) != 0 {
+		// 封面下载失败不影响导出
+		if data, contentType, err := fetchCover(book.CoverURL); err == nil {
+			cover, coverType = data, contentType
+		}
+	}
+
+	w, err := newPdfDocument(book, layout, cover, coverType)
+	if err != nil {
+		return nil, err
+	}
+	w.path = filepath.Join(
+		outputDir,
+		fmt.Sprintf("%s（%s）.pdf", book.BookName, book.Author),
+	)
+	return w, nil
+}
+
+// newPdfDocument 创建文档并排版书名页
+func newPdfDocument(
+	book *model.Book,
+	layout pdfLayout,
+	cover []byte,
+	coverType string,
+) (*pdfWriter, error) {
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		SizeStr: layout.SizeStr,
+		Size:    layout.Size,
+	})
+	pdf.SetMargins(layout.Margin, layout.Margin, layout.Margin)
+	pdf.SetAutoPageBreak(true, layout.Margin)
+	if err := addPdfFont(pdf, layout.Font); err != nil {
+		return nil, fmt.Errorf("pdfWriter error loading font: %v", err)
+	}
+	pdf.SetTitle(book.BookName, true)
+	pdf.SetAuthor(book.Author, true)
+	pdf.SetSubject(book.Intro, true)
+	pdf.SetCreator("fy-novel", true)
+	pdf.SetLang("zh-CN")
+
+	// 页脚页码, 书名页不显示
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-layout.Margin * 0.8)
+		pdf.SetFont(pdfFontFamily, "", layout.FontSize*0.7)
+		pdf.CellFormat(0, 5, strconv.Itoa(pdf.PageNo()-1), "", 0, "C", false, 0, "")
+	})
+
+	lineHeight := layout.FontSize * 0.3528 * 1.8 // 磅转毫米, 1.8 倍行距
+	_, pageHeight := pdf.GetPageSize()
+
+	// 书名页
+	pdf.AddPage()
+	pdf.Bookmark(book.BookName, 0, -1)
+	if len(cover) > 0 {
+		tp := pdf.ImageTypeFromMime(coverType)
+		if pdf.Ok() {
+			info := pdf.RegisterImageOptionsReader("cover", fpdf.ImageOptions{ImageType: tp}, bytes.NewReader(cover))
+			if info != nil && pdf.Ok() {
+				pageWidth, _ := pdf.GetPageSize()
+				w := (pageWidth - 2*layout.Margin) * 0.6
+				h := w * info.Height() / info.Width()
+				pdf.ImageOptions("cover", (pageWidth-w)/2, layout.Margin, w, h, false,
+					fpdf.ImageOptions{ImageType: tp}, 0, "")
+				pdf.SetY(layout.Margin + h + lineHeight)
+			}
+		}
+		// 字体错误已在之前返回, 这里只忽略封面格式不支持或解析失败的错误
+		pdf.ClearError()
+	}
+	if pdf.GetY() < pageHeight*0.3 {
+		pdf.SetY(pageHeight * 0.3)
+	}
+	pdf.SetFont(pdfFontFamily, "", layout.FontSize*2)
+	pdf.MultiCell(0, layout.FontSize*2*0.3528*1.6, book.BookName, "", "C", false)
+	pdf.Ln(lineHeight)
+	pdf.SetFont(pdfFontFamily, "", layout.FontSize*1.1)
+	pdf.MultiCell(0, lineHeight, book.Author, "", "C", false)
+	if book.Category != "" {
+		pdf.MultiCell(0, lineHeight, book.Category, "", "C", false)
+	}
+	if intro := strings.TrimSpace(book.Intro); intro != "" {
+		pdf.Ln(lineHeight * 2)
+		pdf.SetFont(pdfFontFamily, "", layout.FontSize)
+		pdf.MultiCell(0, lineHeight, intro, "", "L", false)
+	}
+
+	return &pdfWriter{pdf: pdf, layout: layout, lineHeight: lineHeight}, nil
+}
+
+// addPdfFont 加载字体并立即检查. fpdf 对非 TrueType 文件只打印错误, 到使用字体时才报错,
+// 截断的文件会直接 panic
+func addPdfFont(pdf *fpdf.Fpdf, font []byte) (err error) {
+	if len(font) < 4 || !(bytes.Equal(font[:4], []byte{0, 1, 0, 0}) || string(font[:4]) == "true") {
+		return errors.New("not a TrueType font (ttc/otf are not supported)")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid TrueType font: %v", r)
+		}
+	}()
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", font)
+	pdf.SetFont(pdfFontFamily, "", 12)
+	return pdf.Error()
+}
+
+// WriteChapter 每章另起一页, 每章 (以及每卷) 生成一个书签
+func (w *pdfWriter) WriteChapter(chapter *model.Chapter) error {
+	pdf, layout := w.pdf, w.layout
+	pdf.AddPage()
+	level := 0
+	if v := chapterVolume(chapter.Title); v != "" {
+		if v != w.volume {
+			pdf.Bookmark(v, 0, -1)
+		}
+		level = 1
+		w.volume = v
+	} else {
+		w.volume = ""
+	}
+	pdf.Bookmark(chapter.Title, level, -1)
+
+	pdf.SetFont(pdfFontFamily, "", layout.FontSize*1.4)
+	pdf.MultiCell(0, layout.FontSize*1.4*0.3528*1.6, chapter.Title, "", "C", false)
+	pdf.Ln(w.lineHeight)
+	pdf.SetFont(pdfFontFamily, "", layout.FontSize)
+	pdf.MultiCell(0, w.lineHeight, strings.TrimRight(chapter.Content, "\n"), "", "L", false)
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("pdfWriter error rendering %s: %v", chapter.Title, err)
+	}
+	return nil
+}
+
+func (w *pdfWriter) Close() (string, error) {
+	if err := w.pdf.OutputFileAndClose(w.path); err != nil {
+		return "", fmt.Errorf("pdfWriter error writing PDF: %v", err)
+	}
+	return w.path, nil
+}
+
+// loadPdfLayout 解析页面尺寸并加载字体 (配置优先, 其次系统中文字体, 最后使用备用字体)
+func loadPdfLayout(conf config.Info) (pdfLayout, error) {
+	layout := pdfLayout{
+		SizeStr:  "A5",
+		Margin:   conf.Pdf.Margin,
+		FontSize: conf.Pdf.FontSize,
+	}
+	if layout.Margin <= 0 {
+		layout.Margin = 15
+	}
+	if layout.FontSize <= 0 {
+		layout.FontSize = 12
+	}
+	if size := strings.TrimSpace(conf.Pdf.PageSize); size != "" {
+		if w, h, ok := strings.Cut(strings.ToLower(size), "x"); ok {
+			width, err1 := strconv.ParseFloat(strings.TrimSpace(w), 64)
+			height, err2 := strconv.ParseFloat(strings.TrimSpace(h), 64)
+			if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+				return layout, fmt.Errorf("invalid page size: %s", size)
+			}
+			layout.SizeStr = ""
+			layout.Size = fpdf.SizeType{Wd: width, Ht: height}
+		} else {
+			layout.SizeStr = size
+		}
+	}
+
+	fontPath := conf.Pdf.FontPath
+	if fontPath == "" {
+		fontPath = findSystemFont()
+	}
+	if fontPath == "" {
+		font, err := loadFallbackFont()
+		if err != nil {
+			return layout, err
+		}
+		layout.Font = font
+		return layout, nil
+	}
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return layout, fmt.Errorf("error reading font: %v", err)
+	}
+	layout.Font = font
+	return layout, nil
+}
+
+// pdfFallbackFontDirs 查找备用字体的目录: 数据目录与程序所在目录下的 fonts
+var pdfFallbackFontDirs = func() []string {
+	dirs := []string{filepath.Join(config.DataDir(), "fonts")}
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Join(filepath.Dir(exe), "fonts"))
+	}
+	return dirs
+}
+
+func loadFallbackFont() ([]byte, error) {
+	if pdfEmbeddedFont != nil {
+		return pdfEmbeddedFont, nil
+	}
+	dirs := pdfFallbackFontDirs()
+	for _, dir := range dirs {
+		if font, err := os.ReadFile(filepath.Join(dir, pdfFallbackFontName)); err == nil {
+			return font, nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"no CJK font found, set pdf.font-path or put %s in %s",
+		pdfFallbackFontName, strings.Join(dirs, " or "),
+	)
+}
+
+func findSystemFont() string {
+	for _, path := range pdfFontCandidates[runtime.GOOS] {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,14 @@
+//go:build embedfont
+
+package merge
+
+import _ "embed"
+
+// 以 -tags embedfont 编译时将备用字体嵌入程序, 无需随程序分发 fonts 目录
+//
+//go:embed font/fallback.ttf
+var embeddedFallbackFont []byte
+
+func init() {
+	pdfEmbeddedFont = embeddedFallbackFont
+}
@@ -0,0 +1,126 @@
+package merge
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+const testFontPath = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+
+func TestPdfWriter(t *testing.T) {
+	if _, err := os.Stat(testFontPath); err != nil {
+		t.Skipf("font not available: %v", err)
+	}
+	conf := config.Info{}
+	conf.Pdf.PageSize = "120x180"
+	conf.Pdf.FontPath = testFontPath
+	layout, err := loadPdfLayout(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := &model.Book{BookName: "Book", Author: "Author", Intro: "Intro"}
+	chapters := []*model.Chapter{
+		{Title: "Prologue", Content: "first line\nsecond line\n"},
+		{Title: "第一卷 风起 第一章 开始", Content: "one\n"},
+		{Title: "第一卷 风起 第二章 继续", Content: "two\n"},
+	}
+	w, err := newPdfDocument(book, layout, []byte("not an image"), "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chapter := range chapters {
+		if err := w.WriteChapter(chapter); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := w.pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("missing PDF header")
+	}
+	if !bytes.Contains(data, []byte("/Outlines")) {
+		t.Errorf("missing outlines")
+	}
+	// 书名页 + 每章一页
+	if got := len(regexp.MustCompile(`/Type /Page\b`).FindAll(data, -1)); got != 1+len(chapters) {
+		t.Errorf("pages = %d, want %d", got, 1+len(chapters))
+	}
+	// 书名 + 卷 + 3 章
+	if got := len(regexp.MustCompile(`/Parent \d+ 0 R`).FindAll(data, -1)); got < 5 {
+		t.Errorf("outline items = %d, want at least 5", got)
+	}
+}
+
+func TestLoadPdfLayoutInvalidSize(t *testing.T) {
+	conf := config.Info{}
+	conf.Pdf.PageSize = "100xabc"
+	if _, err := loadPdfLayout(conf); err == nil {
+		t.Errorf("expected error for invalid page size")
+	}
+}
+
+func TestPdfFallbackFont(t *testing.T) {
+	// 模拟没有任何系统中文字体的环境, 备用字体放在数据目录的 fonts 下
+	savedCandidates, savedDirs := pdfFontCandidates, pdfFallbackFontDirs
+	defer func() { pdfFontCandidates, pdfFallbackFontDirs = savedCandidates, savedDirs }()
+	pdfFontCandidates = nil
+	dir := t.TempDir()
+	pdfFallbackFontDirs = func() []string { return []string{dir} }
+
+	if pdfEmbeddedFont == nil {
+		if _, err := loadPdfLayout(config.Info{}); err == nil {
+			t.Fatal("expected an error without any font")
+		}
+	}
+	font, err := os.ReadFile(filepath.Join("font", pdfFallbackFontName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, pdfFallbackFontName), font, 0644); err != nil {
+		t.Fatal(err)
+	}
+	layout, err := loadPdfLayout(config.Info{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(layout.Font, font) {
+		t.Fatal("should fall back to the bundled font")
+	}
+	book := &model.Book{BookName: "剑来", Author: "烽火戏诸侯"}
+	w, err := newPdfDocument(book, layout, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteChapter(&model.Chapter{Title: "第一章 惊蛰", Content: "二月二，龍抬頭。\n"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := w.pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/FontFile2")) {
+		t.Error("the bundled font should be embedded")
+	}
+}
+
+func TestPdfInvalidFont(t *testing.T) {
+	book := &model.Book{BookName: "书"}
+	for _, font := range [][]byte{nil, []byte("not a font"), append([]byte{0, 1, 0, 0}, make([]byte, 64)...)} {
+		// 封面出错时清除的错误不能掩盖字体错误
+		if _, err := newPdfDocument(book, pdfLayout{SizeStr: "A5", Margin: 15, FontSize: 12, Font: font},
+			[]byte("not an image"), "text/plain"); err == nil {
+			t.Errorf("expected an error for font %q", font)
+		}
+	}
+}
@@ -1,61 +1,61 @@
 package merge
 
 import (
+	"bufio"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"fy-novel/internal/model"
-	"fy-novel/pkg/utils"
 )
 
-func txtMergeHandler(book *model.Book, dirPath string) (string, error) {
+type txtWriter struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+}
+
+func newTxtWriter(book *model.Book, outputDir string) (Writer, error) {
 	outputPath := filepath.Join(
-		filepath.Dir(dirPath),
+		outputDir,
 		fmt.Sprintf("%s（%s）.txt", book.BookName, book.Author),
 	)
-	homePageFile, err := os.Create(outputPath)
+	file, err := os.Create(outputPath)
 	if err != nil {
-		return "", fmt.Errorf("txtMergeHandler error creating output file: %v", err)
+		return nil, fmt.Errorf("txtWriter error creating output file: %v", err)
 	}
-	defer homePageFile.Close()
+	w := &txtWriter{path: outputPath, file: file, buf: bufio.NewWriter(file)}
 	// 首页添加书籍信息
 	bookInfo := []string{
 		fmt.Sprintf("书名：%s", book.BookName),
 		fmt.Sprintf("作者：%s", book.Author),
 		fmt.Sprintf("简介：%s", book.Intro),
-		strings.Repeat("\u3000", 2),
+		strings.Repeat("　", 2),
 	}
-
 	for _, line := range bookInfo {
-		if _, err := homePageFile.WriteString(line + "\n"); err != nil {
-			return "", fmt.Errorf("txtMergeHandler error writing book info: %v", err)
+		if _, err := w.buf.WriteString(line + "\n"); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("txtWriter error writing book info: %v", err)
 		}
 	}
+	return w, nil
+}
 
-	// 获取需要合并的章节文件
-	filePaths, err := utils.GetSortedFilePaths(dirPath)
-	if err != nil {
-		return "", fmt.Errorf("txtMergeHandler error getting sorted file paths: %v", err)
+func (w *txtWriter) WriteChapter(chapter *model.Chapter) error {
+	if _, err := w.buf.WriteString(chapter.Content); err != nil {
+		return fmt.Errorf("txtWriter error writing %s: %v", chapter.Title, err)
 	}
+	return nil
+}
 
-	for _, f := range filePaths {
-		fh, err := os.Open(f)
-		if err != nil {
-			return "", fmt.Errorf("txtMergeHandler error reading file: %v", err)
-		}
-		_, err = io.Copy(homePageFile, fh)
-		if err != nil {
-			return "", fmt.Errorf("txtMergeHandler error copying from %s: %v", f, err)
-		}
+func (w *txtWriter) Close() (string, error) {
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return "", fmt.Errorf("txtWriter error flushing output file: %v", err)
 	}
-
-	// 删除 txt 格式的临时文件
-	err = os.RemoveAll(dirPath)
-	if err != nil {
-		return "", fmt.Errorf("epubMergeHandler Error removing temporary HTML files: %v", err)
+	if err := w.file.Close(); err != nil {
+		return "", fmt.Errorf("txtWriter error closing output file: %v", err)
 	}
-	return outputPath, nil
+	return w.path, nil
 }
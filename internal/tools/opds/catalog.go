@@ -0,0 +1,213 @@
+package opds
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fy-novel/internal/definition"
+	"fy-novel/internal/model"
+	epubTool "fy-novel/internal/tools/epub"
+	libraryTool "fy-novel/internal/tools/library"
+	"fy-novel/pkg/utils"
+)
+
+// Book OPDS 目录中的一本书
+type Book struct {
+	ID       string
+	Title    string
+	Author   string
+	Summary  string
+	Category string
+	// 书源中的封面地址, EPUB 中没有封面时使用
+	CoverURL string
+	// EPUB 中带有封面图片
+	EmbeddedCover bool
+	Updated       time.Time
+	Files         []File
+}
+
+// File 可下载的导出文件
+type File struct {
+	Format string
+	Path   string
+	Size   int64
+}
+
+// 提供下载的格式与类型
+var mediaTypes = map[string]string{
+	definition.NovelExtname_EPUB: "application/epub+zip",
+	definition.NovelExtname_TXT:  "text/plain; charset=utf-8",
+}
+
+// MediaType 格式对应的类型, 不提供下载的格式返回空
+func MediaType(format string) string {
+	return mediaTypes[format]
+}
+
+// HasCover 有内嵌或书源的封面
+func (b Book) HasCover() bool {
+	return b.EmbeddedCover || b.CoverURL != ""
+}
+
+// File 返回指定格式的文件
+func (b Book) File(format string) (File, bool) {
+	for _, f := range b.Files {
+		if f.Format == format {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Load 汇总书库记录与下载目录中的 epub/txt 文件, 最近更新的在前.
+// 书库中的书使用下载时记录的信息, 书库中没有记录的文件从 EPUB 元数据或文件名 "书名（作者）" 中读取书名与作者
+func Load(downloadDir string) ([]Book, error) {
+	entries, err := libraryTool.List()
+	if err != nil {
+		return nil, err
+	}
+	var books []Book
+	known := make(map[string]bool)
+	for _, entry := range entries {
+		book := fromEntry(entry)
+		if len(book.Files) == 0 {
+			continue
+		}
+		for _, f := range book.Files {
+			known[f.Path] = true
+		}
+		books = append(books, book)
+	}
+
+	scanned, err := scan(downloadDir, known)
+	if err != nil {
+		return nil, err
+	}
+	books = append(books, scanned...)
+	sort.SliceStable(books, func(i, j int) bool { return books[i].Updated.After(books[j].Updated) })
+	return books, nil
+}
+
+func fromEntry(entry model.LibraryEntry) Book {
+	book := Book{
+		ID:       entry.ID,
+		Title:    entry.Book.BookName,
+		Author:   entry.Book.Author,
+		Summary:  entry.Book.Intro,
+		Category: entry.Book.Category,
+		CoverURL: entry.Book.CoverURL,
+		Updated:  entry.DownloadedAt,
+	}
+	for _, o := range entry.Outputs {
+		if MediaType(o.Format) == "" {
+			continue
+		}
+		path, err := filepath.Abs(o.Path)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		book.Files = append(book.Files, File{Format: o.Format, Path: path, Size: info.Size()})
+		if o.Format == definition.NovelExtname_EPUB {
+			if meta, err := epubTool.ReadInfo(path); err == nil {
+				book.EmbeddedCover = meta.CoverPath != ""
+			}
+		}
+	}
+	return book
+}
+
+// scan 读取下载目录 (不含子目录) 中书库没有记录的文件
+func scan(downloadDir string, known map[string]bool) ([]Book, error) {
+	dir, err := filepath.Abs(downloadDir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opds error reading download directory: %v", err)
+	}
+	var books []Book
+	for _, f := range files {
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(f.Name())), ".")
+		path := filepath.Join(dir, f.Name())
+		if MediaType(format) == "" || !f.Type().IsRegular() || known[path] {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		book := Book{
+			ID:      fmt.Sprintf("f%x", utils.StringToUniqueHash(path)),
+			Updated: info.ModTime(),
+			Files:   []File{{Format: format, Path: path, Size: info.Size()}},
+		}
+		book.Title, book.Author = splitFileName(strings.TrimSuffix(f.Name(), filepath.Ext(f.Name())))
+		if format == definition.NovelExtname_EPUB {
+			if meta, err := epubTool.ReadInfo(path); err == nil {
+				book.Title = cmp.Or(meta.Title, book.Title)
+				book.Author = cmp.Or(meta.Author, book.Author)
+				book.Summary = meta.Description
+				book.EmbeddedCover = meta.CoverPath != ""
+			}
+		}
+		books = append(books, book)
+	}
+	return books, nil
+}
+
+// splitFileName 拆分导出文件名 "书名（作者）"
+func splitFileName(name string) (title, author string) {
+	if strings.HasSuffix(name, "）") {
+		if i := strings.LastIndex(name, "（"); i > 0 {
+			return name[:i], strings.TrimSuffix(name[i+len("（"):], "）")
+		}
+	}
+	return name, ""
+}
+
+// Find 按 ID 查找
+func Find(books []Book, id string) (Book, bool) {
+	for _, b := range books {
+		if b.ID == id {
+			return b, true
+		}
+	}
+	return Book{}, false
+}
+
+// ByAuthor 指定作者的书
+func ByAuthor(books []Book, author string) []Book {
+	var res []Book
+	for _, b := range books {
+		if b.Author == author {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// Search 书名或作者包含关键字的书, 忽略大小写
+func Search(books []Book, keyword string) []Book {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	var res []Book
+	for _, b := range books {
+		if strings.Contains(strings.ToLower(b.Title), keyword) || strings.Contains(strings.ToLower(b.Author), keyword) {
+			res = append(res, b)
+		}
+	}
+	return res
+}
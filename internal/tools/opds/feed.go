@@ -0,0 +1,273 @@
+// Package opds 生成 OPDS 1.2 目录 (Atom), 供 KOReader、Moon+ Reader 等阅读器浏览与下载本地书籍
+package opds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+)
+
+const (
+	NavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	AcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	OpenSearchType  = "application/opensearchdescription+xml"
+
+	relAcquisition = "http://opds-spec.org/acquisition"
+	relImage       = "http://opds-spec.org/image"
+	relThumbnail   = "http://opds-spec.org/image/thumbnail"
+	relSortNew     = "http://opds-spec.org/sort/new"
+
+	// 最近添加中的书籍数
+	RecentLimit = 50
+	// 没有作者的书归入的作者名
+	UnknownAuthor = "未知作者"
+)
+
+type Feed struct {
+	XMLName   xml.Name `xml:"feed"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	XmlnsDC   string   `xml:"xmlns:dc,attr"`
+	XmlnsOPDS string   `xml:"xmlns:opds,attr"`
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Author    Person   `xml:"author"`
+	Links     []Link   `xml:"link"`
+	Entries   []Entry  `xml:"entry"`
+}
+
+type Person struct {
+	Name string `xml:"name"`
+}
+
+type Link struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type Text struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type Category struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type Entry struct {
+	Title      string     `xml:"title"`
+	ID         string     `xml:"id"`
+	Updated    string     `xml:"updated"`
+	Authors    []Person   `xml:"author"`
+	Language   string     `xml:"dc:language,omitempty"`
+	Categories []Category `xml:"category"`
+	Summary    *Text      `xml:"summary,omitempty"`
+	Content    *Text      `xml:"content,omitempty"`
+	Links      []Link     `xml:"link"`
+}
+
+// Catalog 生成挂载在 Base (例如 /opds) 下的各个目录
+type Catalog struct {
+	Base  string
+	Title string
+}
+
+func (c Catalog) url(elem ...string) string {
+	return path.Join(append([]string{c.Base}, elem...)...)
+}
+
+// AuthorURL 作者目录的地址
+func (c Catalog) AuthorURL(author string) string {
+	return c.url("authors", url.PathEscape(author))
+}
+
+// FileURL 书籍文件的下载地址
+func (c Catalog) FileURL(id, format string) string {
+	return c.url("books", url.PathEscape(id), format)
+}
+
+// CoverURL 书籍封面的地址
+func (c Catalog) CoverURL(id string) string {
+	return c.url("books", url.PathEscape(id), "cover")
+}
+
+// SearchURL 搜索地址
+func (c Catalog) SearchURL(query string) string {
+	return c.url("search") + "?q=" + url.QueryEscape(query)
+}
+
+func (c Catalog) feed(id, title, self, kind string, updated time.Time) *Feed {
+	return &Feed{
+		Xmlns:     "http://www.w3.org/2005/Atom",
+		XmlnsDC:   "http://purl.org/dc/terms/",
+		XmlnsOPDS: "http://opds-spec.org/2010/catalog",
+		ID:        "urn:fy-novel:opds:" + id,
+		Title:     title,
+		Updated:   timestamp(updated),
+		Author:    Person{Name: c.Title},
+		Links: []Link{
+			{Rel: "self", Href: self, Type: kind},
+			{Rel: "start", Href: c.url(), Type: NavigationType},
+			{Rel: "search", Href: c.url("opensearch.xml"), Type: OpenSearchType},
+		},
+	}
+}
+
+// Root 根目录: 最近添加、全部书籍与按作者浏览
+func (c Catalog) Root(books []Book) *Feed {
+	updated := latest(books)
+	f := c.feed("root", c.Title, c.url(), NavigationType, updated)
+	nav := func(id, title, content, rel, href, kind string) Entry {
+		return Entry{
+			Title:   title,
+			ID:      "urn:fy-novel:opds:" + id,
+			Updated: timestamp(updated),
+			Content: &Text{Type: "text", Body: content},
+			Links:   []Link{{Rel: rel, Href: href, Type: kind}},
+		}
+	}
+	f.Entries = []Entry{
+		nav("recent", "最近添加", fmt.Sprintf("最近下载的 %d 本书", min(len(books), RecentLimit)), relSortNew, c.url("recent"), AcquisitionType),
+		nav("books", "全部书籍", fmt.Sprintf("共 %d 本书", len(books)), "subsection", c.url("books"), AcquisitionType),
+		nav("authors", "按作者", fmt.Sprintf("共 %d 位作者", len(Authors(books))), "subsection", c.url("authors"), NavigationType),
+	}
+	return f
+}
+
+// Authors 按作者分组, 作者按名称排序
+func Authors(books []Book) map[string][]Book {
+	authors := make(map[string][]Book)
+	for _, b := range books {
+		name := b.Author
+		if name == "" {
+			name = UnknownAuthor
+		}
+		authors[name] = append(authors[name], b)
+	}
+	return authors
+}
+
+// AuthorList 作者导航目录
+func (c Catalog) AuthorList(books []Book) *Feed {
+	updated := latest(books)
+	f := c.feed("authors", "按作者", c.url("authors"), NavigationType, updated)
+	authors := Authors(books)
+	names := make([]string, 0, len(authors))
+	for name := range authors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f.Entries = append(f.Entries, Entry{
+			Title:   name,
+			ID:      "urn:fy-novel:opds:author:" + url.PathEscape(name),
+			Updated: timestamp(latest(authors[name])),
+			Content: &Text{Type: "text", Body: fmt.Sprintf("%d 本书", len(authors[name]))},
+			Links:   []Link{{Rel: "subsection", Href: c.AuthorURL(name), Type: AcquisitionType}},
+		})
+	}
+	return f
+}
+
+// Books 书籍目录, id 与 self 区分不同的目录, 例如 "recent" 与 /opds/recent
+func (c Catalog) Books(id, title, self string, books []Book) *Feed {
+	f := c.feed(id, title, self, AcquisitionType, latest(books))
+	for _, b := range books {
+		f.Entries = append(f.Entries, c.entry(b))
+	}
+	return f
+}
+
+func (c Catalog) entry(b Book) Entry {
+	e := Entry{
+		Title:    b.Title,
+		ID:       "urn:fy-novel:book:" + b.ID,
+		Updated:  timestamp(b.Updated),
+		Language: "zh",
+	}
+	if b.Author != "" {
+		e.Authors = []Person{{Name: b.Author}}
+	}
+	if b.Category != "" {
+		e.Categories = []Category{{Term: b.Category, Label: b.Category}}
+	}
+	if b.Summary != "" {
+		e.Summary = &Text{Type: "text", Body: b.Summary}
+	}
+	if b.HasCover() {
+		cover := c.CoverURL(b.ID)
+		e.Links = append(e.Links, Link{Rel: relImage, Href: cover}, Link{Rel: relThumbnail, Href: cover})
+	}
+	for _, file := range b.Files {
+		e.Links = append(e.Links, Link{
+			Rel:    relAcquisition,
+			Href:   c.FileURL(b.ID, file.Format),
+			Type:   MediaType(file.Format),
+			Length: file.Size,
+		})
+	}
+	return e
+}
+
+// OpenSearch OpenSearch 描述文档, 阅读器由此得到搜索地址
+func (c Catalog) OpenSearch() ([]byte, error) {
+	doc := struct {
+		XMLName     xml.Name `xml:"OpenSearchDescription"`
+		Xmlns       string   `xml:"xmlns,attr"`
+		ShortName   string   `xml:"ShortName"`
+		Description string   `xml:"Description"`
+		InputEnc    string   `xml:"InputEncoding"`
+		OutputEnc   string   `xml:"OutputEncoding"`
+		URL         struct {
+			Type     string `xml:"type,attr"`
+			Template string `xml:"template,attr"`
+		} `xml:"Url"`
+	}{
+		Xmlns:       "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:   c.Title,
+		Description: "按书名或作者搜索",
+		InputEnc:    "UTF-8",
+		OutputEnc:   "UTF-8",
+	}
+	doc.URL.Type = AcquisitionType
+	doc.URL.Template = c.url("search") + "?q={searchTerms}"
+	return marshal(doc)
+}
+
+// Marshal 输出带 XML 声明的文档
+func (f *Feed) Marshal() ([]byte, error) {
+	return marshal(f)
+}
+
+func marshal(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// latest 最近的更新时间, 没有书籍时为当前时间
+func latest(books []Book) time.Time {
+	var t time.Time
+	for _, b := range books {
+		if b.Updated.After(t) {
+			t = b.Updated
+		}
+	}
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
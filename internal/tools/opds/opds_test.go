@@ -0,0 +1,126 @@
+package opds
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/model"
+	libraryTool "fy-novel/internal/tools/library"
+)
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "downloads")
+	os.MkdirAll(dir, 0755)
+
+	// 书库中的书, html 不提供下载
+	txt := filepath.Join(dir, "剑来（烽火戏诸侯）.txt")
+	os.WriteFile(txt, []byte("正文"), 0644)
+	book := &model.Book{URL: "https://example.com/1", BookName: "剑来", Author: "烽火戏诸侯", CoverURL: "https://example.com/1.jpg"}
+	libraryTool.Record(book, 1, nil, model.LibraryOutput{Format: "txt", Path: txt}, 1)
+	libraryTool.Record(book, 1, nil, model.LibraryOutput{Format: "html", Path: filepath.Join(dir, "剑来.html")}, 1)
+	// 文件已删除的书不出现在目录中
+	libraryTool.Record(&model.Book{URL: "https://example.com/2", BookName: "已删除"}, 1, nil,
+		model.LibraryOutput{Format: "epub", Path: filepath.Join(dir, "已删除.epub")}, 1)
+	// 书库中没有记录的文件
+	os.WriteFile(filepath.Join(dir, "雪中悍刀行（烽火戏诸侯）.txt"), []byte("正文"), 0644)
+	os.WriteFile(filepath.Join(dir, "无作者.txt"), []byte("正文"), 0644)
+	os.WriteFile(filepath.Join(dir, "说明.md"), []byte("x"), 0644)
+
+	books, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := map[string]Book{}
+	for _, b := range books {
+		titles[b.Title] = b
+	}
+	if len(books) != 3 {
+		t.Fatalf("books = %+v", books)
+	}
+	jl := titles["剑来"]
+	if len(jl.Files) != 1 || jl.Files[0].Format != "txt" || jl.Files[0].Size != int64(len("正文")) || !jl.HasCover() {
+		t.Errorf("library book = %+v", jl)
+	}
+	if b := titles["雪中悍刀行"]; b.Author != "烽火戏诸侯" || b.ID == "" || b.HasCover() {
+		t.Errorf("scanned book = %+v", b)
+	}
+	if b := titles["无作者"]; b.Author != "" {
+		t.Errorf("scanned book without author = %+v", b)
+	}
+	if got := len(ByAuthor(books, "烽火戏诸侯")); got != 2 {
+		t.Errorf("by author = %d", got)
+	}
+	if got := Search(books, "雪中"); len(got) != 1 {
+		t.Errorf("search = %+v", got)
+	}
+	if _, ok := Find(books, jl.ID); !ok {
+		t.Error("find by id")
+	}
+
+	if books, err := Load(filepath.Join(home, "missing")); err != nil || len(books) != 1 {
+		t.Errorf("missing download directory: %v %v", books, err)
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	c := Catalog{Base: "/opds", Title: "fy-novel"}
+	books := []Book{
+		{ID: "a1", Title: "剑来 & 雪中", Author: "烽火戏诸侯", Summary: "<简介>", CoverURL: "https://example.com/1.jpg",
+			Files: []File{{Format: "epub", Size: 10}, {Format: "txt", Size: 5}}},
+		{ID: "b2", Title: "无名"},
+	}
+
+	root, err := c.Root(books).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`href="/opds/recent"`, `href="/opds/authors"`, `href="/opds/opensearch.xml"`, NavigationType} {
+		if !strings.Contains(string(root), want) {
+			t.Errorf("root feed missing %s", want)
+		}
+	}
+
+	data, err := c.Books("all", "全部书籍", "/opds/books", books).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed Feed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("entries = %+v", feed.Entries)
+	}
+	var acquisitions, images int
+	for _, l := range feed.Entries[0].Links {
+		switch l.Rel {
+		case relAcquisition:
+			acquisitions++
+		case relImage:
+			images++
+			if l.Href != "/opds/books/a1/cover" {
+				t.Errorf("cover link = %s", l.Href)
+			}
+		}
+	}
+	if acquisitions != 2 || images != 1 || len(feed.Entries[1].Links) != 0 {
+		t.Errorf("links = %+v %+v", feed.Entries[0].Links, feed.Entries[1].Links)
+	}
+	if !strings.Contains(string(data), `<dc:language>zh</dc:language>`) || !strings.Contains(string(data), `length="10"`) {
+		t.Errorf("feed = %s", data)
+	}
+
+	authors, _ := c.AuthorList(books).Marshal()
+	if !strings.Contains(string(authors), UnknownAuthor) || !strings.Contains(string(authors), "/opds/authors/%E7%83%BD") {
+		t.Errorf("authors feed = %s", authors)
+	}
+	search, _ := c.OpenSearch()
+	if !strings.Contains(string(search), `template="/opds/search?q={searchTerms}"`) {
+		t.Errorf("opensearch = %s", search)
+	}
+}
@@ -0,0 +1,247 @@
+package quality
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"fy-novel/internal/model"
+)
+
+// 章节质量检查, 按以下几项累计扣分, 分数越高越可疑:
+//   - 抓取失败
+//   - 正文为空
+//   - 字数远低于全书中位数, 或本身只有几十个字
+//   - 含有 "正在手打中" 等占位文字
+//   - 乱码 (替换字符、私用区、控制字符、常见的编码错误字符) 比例过高
+//   - 长篇正文没有分段, 多半是段落标签丢失
+
+const (
+	ReasonFetchFailed  = "fetch-failed"
+	ReasonEmpty        = "empty"
+	ReasonShort        = "short"
+	ReasonPlaceholder  = "placeholder"
+	ReasonGarbage      = "garbage"
+	ReasonNoParagraphs = "no-paragraphs"
+
+	// DefaultThreshold 未配置时, 分数达到该值视为可疑章节
+	DefaultThreshold = 50
+
+	// 少于该字数的章节无论全书情况如何都视为过短
+	stubLength = 100
+	// 章节数达到该值时才与中位数比较
+	minSamples = 5
+)
+
+var placeholders = []string{
+	"正在手打中",
+	"手打中",
+	"请稍后再来",
+	"请稍等片刻",
+	"内容更新后",
+	"重新刷新页面",
+	"章节内容正在",
+	"努力更新中",
+	"防盗章节",
+	"此章节为防盗",
+	"暂无内容",
+	"内容加载失败",
+	"请刷新重试",
+}
+
+var (
+	paragraphPattern = regexp.MustCompile(`(?s)<p\b[^>]*>.*?</p>`)
+	tagPattern       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Sample 单个章节的统计信息
+type Sample struct {
+	Seq         int
+	ChapterNo   int
+	Title       string
+	URL         string
+	Length      int
+	Paragraphs  int
+	Placeholder string
+	Garbage     float64
+	// 抓取失败, 没有正文
+	Failed bool
+}
+
+// Measure 统计清洗后的 <p> 段落正文
+func Measure(seq int, chapter *model.Chapter) Sample {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(chapter.Content, ""))
+	s := Sample{
+		Seq:        seq,
+		ChapterNo:  chapter.ChapterNo,
+		Title:      chapter.Title,
+		URL:        chapter.URL,
+		Paragraphs: len(paragraphPattern.FindAllStringIndex(chapter.Content, -1)),
+	}
+	garbage := 0
+	prev := rune(-1)
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			prev = r
+			continue
+		}
+		s.Length++
+		switch {
+		case isMojibake(prev, r):
+			garbage += 2
+		case isGarbage(r):
+			garbage++
+		}
+		prev = r
+	}
+	if s.Length > 0 {
+		s.Garbage = float64(garbage) / float64(s.Length)
+	}
+	for _, p := range placeholders {
+		if strings.Contains(text, p) {
+			s.Placeholder = p
+			break
+		}
+	}
+	return s
+}
+
+// isGarbage 编码错误时常见的字符, 中文正文中基本不会出现
+func isGarbage(r rune) bool {
+	switch {
+	case r == utf8.RuneError:
+		return true
+	case unicode.IsControl(r):
+		return true
+	case unicode.In(r, unicode.Co):
+		return true
+	}
+	return false
+}
+
+// Windows-1252 中 0x80-0x9F 对应的字符
+const cp1252Trail = "€‚ƒ„…†‡ˆ‰Š‹ŒŽ‘’“”•–—˜™š›œžŸ"
+
+// isMojibake UTF-8 按 Latin-1/Windows-1252 解码产生的字符对, 如 "ä»" "æŠ":
+// 首字节变为 À-ÿ, 紧跟的后续字节变为 U+0080-U+00BF 或 cp1252 符号.
+// 单独出现的 é ü 等 (拼音、外文人名) 不算
+func isMojibake(prev, r rune) bool {
+	if prev < 0x00C0 || prev > 0x00FF {
+		return false
+	}
+	return r >= 0x0080 && r <= 0x00BF || strings.ContainsRune(cp1252Trail, r)
+}
+
+// Score 按全书中位字数评分, median 为 0 时不做比较
+func Score(s Sample, median int) (int, []string) {
+	score := 0
+	var reasons []string
+	add := func(points int, reason string) {
+		score += points
+		reasons = append(reasons, reason)
+	}
+	if s.Failed {
+		add(100, ReasonFetchFailed)
+		return score, reasons
+	}
+	if s.Length == 0 {
+		add(100, ReasonEmpty)
+		return score, reasons
+	}
+	switch {
+	case s.Length < stubLength:
+		add(60, ReasonShort)
+	case median > 0 && s.Length*5 < median:
+		add(60, ReasonShort)
+	case median > 0 && s.Length*5 < median*2:
+		add(30, ReasonShort)
+	}
+	if s.Placeholder != "" {
+		// 长篇正文中偶尔提到占位文字不必在意
+		if s.Length < 500 {
+			add(80, ReasonPlaceholder)
+		} else {
+			add(20, ReasonPlaceholder)
+		}
+	}
+	switch {
+	case s.Garbage > 0.05:
+		add(60, ReasonGarbage)
+	case s.Garbage > 0.01:
+		add(20, ReasonGarbage)
+	}
+	if s.Paragraphs <= 1 && s.Length > 300 {
+		add(30, ReasonNoParagraphs)
+	}
+	return score, reasons
+}
+
+// Analyzer 收集全书章节后按中位数评分
+type Analyzer struct {
+	samples []Sample
+}
+
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{}
+}
+
+func (a *Analyzer) Add(seq int, chapter *model.Chapter) {
+	a.samples = append(a.samples, Measure(seq, chapter))
+}
+
+// AddFailed 记录抓取失败的章节, chapter 只需目录信息, 不参与中位数
+func (a *Analyzer) AddFailed(seq int, chapter *model.Chapter) {
+	a.samples = append(a.samples, Sample{
+		Seq:       seq,
+		ChapterNo: chapter.ChapterNo,
+		Title:     chapter.Title,
+		URL:       chapter.URL,
+		Failed:    true,
+	})
+}
+
+// Median 全书章节字数中位数, 章节过少时返回 0
+func (a *Analyzer) Median() int {
+	lengths := make([]int, 0, len(a.samples))
+	for _, s := range a.samples {
+		if !s.Failed {
+			lengths = append(lengths, s.Length)
+		}
+	}
+	if len(lengths) < minSamples {
+		return 0
+	}
+	slices.Sort(lengths)
+	return lengths[len(lengths)/2]
+}
+
+// Suspects 按 Seq 顺序返回分数达到 threshold 的章节, threshold 不大于 0 时使用默认值
+func (a *Analyzer) Suspects(threshold int) []model.SuspectChapter {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	median := a.Median()
+	var res []model.SuspectChapter
+	for _, s := range a.samples {
+		score, reasons := Score(s, median)
+		if score < threshold {
+			continue
+		}
+		res = append(res, model.SuspectChapter{
+			Seq:       s.Seq,
+			ChapterNo: s.ChapterNo,
+			Title:     s.Title,
+			URL:       s.URL,
+			Length:    s.Length,
+			Score:     score,
+			Reasons:   reasons,
+		})
+	}
+	slices.SortFunc(res, func(a, b model.SuspectChapter) int {
+		return a.Seq - b.Seq
+	})
+	return res
+}
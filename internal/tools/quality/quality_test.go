@@ -0,0 +1,65 @@
+package quality
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"fy-novel/internal/model"
+)
+
+func paragraphs(n int, text string) string {
+	return strings.Repeat("<p>"+text+"</p>", n)
+}
+
+func TestScore(t *testing.T) {
+	line := "他抬起头，看着远处的山峰，心中暗暗下定了决心。"
+	cases := []struct {
+		name    string
+		content string
+		median  int
+		reasons []string
+	}{
+		{"normal", paragraphs(40, line), 900, nil},
+		{"empty", "<p> </p>", 900, []string{ReasonEmpty}},
+		{"placeholder", "<p>正在手打中，请稍等片刻，内容更新后，请重新刷新页面，即可获取最新更新！</p>", 900,
+			[]string{ReasonShort, ReasonPlaceholder}},
+		{"short", paragraphs(6, line), 2000, []string{ReasonShort}},
+		{"garbage", paragraphs(40, "ä»–æŠ¬èµ·å¤´ï¼Œçœ‹ç�€è¿œå¤„çš„å±±å³°"), 900, []string{ReasonGarbage}},
+		// 拼音与外文人名中的 ā é ü 不是乱码
+		{"accents", paragraphs(40, "Lǐ Bái 与 José、Zoë、Müller 在 Crêpe 咖啡馆，ñ à ô。"), 900, nil},
+		{"replacement", paragraphs(40, "他抬起头���看着远处"), 900, []string{ReasonGarbage}},
+		{"no paragraphs", "<p>" + strings.Repeat(line, 40) + "</p>", 900, []string{ReasonNoParagraphs}},
+	}
+	for _, c := range cases {
+		_, reasons := Score(Measure(0, &model.Chapter{Content: c.content}), c.median)
+		if !reflect.DeepEqual(reasons, c.reasons) {
+			t.Errorf("%s: reasons = %v, want %v", c.name, reasons, c.reasons)
+		}
+	}
+}
+
+func TestSuspects(t *testing.T) {
+	a := NewAnalyzer()
+	line := "他抬起头，看着远处的山峰，心中暗暗下定了决心。"
+	for i := 0; i < 10; i++ {
+		content := paragraphs(40, line)
+		if i == 4 {
+			content = "<p>章节内容正在手打中</p>"
+		}
+		a.Add(i, &model.Chapter{ChapterNo: i + 1, Title: "第" + string(rune('一'+i)) + "章", Content: content})
+	}
+	// 抓取失败的章节保留目录中的地址, 用于重新获取
+	a.AddFailed(10, &model.Chapter{ChapterNo: 11, Title: "第十一章", URL: "https://example.com/11.html"})
+	suspects := a.Suspects(0)
+	if len(suspects) != 2 || suspects[0].Seq != 4 || suspects[0].Score < DefaultThreshold {
+		t.Errorf("suspects = %+v", suspects)
+	}
+	if failed := suspects[len(suspects)-1]; failed.Seq != 10 || failed.URL != "https://example.com/11.html" ||
+		!reflect.DeepEqual(failed.Reasons, []string{ReasonFetchFailed}) {
+		t.Errorf("failed chapter = %+v", failed)
+	}
+	if a.Median() != 40*len([]rune(line)) {
+		t.Errorf("median = %d", a.Median())
+	}
+}
@@ -0,0 +1,172 @@
+// Package ratelimit 按域名限制抓取请求的频率与并发数 (令牌桶), 同一域名的全部采集器共用.
+// 书源返回 429、503 或限流提示页时减半速率并按 Retry-After 暂停, 之后每次成功的请求逐步恢复
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"fy-novel/internal/model"
+)
+
+const (
+	// DefaultRPS 书源规则未设置时的每秒请求数
+	DefaultRPS = 5.0
+	// 被限流时的速率下限为设定值的 1/minRateDivisor
+	minRateDivisor = 16
+	// 每次成功的请求恢复设定值的 1/recoverSteps
+	recoverSteps = 20
+	// 没有 Retry-After 时被限流后暂停的时间
+	defaultPause = 5 * time.Second
+	// Retry-After 过长时最多暂停的时间, 之后的请求被限流会再次暂停
+	maxPause = time.Minute
+)
+
+// Limiter 一个域名的限制
+type Limiter struct {
+	mu          sync.Mutex
+	rps         float64
+	burst       int
+	concurrency int
+	// 当前速率, 被限流时低于 rps
+	rate   float64
+	tokens float64
+	last   time.Time
+	// 被限流后在此之前不发出请求
+	pausedUntil time.Time
+	inflight    int
+	// 有请求结束时关闭并替换, 通知等待并发名额的请求
+	released chan struct{}
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*Limiter)
+)
+
+// For 域名 (含端口) 共用的 Limiter, 按书源规则更新设定
+func For(host string, rule model.Rule) *Limiter {
+	limitersMu.Lock()
+	l, ok := limiters[host]
+	if !ok {
+		l = &Limiter{released: make(chan struct{}), last: time.Now()}
+		limiters[host] = l
+	}
+	limitersMu.Unlock()
+	l.configure(rule)
+	return l
+}
+
+func (l *Limiter) configure(rule model.Rule) {
+	limit := rule.RateLimit
+	rps := limit.RPS
+	if rps <= 0 {
+		rps = DefaultRPS
+	}
+	burst := max(1, limit.Burst)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rps == rps && l.burst == burst && l.concurrency == limit.Concurrency {
+		return
+	}
+	// 设定变化时保持当前的降速比例
+	if l.rps == 0 {
+		l.rate = rps
+		l.tokens = float64(burst)
+	} else {
+		l.rate = rps * l.rate / l.rps
+	}
+	l.rps, l.burst, l.concurrency = rps, burst, limit.Concurrency
+}
+
+// refill 按经过的时间补充令牌, 暂停期间不补充
+func (l *Limiter) refill(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+	l.tokens = min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// Acquire 等待并发名额与令牌, 请求结束后须调用 Release
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refill(now)
+		var wait time.Duration
+		released := l.released
+		switch {
+		case l.concurrency > 0 && l.inflight >= l.concurrency:
+		case now.Before(l.pausedUntil):
+			wait = l.pausedUntil.Sub(now)
+		case l.tokens < 1:
+			wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		default:
+			l.tokens--
+			l.inflight++
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		// 等待并发名额时 wait 为 0, 只等有请求结束
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			expired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-released:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Release 请求结束, 让出并发名额
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Succeeded 请求成功, 被限流后的速率逐步恢复
+func (l *Limiter) Succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = min(l.rps, l.rate+l.rps/recoverSteps)
+}
+
+// Throttled 被限流: 速率减半并暂停 retryAfter, 为 0 时暂停 defaultPause
+func (l *Limiter) Throttled(retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = max(l.rps/minRateDivisor, l.rate/2)
+	if retryAfter <= 0 {
+		retryAfter = defaultPause
+	}
+	retryAfter = min(retryAfter, maxPause)
+	if until := time.Now().Add(retryAfter); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		l.last = until
+	}
+	l.tokens = 0
+}
+
+// Rate 当前的每秒请求数
+func (l *Limiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
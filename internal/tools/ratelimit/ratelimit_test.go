@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fy-novel/internal/model"
+)
+
+func rule(rps float64, burst, concurrency int) model.Rule {
+	var r model.Rule
+	r.RateLimit.RPS = rps
+	r.RateLimit.Burst = burst
+	r.RateLimit.Concurrency = concurrency
+	return r
+}
+
+func TestLimiter(t *testing.T) {
+	// 限制按域名全局共享, 每次运行用不同的域名
+	host := fmt.Sprintf("limiter-%d.test", time.Now().UnixNano())
+	l := For(host, rule(20, 2, 0))
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+		l.Release()
+	}
+	// 前两个请求用掉突发额度, 第三个等一个令牌 (50ms)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Errorf("3 requests at 20/s with burst 2 took %v", elapsed)
+	}
+	if For(host, rule(20, 2, 0)) != l {
+		t.Error("the same host should share a limiter")
+	}
+
+	l.Throttled(100 * time.Millisecond)
+	if l.Rate() != 10 {
+		t.Errorf("rate after throttling = %v, want 10", l.Rate())
+	}
+	start = time.Now()
+	if err := l.Acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	l.Release()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("request during Retry-After pause waited only %v", elapsed)
+	}
+	for i := 0; i < 10; i++ {
+		l.Throttled(time.Millisecond)
+	}
+	if l.Rate() != 20.0/minRateDivisor {
+		t.Errorf("rate should not drop below %v, got %v", 20.0/minRateDivisor, l.Rate())
+	}
+	for i := 0; i < recoverSteps; i++ {
+		l.Succeeded()
+	}
+	if l.Rate() != 20 {
+		t.Errorf("rate after recovering = %v, want 20", l.Rate())
+	}
+
+	// 等待中的请求可被取消
+	l.Throttled(time.Minute)
+	cancelled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(cancelled); err != context.DeadlineExceeded {
+		t.Errorf("acquire during pause = %v", err)
+	}
+}
+
+func TestTransportConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(rule(1000, 10, 2), http.DefaultTransport)}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if peak.Load() != 2 {
+		t.Errorf("peak concurrent requests = %d, want 2", peak.Load())
+	}
+}
+
+func TestTransportThrottle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/busy":
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/page":
+			// GBK 编码的 "访问过于频繁"
+			w.Header().Set("Content-Type", "text/html; charset=gbk")
+			w.Write([]byte{0xb7, 0xc3, 0xce, 0xca, 0xb9, 0xfd, 0xd3, 0xda, 0xc6, 0xb5, 0xb7, 0xb1})
+		case "/custom":
+			io.WriteString(w, "<p>slow down</p>")
+		default:
+			io.WriteString(w, "<p>第一章 访问过于频繁</p>"+strings.Repeat("正文", maxThrottlePage))
+		}
+	}))
+	defer srv.Close()
+
+	r := rule(100, 1, 0)
+	r.RateLimit.ThrottleText = []string{"slow down"}
+	tr := NewTransport(r, http.DefaultTransport)
+	get := func(path string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		resp, err := tr.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		// 测试不等暂停结束
+		l := For(req.URL.Host, r)
+		l.mu.Lock()
+		l.pausedUntil, l.last, l.tokens = time.Time{}, time.Now(), 1
+		l.mu.Unlock()
+		return resp.StatusCode
+	}
+
+	if code := get("/chapter"); code != http.StatusOK {
+		t.Errorf("long chapter mentioning the text should not count as throttled, got %d", code)
+	}
+	if code := get("/busy"); code != http.StatusServiceUnavailable {
+		t.Errorf("busy = %d", code)
+	}
+	if code := get("/page"); code != http.StatusTooManyRequests {
+		t.Errorf("throttle page should become 429, got %d", code)
+	}
+	if code := get("/custom"); code != http.StatusTooManyRequests {
+		t.Errorf("rule throttle text should become 429, got %d", code)
+	}
+	if rate := For(strings.TrimPrefix(srv.URL, "http://"), r).Rate(); rate != 100.0/8 {
+		t.Errorf("rate after 3 throttled responses = %v, want %v", rate, 100.0/8)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d := retryAfter("3"); d != 3*time.Second {
+		t.Errorf("seconds = %v", d)
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if d := retryAfter(date); d < 58*time.Second || d > time.Minute {
+		t.Errorf("date = %v", d)
+	}
+	if d := retryAfter("soon"); d != 0 {
+		t.Errorf("invalid = %v", d)
+	}
+}
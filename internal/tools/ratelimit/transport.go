@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fy-novel/internal/model"
+
+	"golang.org/x/net/html/charset"
+)
+
+// DefaultThrottleText 常见的限流提示, 书源规则可补充
+var DefaultThrottleText = []string{"访问过于频繁", "请求过于频繁", "操作过于频繁", "访问太频繁", "Too Many Requests"}
+
+// 限流提示页通常很短, 只检查不超过该大小的页面, 以免章节正文中的文字被误判
+const maxThrottlePage = 8 << 10
+
+// Transport 按请求的域名限制频率, 根据响应调整速率
+type Transport struct {
+	Base http.RoundTripper
+	Rule model.Rule
+}
+
+func NewTransport(rule model.Rule, base http.RoundTripper) *Transport {
+	return &Transport{Base: base, Rule: rule}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := For(req.URL.Host, t.Rule)
+	if err := l.Acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	defer l.Release()
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// 读完响应体再让出并发名额
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		l.Throttled(retryAfter(resp.Header.Get("Retry-After")))
+	case resp.StatusCode == http.StatusOK && t.throttlePage(body, resp.Header.Get("Content-Type")):
+		l.Throttled(retryAfter(resp.Header.Get("Retry-After")))
+		// 改为 429, 提示页不写入抓取缓存, 并由采集器重试
+		resp.StatusCode = http.StatusTooManyRequests
+		resp.Status = "429 " + http.StatusText(http.StatusTooManyRequests)
+	default:
+		l.Succeeded()
+	}
+	return resp, nil
+}
+
+func (t *Transport) throttlePage(body []byte, contentType string) bool {
+	if len(body) > maxThrottlePage {
+		return false
+	}
+	// 书源多为 GBK 编码, 转为 UTF-8 后再查找
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return false
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return false
+	}
+	page := string(text)
+	for _, list := range [][]string{DefaultThrottleText, t.Rule.RateLimit.ThrottleText} {
+		for _, s := range list {
+			if s != "" && strings.Contains(page, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retryAfter 解析秒数或 HTTP 日期, 无法解析时为 0
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
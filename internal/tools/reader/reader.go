@@ -0,0 +1,154 @@
+// Package reader 内置阅读器: 从抓取日志读取章节, 导出前也可以阅读
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"fy-novel/internal/model"
+	dictTool "fy-novel/internal/tools/dict"
+	journalTool "fy-novel/internal/tools/journal"
+	libraryTool "fy-novel/internal/tools/library"
+)
+
+var (
+	// ErrNoJournal 书籍没有抓取日志, 无法阅读
+	ErrNoJournal = errors.New("reader: no crawl journal for this book, download it again to read")
+	// ErrChapterUnavailable 章节抓取失败或尚未抓取
+	ErrChapterUnavailable = errors.New("reader: chapter not available")
+)
+
+// Books 有抓取日志的书, 最近阅读或抓取的在前
+func Books() ([]model.ReaderBook, error) {
+	journals, err := journalTool.List()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := libraryTool.List()
+	if err != nil {
+		return nil, err
+	}
+	library := make(map[string]model.LibraryEntry, len(entries))
+	for _, e := range entries {
+		library[e.ID] = e
+	}
+	states, err := libraryTool.ReadingStates()
+	if err != nil {
+		return nil, err
+	}
+	books := make([]model.ReaderBook, 0, len(journals))
+	for _, j := range journals {
+		id := j.ID()
+		book := model.ReaderBook{
+			ID:        id,
+			Book:      j.Book,
+			SourceID:  j.SourceID,
+			FetchedAt: j.CreatedAt,
+		}
+		// 书库中的记录更新, 例如重新导出后的书籍信息
+		if entry, ok := library[id]; ok {
+			book.Book = entry.Book
+			book.InLibrary = true
+		}
+		if state, ok := states[id]; ok {
+			book.Reading = &state
+		}
+		books = append(books, book)
+	}
+	sort.SliceStable(books, func(i, j int) bool {
+		return lastActive(books[i]).After(lastActive(books[j]))
+	})
+	return books, nil
+}
+
+func lastActive(b model.ReaderBook) time.Time {
+	if b.Reading != nil && b.Reading.UpdatedAt.After(b.FetchedAt) {
+		return b.Reading.UpdatedAt
+	}
+	return b.FetchedAt
+}
+
+func open(id string) (*journalTool.Journal, error) {
+	j, err := journalTool.ReadByID(id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoJournal
+	}
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Chapters 书籍目录. 有书库目录快照时按快照列出全部章节, 并标出日志中没有正文的章节;
+// 还没有导出的书只列出日志中已抓取的章节
+func Chapters(id string) ([]model.ReaderChapter, error) {
+	j, err := open(id)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[int]journalTool.Entry, len(j.Chapters))
+	for _, e := range j.Chapters {
+		available[e.Seq] = e
+	}
+	catalog, err := libraryTool.Catalog(id)
+	if err != nil && !errors.Is(err, libraryTool.ErrNotFound) {
+		return nil, err
+	}
+	// 日志比快照新 (例如重新下载了更多章节) 时快照已不对应
+	if len(j.Chapters) > 0 && j.Chapters[len(j.Chapters)-1].Seq >= len(catalog) {
+		catalog = nil
+	}
+	if catalog == nil {
+		res := make([]model.ReaderChapter, len(j.Chapters))
+		for i, e := range j.Chapters {
+			res[i] = model.ReaderChapter{Seq: e.Seq, ChapterNo: e.ChapterNo, Title: e.Title, Available: true}
+		}
+		return res, nil
+	}
+	res := make([]model.ReaderChapter, len(catalog))
+	for seq, item := range catalog {
+		res[seq] = model.ReaderChapter{Seq: seq, ChapterNo: item.ChapterNo, Title: item.Title}
+		if e, ok := available[seq]; ok {
+			// 日志中是整理后的标题
+			res[seq].Title = e.Title
+			res[seq].Available = true
+		}
+	}
+	return res, nil
+}
+
+// Chapter 读取一章, 与导出时一样应用替换词典, 再清理为只含段落与基本格式的 HTML
+func Chapter(id string, seq int) (*model.ReaderChapterContent, error) {
+	j, err := open(id)
+	if err != nil {
+		return nil, err
+	}
+	i := sort.Search(len(j.Chapters), func(i int) bool { return j.Chapters[i].Seq >= seq })
+	if i == len(j.Chapters) || j.Chapters[i].Seq != seq {
+		return nil, fmt.Errorf("%w: %d", ErrChapterUnavailable, seq)
+	}
+	entry := j.Chapters[i]
+	replacer, err := dictTool.ForScope(dictTool.Scope{Book: j.Book.BookName, SourceID: j.SourceID})
+	if err != nil {
+		return nil, err
+	}
+	content, _ := replacer.Apply(entry.Content)
+	res := &model.ReaderChapterContent{
+		BookID:  id,
+		Seq:     seq,
+		Title:   entry.Title,
+		Content: Sanitize(content),
+		Prev:    -1,
+		Next:    -1,
+	}
+	if i > 0 {
+		res.Prev = j.Chapters[i-1].Seq
+	}
+	if i+1 < len(j.Chapters) {
+		res.Next = j.Chapters[i+1].Seq
+	}
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package reader
+
+import (
+	"errors"
+	"testing"
+
+	"fy-novel/internal/model"
+	dictTool "fy-novel/internal/tools/dict"
+	journalTool "fy-novel/internal/tools/journal"
+	libraryTool "fy-novel/internal/tools/library"
+)
+
+func writeJournal(t *testing.T, book *model.Book, chapters map[int]*model.Chapter) {
+	t.Helper()
+	w, err := journalTool.Create(book, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq, c := range chapters {
+		if err := w.Append(seq, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if books, err := Books(); err != nil || len(books) != 0 {
+		t.Fatalf("no journals: %v, %v", books, err)
+	}
+
+	// 第二章抓取失败, 日志中没有
+	book := &model.Book{URL: "https://example.com/reader/1", BookName: "阅读测试", Author: "作者"}
+	writeJournal(t, book, map[int]*model.Chapter{
+		0: {ChapterNo: 1, Title: "第一章", Content: "<p>旧词</p>"},
+		2: {ChapterNo: 3, Title: "第三章", Content: `<p onclick="x()">正文<script>alert(1)</script></p>`},
+	})
+	id := libraryTool.ID(book.URL)
+
+	books, err := Books()
+	if err != nil || len(books) != 1 || books[0].ID != id || books[0].InLibrary || books[0].Reading != nil {
+		t.Fatalf("books = %+v, %v", books, err)
+	}
+
+	// 导出前只有日志中的章节
+	chapters, err := Chapters(id)
+	if err != nil || len(chapters) != 2 || chapters[1].Seq != 2 || !chapters[1].Available {
+		t.Errorf("journal chapters = %+v, %v", chapters, err)
+	}
+
+	catalog := []*model.Chapter{{ChapterNo: 1, Title: "第一章"}, {ChapterNo: 2, Title: "第二章"}, {ChapterNo: 3, Title: "第三章"}}
+	if _, err := libraryTool.Record(book, 1, catalog, model.LibraryOutput{Format: "txt", Path: "x.txt"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	chapters, err = Chapters(id)
+	if err != nil || len(chapters) != 3 || chapters[1].Available || chapters[1].Title != "第二章" || !chapters[2].Available {
+		t.Errorf("library chapters = %+v, %v", chapters, err)
+	}
+
+	if _, err := dictTool.Put(model.DictEntry{Pattern: "旧词", Replace: "新词", Book: book.BookName}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := Chapter(id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Content != "<p>新词</p>" || first.Prev != -1 || first.Next != 2 {
+		t.Errorf("first chapter = %+v", first)
+	}
+	third, err := Chapter(id, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Content != "<p>正文</p>" || third.Prev != 0 || third.Next != -1 {
+		t.Errorf("third chapter = %+v", third)
+	}
+	if _, err := Chapter(id, 1); !errors.Is(err, ErrChapterUnavailable) {
+		t.Errorf("missing chapter error = %v", err)
+	}
+	if _, err := Chapter("0123abcd", 0); !errors.Is(err, ErrNoJournal) {
+		t.Errorf("unknown book error = %v", err)
+	}
+
+	if _, err := libraryTool.SavePosition(id, 2, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	books, _ = Books()
+	if len(books) != 1 || !books[0].InLibrary || books[0].Reading == nil || books[0].Reading.Seq != 2 {
+		t.Errorf("books after reading = %+v", books)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"<p>一<br/>二</p>", "<p>一<br>二</p>"},
+		{`<p class="x"><strong>粗</strong><a href="javascript:x()">链接</a></p>`, "<p><strong>粗</strong>链接</p>"},
+		{"<p>a<style>p{}</style><iframe src=x></iframe></p><!-- c -->", "<p>a</p>"},
+		{`<p><img src=x onerror=alert(1)>1 &lt; 2</p>`, "<p>1 &lt; 2</p>"},
+		{"<div><p>段</p></div>", "<p>段</p>"},
+	}
+	for _, c := range cases {
+		if got := Sanitize(c.in); got != c.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package reader
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 保留的元素, 属性一律去掉
+var allowedElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true,
+	atom.B: true, atom.Strong: true, atom.Em: true, atom.I: true,
+}
+
+// 连同内容一起去掉的元素
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Object: true, atom.Embed: true, atom.Template: true, atom.Svg: true, atom.Math: true,
+}
+
+// Sanitize 只保留段落、换行与粗体斜体, 其余元素只保留文字, 可直接插入页面
+func Sanitize(content string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return html.EscapeString(content)
+	}
+	var sb strings.Builder
+	for _, n := range nodes {
+		sanitize(&sb, n)
+	}
+	return sb.String()
+}
+
+func sanitize(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+		if droppedElements[n.DataAtom] {
+			return
+		}
+	default:
+		// 注释等
+		return
+	}
+	allowed := allowedElements[n.DataAtom]
+	if allowed {
+		sb.WriteString("<" + n.DataAtom.String() + ">")
+		if n.DataAtom == atom.Br {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sanitize(sb, c)
+	}
+	if allowed {
+		sb.WriteString("</" + n.DataAtom.String() + ">")
+	}
+}
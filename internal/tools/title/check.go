@@ -0,0 +1,66 @@
+package title
+
+import (
+	"slices"
+
+	"fy-novel/internal/model"
+)
+
+// Check 检查目录中缺失或重复的章节号.
+// 卷名变化且编号回落时视为该卷重新编号, 各段分别检查.
+func Check(titles []string) model.CatalogReport {
+	report := model.CatalogReport{Total: len(titles)}
+
+	type segment struct {
+		volume string
+		// 章节号 -> 标题
+		numbers map[int][]string
+	}
+	var segments []*segment
+	var cur *segment
+	prevVolume, prevNumber := "", 0
+	for _, raw := range titles {
+		t := Parse(raw)
+		if t.Number == 0 {
+			continue
+		}
+		report.Numbered++
+		if t.Volume != "" {
+			if cur == nil || (t.Volume != prevVolume && t.Number <= prevNumber) {
+				cur = nil
+			}
+			prevVolume = t.Volume
+		}
+		if cur == nil {
+			cur = &segment{volume: t.Volume, numbers: make(map[int][]string)}
+			segments = append(segments, cur)
+		}
+		cur.numbers[t.Number] = append(cur.numbers[t.Number], raw)
+		prevNumber = t.Number
+	}
+
+	for _, seg := range segments {
+		numbers := make([]int, 0, len(seg.numbers))
+		for n := range seg.numbers {
+			numbers = append(numbers, n)
+		}
+		slices.Sort(numbers)
+		for i, n := range numbers {
+			if ts := seg.numbers[n]; len(ts) > 1 {
+				report.Duplicates = append(report.Duplicates, model.CatalogDuplicate{
+					Volume: seg.volume,
+					Number: n,
+					Titles: ts,
+				})
+			}
+			if i > 0 && n > numbers[i-1]+1 {
+				report.Gaps = append(report.Gaps, model.CatalogGap{
+					Volume: seg.volume,
+					From:   numbers[i-1] + 1,
+					To:     n - 1,
+				})
+			}
+		}
+	}
+	return report
+}
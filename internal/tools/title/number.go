@@ -0,0 +1,91 @@
+package title
+
+import "strings"
+
+var chineseDigits = map[rune]int{
+	'零': 0, '〇': 0, '○': 0,
+	'一': 1, '壹': 1,
+	'二': 2, '两': 2, '贰': 2,
+	'三': 3, '叁': 3,
+	'四': 4, '肆': 4,
+	'五': 5, '伍': 5,
+	'六': 6, '陆': 6,
+	'七': 7, '柒': 7,
+	'八': 8, '捌': 8,
+	'九': 9, '玖': 9,
+}
+
+var chineseUnits = map[rune]int{
+	'十': 10, '拾': 10,
+	'百': 100, '佰': 100,
+	'千': 1000, '仟': 1000,
+}
+
+// numeralChars 正则中使用的数字字符
+const numeralChars = `0-9０-９零〇○一壹二两贰三叁四肆五伍六陆七柒八捌九玖十拾百佰千仟万`
+
+// ParseNumber 解析阿拉伯数字 (含全角) 或中文数字, 例如 "120" "一百二十" "一二零" "两千零五"
+func ParseNumber(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if n, ok := parseArabic(s); ok {
+		return n, true
+	}
+	return parseChinese(s)
+}
+
+func parseArabic(s string) (int, bool) {
+	n := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			n = n*10 + int(r-'0')
+		case r >= '０' && r <= '９':
+			n = n*10 + int(r-'０')
+		default:
+			return 0, false
+		}
+	}
+	return n, true
+}
+
+func parseChinese(s string) (int, bool) {
+	hasUnit := strings.ContainsAny(s, "十拾百佰千仟万")
+	if !hasUnit {
+		// 逐位书写: 一二零
+		n := 0
+		for _, r := range s {
+			d, ok := chineseDigits[r]
+			if !ok {
+				return 0, false
+			}
+			n = n*10 + d
+		}
+		return n, true
+	}
+	total, section, digit := 0, 0, 0
+	for _, r := range s {
+		if d, ok := chineseDigits[r]; ok {
+			digit = d
+			continue
+		}
+		if r == '万' {
+			total += (section + digit) * 10000
+			section, digit = 0, 0
+			continue
+		}
+		unit, ok := chineseUnits[r]
+		if !ok {
+			return 0, false
+		}
+		// "十二" 省略了开头的 "一"
+		if digit == 0 && unit == 10 {
+			digit = 1
+		}
+		section += digit * unit
+		digit = 0
+	}
+	return total + section + digit, true
+}
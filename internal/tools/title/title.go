@@ -0,0 +1,151 @@
+package title
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fy-novel/internal/config"
+)
+
+var (
+	// 卷名前缀, 与导出时识别卷的规则一致: "第一卷 风起 第一章 开始"
+	volumePattern = regexp.MustCompile(
+		`^(第[` + numeralChars + `]+[卷部](?:[ \t　]+[^第\s　]\S*)?)[ \t　]+(\S.*)$`,
+	)
+	// 第一百二十章 标题 / 第120回：标题
+	chapterPattern = regexp.MustCompile(
+		`^第[ \t　]*([` + numeralChars + `]+)[ \t　]*([章节回话集幕])[ \t　:：、.．\-—]*(.*)$`,
+	)
+	// 120. 标题 / 120、标题 / 一百二十、标题 / 120 标题
+	numberPattern = regexp.MustCompile(
+		`^([` + numeralChars + `]+)(?:[ \t　]*[.．、:：\-—][ \t　]*|[ \t　]+|$)(.*)$`,
+	)
+)
+
+// Title 解析后的章节标题
+type Title struct {
+	// 卷名前缀, 没有时为空
+	Volume string
+	// 章节号, 没有时为 0
+	Number int
+	// 章、回、节等, 没有时为空
+	Unit string
+	Name string
+}
+
+// Parse 拆分章节标题, 无法识别章节号时整个标题作为 Name
+func Parse(raw string) Title {
+	s := strings.TrimSpace(raw)
+	if m := volumePattern.FindStringSubmatch(s); m != nil {
+		// 卷名后需要紧跟章节号, 否则 "第一卷 风起云涌" 整体就是标题
+		if rest := parseChapter(m[2]); rest.Number > 0 {
+			rest.Volume = m[1]
+			return rest
+		}
+	}
+	return parseChapter(s)
+}
+
+func parseChapter(s string) Title {
+	if m := chapterPattern.FindStringSubmatch(s); m != nil {
+		if n, ok := ParseNumber(m[1]); ok && n > 0 {
+			return Title{Number: n, Unit: m[2], Name: strings.TrimSpace(m[3])}
+		}
+	}
+	if m := numberPattern.FindStringSubmatch(s); m != nil {
+		// 纯中文数字开头的标题 (如 "一往无前") 必须有分隔符
+		if n, ok := ParseNumber(m[1]); ok && n > 0 && (isArabic(m[1]) || len(m[2]) < len(s)-len(m[1])) {
+			return Title{Number: n, Name: strings.TrimSpace(m[2])}
+		}
+	}
+	return Title{Name: s}
+}
+
+func isArabic(s string) bool {
+	_, ok := parseArabic(s)
+	return ok
+}
+
+// String 统一格式: "第120章 标题", 保留卷名前缀
+func (t Title) String() string {
+	if t.Number == 0 {
+		return strings.TrimSpace(t.Volume + " " + t.Name)
+	}
+	unit := t.Unit
+	if unit == "" {
+		unit = "章"
+	}
+	s := fmt.Sprintf("第%d%s", t.Number, unit)
+	if t.Name != "" {
+		s += " " + t.Name
+	}
+	if t.Volume != "" {
+		s = t.Volume + " " + s
+	}
+	return s
+}
+
+// Normalizer 按配置整理目录中的章节标题
+type Normalizer struct {
+	strip     []*regexp.Regexp
+	normalize bool
+	renumber  bool
+}
+
+func NewNormalizer(conf config.Info) (*Normalizer, error) {
+	n := &Normalizer{normalize: conf.Title.Normalize, renumber: conf.Title.Renumber}
+	for _, p := range conf.Title.StripPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title strip pattern %q: %v", p, err)
+		}
+		n.strip = append(n.strip, re)
+	}
+	return n, nil
+}
+
+// Active 是否会修改标题
+func (n *Normalizer) Active() bool {
+	return n.normalize || n.renumber || len(n.strip) > 0
+}
+
+// Strip 去除推广后缀等配置的内容
+func (n *Normalizer) Strip(raw string) string {
+	s := raw
+	for _, re := range n.strip {
+		s = re.ReplaceAllString(s, "")
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		// 整个标题都被去掉时保留原标题
+		return strings.TrimSpace(raw)
+	}
+	return s
+}
+
+// Catalog 整理整本书的标题. 重新编号时按目录顺序为带章节号的标题从 1 开始连续编号,
+// 序章、番外等没有章节号的标题不参与编号.
+func (n *Normalizer) Catalog(raw []string) []string {
+	res := make([]string, len(raw))
+	next := 1
+	for i, r := range raw {
+		s := n.Strip(r)
+		if !n.normalize && !n.renumber {
+			res[i] = s
+			continue
+		}
+		t := Parse(s)
+		if t.Number == 0 {
+			res[i] = s
+			continue
+		}
+		// 重新编号时也使用统一格式, 否则无法替换原有的编号
+		if n.renumber {
+			t.Number = next
+			next++
+		}
+		res[i] = t.String()
+	}
+	return res
+}
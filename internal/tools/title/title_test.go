@@ -0,0 +1,116 @@
+package title
+
+import (
+	"reflect"
+	"testing"
+
+	"fy-novel/internal/config"
+	"fy-novel/internal/model"
+)
+
+func TestParseNumber(t *testing.T) {
+	cases := map[string]int{
+		"120":   120,
+		"１２０":   120,
+		"十":     10,
+		"十二":    12,
+		"一百二十":  120,
+		"一百零五":  105,
+		"两千零五":  2005,
+		"一二零":   120,
+		"壹佰贰拾":  120,
+		"一万零一百": 10100,
+	}
+	for s, want := range cases {
+		if got, ok := ParseNumber(s); !ok || got != want {
+			t.Errorf("ParseNumber(%q) = %d, %v, want %d", s, got, ok, want)
+		}
+	}
+	if _, ok := ParseNumber("十年"); ok {
+		t.Error("expected failure for non numeral")
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want Title
+	}{
+		{"第一百二十章", Title{Number: 120, Unit: "章"}},
+		{"第120章 标题（求月票）", Title{Number: 120, Unit: "章", Name: "标题（求月票）"}},
+		{"120.", Title{Number: 120}},
+		{"120、标题", Title{Number: 120, Name: "标题"}},
+		{"一百二十、标题", Title{Number: 120, Name: "标题"}},
+		{"第五回：标题", Title{Number: 5, Unit: "回", Name: "标题"}},
+		{"第一卷 风起 第三章 开始", Title{Volume: "第一卷 风起", Number: 3, Unit: "章", Name: "开始"}},
+		{"第一卷 风起云涌", Title{Name: "第一卷 风起云涌"}},
+		{"一往无前", Title{Name: "一往无前"}},
+		{"序章 开端", Title{Name: "序章 开端"}},
+	}
+	for _, c := range cases {
+		if got := Parse(c.raw); got != c.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestNormalizerCatalog(t *testing.T) {
+	conf := config.Info{}
+	conf.Title.StripPatterns = []string{`[（(][^）)]*求[^）)]*[）)]`}
+	raw := []string{"序章", "第一章 开始（求月票）", "2.继续", "第五章 跳号", "番外"}
+
+	n, err := NewNormalizer(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"序章", "第一章 开始", "2.继续", "第五章 跳号", "番外"}
+	if got := n.Catalog(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("strip only: got %q", got)
+	}
+
+	conf.Title.Normalize = true
+	n, _ = NewNormalizer(conf)
+	want = []string{"序章", "第1章 开始", "第2章 继续", "第5章 跳号", "番外"}
+	if got := n.Catalog(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("normalize: got %q", got)
+	}
+
+	conf.Title.Renumber = true
+	n, _ = NewNormalizer(conf)
+	want = []string{"序章", "第1章 开始", "第2章 继续", "第3章 跳号", "番外"}
+	if got := n.Catalog(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("renumber: got %q", got)
+	}
+
+	conf.Title.StripPatterns = []string{"("}
+	if _, err := NewNormalizer(conf); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	titles := []string{
+		"序章",
+		"第一卷 风起 第一章 甲",
+		"第一卷 风起 第二章 乙",
+		"第一卷 风起 第二章 乙（重复）",
+		"第一卷 风起 第五章 丙",
+		// 第二卷重新编号
+		"第二卷 云涌 第一章 丁",
+		"第二卷 云涌 第二章 戊",
+	}
+	report := Check(titles)
+	want := model.CatalogReport{
+		Total:    7,
+		Numbered: 6,
+		Gaps:     []model.CatalogGap{{Volume: "第一卷 风起", From: 3, To: 4}},
+		Duplicates: []model.CatalogDuplicate{{
+			Volume: "第一卷 风起",
+			Number: 2,
+			Titles: []string{"第一卷 风起 第二章 乙", "第一卷 风起 第二章 乙（重复）"},
+		}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// OpenPath 使用系统默认程序打开文件或目录
+func OpenPath(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", path)
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	// 不等待文件管理器退出
+	return cmd.Start()
+}